@@ -4,6 +4,7 @@ import (
 	"os"
 	"os/signal"
 	"syscall"
+	"time"
 
 	"github.com/Shopify/sarama"
 	"github.com/crxfoz/teaserad/adclick/internal/delivery/http"
@@ -11,6 +12,7 @@ import (
 	kafrepo "github.com/crxfoz/teaserad/adclick/internal/repo/kafka"
 	redisRepo "github.com/crxfoz/teaserad/adclick/internal/repo/redis"
 	"github.com/crxfoz/teaserad/adclick/internal/services/click"
+	"github.com/crxfoz/teaserad/adclick/pkg/clicksign"
 	"github.com/crxfoz/teaserad/adclick/pkg/httpserver"
 	"github.com/crxfoz/teaserad/adeliver/pkg/kafka"
 	redisCluster "github.com/crxfoz/teaserad/adeliver/pkg/redis"
@@ -19,6 +21,9 @@ import (
 	"go.uber.org/zap"
 )
 
+// clickTTL is how long click URLs of shown banners are valid, it's the same as adshow signs them with
+const clickTTL = 24 * time.Hour
+
 func main() {
 	z, err := zap.NewDevelopment()
 	if err != nil {
@@ -64,13 +69,25 @@ func main() {
 		return
 	}
 
-	kafkaRepo := kafrepo.New(kafkaProducerRepo)
+	signKeys, err := clicksign.ParseKeys(os.Getenv("CLICK_SIGN_KEYS"))
+	if err != nil {
+		cmdLogger.Fatalw("could not parse click sign keys", "err", err)
+		return
+	}
+
+	// clicks are switched to protobuf by KAFKA_CONTENT_TYPE=application/x-protobuf once consumers are ready
+	kafkaRepo := kafrepo.New(kafkaProducerRepo, os.Getenv("KAFKA_CONTENT_TYPE"))
 	rCluster := redisCluster.New(redisConns)
 	rRepo := redisRepo.New(rCluster)
-	clickService := click.New(rRepo, kafkaRepo, logger.Named("service-click"))
-	kafBuilder := kafka.New(logger)
+	clickService := click.New(rRepo, kafkaRepo, logger.Named("service-click"),
+		click.NewBotRule(),
+		click.NewTooFastRule(time.Second),
+		click.NewDedupeRule(rRepo, clickTTL),
+		click.NewIPRateRule(rRepo, 20, time.Minute),
+		click.NewVisitorRateRule(rRepo, 10, time.Minute))
+	kafBuilder := kafka.New(logger, kafkaProducerRepo)
 	kafkaHandler := kafkadel.New(clickService)
-	httpHandler := http.New(clickService, logger.Named("delivery-http"))
+	httpHandler := http.New(clickService, clicksign.New(signKeys, clickTTL), logger.Named("delivery-http"))
 	httpSrv := httpserver.New(httpHandler)
 
 	kafSessBanners, err := kafBuilder.NewConsumer("adclick-consumer-banners", kafConsumerNewBanners, func(sess *kafka.Session) error {
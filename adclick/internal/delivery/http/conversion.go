@@ -0,0 +1,85 @@
+package http
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/crxfoz/teaserad/adclick/internal/domain/entity"
+	"github.com/labstack/echo/v4"
+	"go.opentelemetry.io/otel"
+)
+
+// pixel is a transparent 1x1 GIF
+var pixel = []byte{
+	0x47, 0x49, 0x46, 0x38, 0x39, 0x61, 0x01, 0x00, 0x01, 0x00, 0x80, 0x00, 0x00, 0x00, 0x00, 0x00,
+	0xff, 0xff, 0xff, 0x21, 0xf9, 0x04, 0x01, 0x00, 0x00, 0x00, 0x00, 0x2c, 0x00, 0x00, 0x00, 0x00,
+	0x01, 0x00, 0x01, 0x00, 0x00, 0x02, 0x02, 0x44, 0x01, 0x00, 0x3b,
+}
+
+// Conversion is a server-to-server postback of the advertiser
+func (r *Router) Conversion(c echo.Context) error {
+	spanCtx, span := otel.Tracer(tracerName).Start(c.Request().Context(), "Conversion")
+	defer span.End()
+
+	clickID := c.QueryParam(entity.ClickParam)
+	if clickID == "" {
+		return c.JSON(http.StatusUnprocessableEntity, HTTPError{"invalide click id"})
+	}
+
+	value, err := r.conversionValue(c)
+	if err != nil {
+		return c.JSON(http.StatusUnprocessableEntity, HTTPError{"invalide value"})
+	}
+
+	if err := r.clickSvc.NewConversion(spanCtx, clickID, value); err != nil {
+		if errors.Is(err, entity.ErrUnknownClick) {
+			return c.JSON(http.StatusNotFound, HTTPError{"unknown click"})
+		}
+
+		if errors.Is(err, entity.ErrDuplicateConversion) {
+			return c.JSON(http.StatusConflict, HTTPError{"conversion already registered"})
+		}
+
+		r.logger.Errorw("could not register conversion", "err", err, "endpoint", "Conversion")
+		return c.JSON(http.StatusInternalServerError, HTTPError{"could not register conversion"})
+	}
+
+	return c.JSON(http.StatusOK, map[string]string{
+		"status": "ok",
+	})
+}
+
+// ConversionPixel is loaded by the landing page of the advertiser, it answers with the pixel whatever happens
+func (r *Router) ConversionPixel(c echo.Context) error {
+	spanCtx, span := otel.Tracer(tracerName).Start(c.Request().Context(), "ConversionPixel")
+	defer span.End()
+
+	c.Response().Header().Set("Cache-Control", "no-store")
+
+	clickID := c.QueryParam(entity.ClickParam)
+	if clickID == "" {
+		return c.Blob(http.StatusOK, "image/gif", pixel)
+	}
+
+	value, err := r.conversionValue(c)
+	if err != nil {
+		return c.Blob(http.StatusOK, "image/gif", pixel)
+	}
+
+	if err := r.clickSvc.NewConversion(spanCtx, clickID, value); err != nil {
+		r.logger.Warnw("could not register conversion", "err", err, "endpoint", "ConversionPixel")
+	}
+
+	return c.Blob(http.StatusOK, "image/gif", pixel)
+}
+
+// conversionValue is optional revenue of the conversion reported by the advertiser
+func (r *Router) conversionValue(c echo.Context) (float64, error) {
+	v := c.QueryParam("value")
+	if v == "" {
+		return 0, nil
+	}
+
+	return strconv.ParseFloat(v, 64)
+}
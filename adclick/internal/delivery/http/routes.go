@@ -3,31 +3,70 @@ package http
 import (
 	"context"
 	"net/http"
+	"net/url"
 	"strconv"
+	"time"
 
 	"github.com/crxfoz/teaserad/adclick/internal/domain"
 	"github.com/crxfoz/teaserad/adclick/internal/domain/entity"
+	"github.com/crxfoz/teaserad/adclick/pkg/clicksign"
 	"github.com/labstack/echo/v4"
 	"go.opentelemetry.io/otel"
 )
 
 type ClickService interface {
-	NewClick(ctx context.Context, bannerID int, platformID int, viewID string) (*entity.BannerURL, error)
+	NewClick(ctx context.Context, click *entity.Click) (*entity.BannerURL, error)
+	InvalidClick(ctx context.Context, click *entity.Click, reason string) (*entity.BannerURL, error)
+	NewConversion(ctx context.Context, clickID string, value float64) error
+}
+
+// ClickVerifier checks click URLs were signed by adshow and haven't expired
+type ClickVerifier interface {
+	Verify(query url.Values, now time.Time) (clicksign.Click, error)
 }
 
 type Router struct {
 	clickSvc ClickService
+	verifier ClickVerifier
 	logger   domain.Logger
 }
 
-func New(clickSvc ClickService, logger domain.Logger) *Router {
-	return &Router{clickSvc: clickSvc, logger: logger}
+func New(clickSvc ClickService, verifier ClickVerifier, logger domain.Logger) *Router {
+	return &Router{clickSvc: clickSvc, verifier: verifier, logger: logger}
 }
 
 const (
 	tracerName = "http-delivery"
+
+	// visitorCookie is issued by adshow, both are served from the same host
+	visitorCookie = "vid"
 )
 
+// click collects the click from the request, signed fields are taken from the verified URL if it's valid
+func (r *Router) click(c echo.Context, bannerID int, platformID int, signed *clicksign.Click) *entity.Click {
+	click := &entity.Click{
+		BannerID:   bannerID,
+		PlatformID: platformID,
+		ViewID:     c.QueryParam("view"),
+		IP:         c.RealIP(),
+		UserAgent:  c.Request().UserAgent(),
+	}
+
+	if signed != nil {
+		click.BannerID = signed.BannerID
+		click.PlatformID = signed.PlatformID
+		click.ViewID = signed.ViewID
+		click.Price = signed.Price
+		click.ShownAt = signed.Timestamp
+	}
+
+	if cookie, err := c.Cookie(visitorCookie); err == nil {
+		click.VisitorID = cookie.Value
+	}
+
+	return click
+}
+
 func (r *Router) RegisterClick(c echo.Context) error {
 	spanCtx, span := otel.Tracer(tracerName).Start(c.Request().Context(), "RegisterClick")
 	defer span.End()
@@ -44,8 +83,18 @@ func (r *Router) RegisterClick(c echo.Context) error {
 		return c.JSON(http.StatusUnprocessableEntity, HTTPError{"invalide banner id"})
 	}
 
-	// TODO: add viewID
-	info, err := r.clickSvc.NewClick(spanCtx, bannerID, platformID, "")
+	signed, err := r.verifier.Verify(c.QueryParams(), time.Now())
+	if err != nil {
+		r.logger.Warnw("click rejected", "err", err, "endpoint", "RegisterClick")
+
+		if _, err := r.clickSvc.InvalidClick(spanCtx, r.click(c, bannerID, platformID, nil), entity.ReasonSignature); err != nil {
+			r.logger.Errorw("could not register invalid click", "err", err, "endpoint", "RegisterClick")
+		}
+
+		return c.JSON(http.StatusForbidden, HTTPError{"invalide click"})
+	}
+
+	info, err := r.clickSvc.NewClick(spanCtx, r.click(c, bannerID, platformID, &signed))
 	if err != nil {
 		r.logger.Errorw("could not register click", "err", err, "endpoint", "RegisterClick")
 		return c.JSON(http.StatusInternalServerError, HTTPError{"could not register new click"})
@@ -70,8 +119,21 @@ func (r *Router) HTMLClick(c echo.Context) error {
 		return c.NoContent(http.StatusNotFound)
 	}
 
-	// TODO: add viewID
-	info, err := r.clickSvc.NewClick(spanCtx, bannerID, platformID, "")
+	// user is redirected anyway, forged or expired clicks are just not charged
+	signed, err := r.verifier.Verify(c.QueryParams(), time.Now())
+	if err != nil {
+		r.logger.Warnw("click rejected", "err", err, "endpoint", "HTMLClick")
+
+		info, err := r.clickSvc.InvalidClick(spanCtx, r.click(c, bannerID, platformID, nil), entity.ReasonSignature)
+		if err != nil {
+			r.logger.Errorw("could not register invalid click", "err", err, "endpoint", "HTMLClick")
+			return c.NoContent(http.StatusNotFound)
+		}
+
+		return c.Redirect(http.StatusMovedPermanently, info.BannerURL)
+	}
+
+	info, err := r.clickSvc.NewClick(spanCtx, r.click(c, bannerID, platformID, &signed))
 	if err != nil {
 		r.logger.Errorw("could not register click", "err", err, "endpoint", "HTMLClick")
 		return c.NoContent(http.StatusNotFound)
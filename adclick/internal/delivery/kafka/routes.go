@@ -2,12 +2,12 @@ package kafka
 
 import (
 	"context"
-	"encoding/json"
 	"fmt"
 
 	"github.com/Shopify/sarama"
 	"github.com/crxfoz/teaserad/adclick/internal/domain/entity"
 	"github.com/crxfoz/teaserad/adclick/internal/domain/events"
+	kafkapkg "github.com/crxfoz/teaserad/adeliver/pkg/kafka"
 	"go.opentelemetry.io/otel"
 )
 
@@ -32,13 +32,18 @@ func (h *Handler) OnNewBanner(ctx context.Context, msg *sarama.ConsumerMessage)
 	defer span.End()
 
 	var newBanner events.NewBanner
-	if err := json.Unmarshal(msg.Value, &newBanner); err != nil {
+	if err := kafkapkg.Decode(msg, &newBanner); err != nil {
 		return fmt.Errorf("could not parse message: %w", err)
 	}
 
 	if err := h.clickSvc.AddBanner(spanCtx, &entity.BannerURL{
 		BannerID:  newBanner.BannerID,
 		BannerURL: newBanner.BannerURL,
+		BidType:   newBanner.BidType,
+		Bid:       newBanner.Bid,
+
+		CategoryID: newBanner.CategoryID,
+		UTM:        newBanner.UTM,
 	}); err != nil {
 		return fmt.Errorf("could not add banner: %w", err)
 	}
@@ -1,6 +1,73 @@
 package entity
 
+import (
+	"strconv"
+
+	"github.com/crxfoz/teaserad/adclick/pkg/landing"
+	"github.com/crxfoz/teaserad/adshow/pkg/useragent"
+)
+
+const (
+	BidCPC = "cpc"
+	BidCPM = "cpm"
+
+	// DefaultBid is a price of a click banners had before bids were introduced
+	DefaultBid = 1
+
+	// minCTR is the lowest CTR adshow predicts, so a click of CPM banner costs at most its bid
+	minCTR = 0.001
+)
+
 type BannerURL struct {
-	BannerID  int    `json:"banner_id"`
-	BannerURL string `json:"banner_url"`
+	BannerID  int     `json:"banner_id"`
+	BannerURL string  `json:"banner_url"`
+	BidType   string  `json:"bid_type"`
+	Bid       float64 `json:"bid"`
+	// BannerURL is a template with macros, CategoryID and UTM are used to expand it
+	CategoryID int               `json:"category_id"`
+	UTM        map[string]string `json:"utm"`
+}
+
+// MaxClickPrice is the most the click on the banner can be charged with
+func (b *BannerURL) MaxClickPrice() float64 {
+	if b.Bid == 0 {
+		return DefaultBid
+	}
+
+	if b.BidType == BidCPM {
+		return b.Bid / 1000 / minCTR
+	}
+
+	return b.Bid
+}
+
+// ClickPrice returns price chosen by adshow auction, it never exceeds the bid even if adshow
+// ranked the banner with outdated one
+func (b *BannerURL) ClickPrice(offered float64) float64 {
+	maxPrice := b.MaxClickPrice()
+	if offered <= 0 || offered > maxPrice {
+		return maxPrice
+	}
+
+	return offered
+}
+
+// LandingURL expands the template of the banner, click ID is appended unless the template places it on its own
+func (b *BannerURL) LandingURL(click *Click, clickID string) string {
+	params := make(map[string]string, len(b.UTM)+1)
+	for name, value := range b.UTM {
+		params[name] = value
+	}
+
+	if clickID != "" && !landing.HasMacro(b.BannerURL, landing.MacroClickID) {
+		params[ClickParam] = clickID
+	}
+
+	return landing.Build(b.BannerURL, landing.Values{
+		landing.MacroBannerID:   strconv.Itoa(b.BannerID),
+		landing.MacroPlatformID: strconv.Itoa(click.PlatformID),
+		landing.MacroClickID:    clickID,
+		landing.MacroDevice:     useragent.Parse(click.UserAgent).Device,
+		landing.MacroCategory:   strconv.Itoa(b.CategoryID),
+	}, params)
 }
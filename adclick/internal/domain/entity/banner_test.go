@@ -0,0 +1,26 @@
+package entity
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBannerURL_LandingURL(t *testing.T) {
+	click := &Click{PlatformID: 20, UserAgent: "Mozilla/5.0 (iPhone; CPU iPhone OS 16_0 like Mac OS X) Mobile/15E148"}
+
+	banner := &BannerURL{BannerID: 10, BannerURL: "https://shop.example/item?id=10#top"}
+	assert.Equal(t, "https://shop.example/item?id=10&click_id=abc#top", banner.LandingURL(click, "abc"))
+
+	banner = &BannerURL{BannerID: 10, BannerURL: "https://shop.example"}
+	assert.Equal(t, "https://shop.example?click_id=abc", banner.LandingURL(click, "abc"))
+	assert.Equal(t, "https://shop.example", banner.LandingURL(click, ""))
+
+	banner = &BannerURL{
+		BannerID:   10,
+		CategoryID: 3,
+		BannerURL:  "https://shop.example/{device}?cid={click_id}&p={platform_id}&c={category}",
+		UTM:        map[string]string{"utm_source": "teaserad", "utm_content": "{banner_id}"},
+	}
+	assert.Equal(t, "https://shop.example/mobile?cid=abc&p=20&c=3&utm_content=10&utm_source=teaserad", banner.LandingURL(click, "abc"))
+}
@@ -0,0 +1,24 @@
+package entity
+
+// reasons clicks are invalid for
+const (
+	ReasonSignature = "signature"
+	ReasonDuplicate = "duplicate"
+	ReasonIPRate    = "ip_rate"
+	ReasonVisitor   = "visitor_rate"
+	ReasonBot       = "bot"
+	ReasonTooFast   = "too_fast"
+)
+
+// Click holds everything known about the click to tell whether it's a valid one
+type Click struct {
+	BannerID   int
+	PlatformID int
+	ViewID     string
+	Price      float64
+	// ShownAt is when the banner was shown, it's taken from the signed click URL
+	ShownAt   int64
+	IP        string
+	UserAgent string
+	VisitorID string
+}
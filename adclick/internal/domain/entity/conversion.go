@@ -0,0 +1,21 @@
+package entity
+
+import "errors"
+
+var (
+	ErrUnknownClick        = errors.New("unknown click")
+	ErrDuplicateConversion = errors.New("conversion already registered")
+)
+
+// ClickParam is a query parameter of the landing URL the click ID is passed to the advertiser with
+const ClickParam = "click_id"
+
+// ClickRecord is kept by click ID so conversions reported by the advertiser can be attributed to the click
+type ClickRecord struct {
+	ClickID    string  `json:"click_id"`
+	BannerID   int     `json:"banner_id"`
+	PlatformID int     `json:"platform_id"`
+	ViewID     string  `json:"view_id"`
+	Price      float64 `json:"price"`
+	CreatedAt  int64   `json:"created_at"`
+}
@@ -1,6 +1,6 @@
 package events
 
-type NewBanner struct {
-	BannerID  int    `json:"banner_id"`
-	BannerURL string `json:"banner_url"`
-}
+import contracts "github.com/crxfoz/teaserad/contracts/events"
+
+// events are declared by the shared contracts, so every service agrees on their schemas
+type NewBanner = contracts.ServingStart
@@ -1,9 +1,11 @@
 package events
 
-type Click struct {
-	BannerID   int     `json:"banner_id"`
-	PlatformID int     `json:"platform_id"`
-	ViewID     string  `json:"view_id"`
-	Price      float64 `json:"price"`
-	CreatedAt  int64   `json:"created_at"`
-}
+import contracts "github.com/crxfoz/teaserad/contracts/events"
+
+type (
+	Click = contracts.Click
+	// InvalidClick is a click filtered out as forged or fraudulent, it's not charged
+	InvalidClick = contracts.InvalidClick
+	// Conversion is reported by the advertiser for the click which led to it
+	Conversion = contracts.Conversion
+)
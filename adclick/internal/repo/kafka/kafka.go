@@ -2,48 +2,58 @@ package kafka
 
 import (
 	"context"
-	"encoding/json"
 	"fmt"
 
 	"github.com/Shopify/sarama"
 	"github.com/crxfoz/teaserad/adclick/internal/domain/events"
-	"go.opentelemetry.io/contrib/instrumentation/github.com/Shopify/sarama/otelsarama"
+	kafkapkg "github.com/crxfoz/teaserad/adeliver/pkg/kafka"
 	"go.opentelemetry.io/otel"
 )
 
 const (
-	topicNewClick = "adclick.action.click"
-	tracerName    = "kafka-producer"
+	topicNewClick     = "adclick.action.click"
+	topicInvalidClick = "adclick.action.click.invalid"
+	topicConversion   = "adclick.action.conversion"
+	tracerName        = "kafka-producer"
 )
 
 type Kafka struct {
-	conn sarama.SyncProducer
+	producer *kafkapkg.Producer
 }
 
-func New(conn sarama.SyncProducer) *Kafka {
-	return &Kafka{conn: conn}
+// New sends clicks encoded with the content type, empty one means JSON
+func New(conn sarama.SyncProducer, contentType string) *Kafka {
+	return &Kafka{producer: kafkapkg.NewProducer(conn, "adclick", kafkapkg.WithContentType(contentType))}
 }
 
 func (k *Kafka) SendClick(ctx context.Context, event *events.Click) error {
 	spanCtx, span := otel.Tracer(tracerName).Start(ctx, "SendClick")
 	defer span.End()
 
-	out, err := json.Marshal(event)
-	if err != nil {
-		return fmt.Errorf("could not marshal msg: %w", err)
+	if err := k.producer.Publish(spanCtx, topicNewClick, event); err != nil {
+		return fmt.Errorf("could not publish event: %w", err)
 	}
 
-	pitem := &sarama.ProducerMessage{
-		Topic: topicNewClick,
-		Key:   sarama.StringEncoder("1"), // TODO: use different keys
-		Value: sarama.ByteEncoder(out),
+	return nil
+}
+
+func (k *Kafka) SendInvalidClick(ctx context.Context, event *events.InvalidClick) error {
+	spanCtx, span := otel.Tracer(tracerName).Start(ctx, "SendInvalidClick")
+	defer span.End()
+
+	if err := k.producer.Publish(spanCtx, topicInvalidClick, event); err != nil {
+		return fmt.Errorf("could not publish event: %w", err)
 	}
 
-	otel.GetTextMapPropagator().Inject(spanCtx, otelsarama.NewProducerMessageCarrier(pitem))
+	return nil
+}
+
+func (k *Kafka) SendConversion(ctx context.Context, event *events.Conversion) error {
+	spanCtx, span := otel.Tracer(tracerName).Start(ctx, "SendConversion")
+	defer span.End()
 
-	_, _, err = k.conn.SendMessage(pitem)
-	if err != nil {
-		return fmt.Errorf("could not send message: %w", err)
+	if err := k.producer.Publish(spanCtx, topicConversion, event); err != nil {
+		return fmt.Errorf("could not publish event: %w", err)
 	}
 
 	return nil
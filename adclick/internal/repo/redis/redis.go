@@ -4,6 +4,8 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"hash/fnv"
+	"time"
 
 	"github.com/crxfoz/teaserad/adclick/internal/domain/entity"
 	"github.com/go-redis/redis/v8"
@@ -72,3 +74,120 @@ func (r *Redis) GetBanner(ctx context.Context, bannerID int) (*entity.BannerURL,
 
 	return &banner, nil
 }
+
+// node keeps keys which aren't bound to a banner on the same node
+func (r *Redis) node(key string) *redis.Client {
+	h := fnv.New32a()
+	_, _ = h.Write([]byte(key))
+
+	return r.cluster.Node(int(h.Sum32()))
+}
+
+func (r *Redis) MarkView(ctx context.Context, viewID string, ttl time.Duration) (bool, error) {
+	spanCtx, span := otel.Tracer(tracerName).Start(ctx, "MarkView")
+	defer span.End()
+
+	key := fmt.Sprintf("click.view.%s", viewID)
+
+	marked, err := r.node(key).SetNX(spanCtx, key, 1, ttl).Result()
+	if err != nil {
+		return false, fmt.Errorf("could not mark view: %w", err)
+	}
+
+	return !marked, nil
+}
+
+func (r *Redis) UnmarkConversion(ctx context.Context, clickID string) error {
+	spanCtx, span := otel.Tracer(tracerName).Start(ctx, "UnmarkConversion")
+	defer span.End()
+
+	key := r.conversionKey(clickID)
+
+	if err := r.node(key).Del(spanCtx, key).Err(); err != nil {
+		return fmt.Errorf("could not unmark conversion: %w", err)
+	}
+
+	return nil
+}
+
+func (r *Redis) CountClick(ctx context.Context, key string, window time.Duration) (int64, error) {
+	spanCtx, span := otel.Tracer(tracerName).Start(ctx, "CountClick")
+	defer span.End()
+
+	// fixed windows are good enough to catch click floods
+	bucket := time.Now().UnixNano() / int64(window)
+	key = fmt.Sprintf("click.rate.%s.%d", key, bucket)
+	conn := r.node(key)
+
+	pipe := conn.TxPipeline()
+	incr := pipe.Incr(spanCtx, key)
+	pipe.Expire(spanCtx, key, window)
+
+	if _, err := pipe.Exec(spanCtx); err != nil {
+		return 0, fmt.Errorf("could not count click: %w", err)
+	}
+
+	return incr.Val(), nil
+}
+
+func (r *Redis) clickKey(clickID string) string {
+	return fmt.Sprintf("click.id.%s", clickID)
+}
+
+func (r *Redis) SaveClick(ctx context.Context, record *entity.ClickRecord, ttl time.Duration) error {
+	spanCtx, span := otel.Tracer(tracerName).Start(ctx, "SaveClick")
+	defer span.End()
+
+	out, err := json.Marshal(record)
+	if err != nil {
+		return fmt.Errorf("could not marshal: %w", err)
+	}
+
+	key := r.clickKey(record.ClickID)
+	if err := r.node(key).Set(spanCtx, key, out, ttl).Err(); err != nil {
+		return fmt.Errorf("could not save click: %w", err)
+	}
+
+	return nil
+}
+
+func (r *Redis) GetClick(ctx context.Context, clickID string) (*entity.ClickRecord, error) {
+	spanCtx, span := otel.Tracer(tracerName).Start(ctx, "GetClick")
+	defer span.End()
+
+	key := r.clickKey(clickID)
+
+	out, err := r.node(key).Get(spanCtx, key).Result()
+	if err == redis.Nil {
+		return nil, entity.ErrUnknownClick
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("could not get click: %w", err)
+	}
+
+	var record entity.ClickRecord
+	if err := json.Unmarshal([]byte(out), &record); err != nil {
+		return nil, fmt.Errorf("could not unmarshal: %w", err)
+	}
+
+	return &record, nil
+}
+
+func (r *Redis) conversionKey(clickID string) string {
+	return fmt.Sprintf("click.conversion.%s", clickID)
+}
+
+func (r *Redis) MarkConversion(ctx context.Context, clickID string, ttl time.Duration) (bool, error) {
+	spanCtx, span := otel.Tracer(tracerName).Start(ctx, "MarkConversion")
+	defer span.End()
+
+	key := r.conversionKey(clickID)
+
+	marked, err := r.node(key).SetNX(spanCtx, key, 1, ttl).Result()
+	if err != nil {
+		return false, fmt.Errorf("could not mark conversion: %w", err)
+	}
+
+	return !marked, nil
+}
@@ -8,26 +8,35 @@ import (
 	"github.com/crxfoz/teaserad/adclick/internal/domain"
 	"github.com/crxfoz/teaserad/adclick/internal/domain/entity"
 	"github.com/crxfoz/teaserad/adclick/internal/domain/events"
+	"github.com/google/uuid"
 	"go.opentelemetry.io/otel"
 )
 
 type ClickRepo interface {
 	AddBanner(ctx context.Context, banner *entity.BannerURL) error
 	GetBanner(ctx context.Context, bannerID int) (*entity.BannerURL, error)
+	SaveClick(ctx context.Context, record *entity.ClickRecord, ttl time.Duration) error
+	GetClick(ctx context.Context, clickID string) (*entity.ClickRecord, error)
+	MarkConversion(ctx context.Context, clickID string, ttl time.Duration) (bool, error)
+	UnmarkConversion(ctx context.Context, clickID string) error
 }
 
 type ClickNotifier interface {
 	SendClick(ctx context.Context, event *events.Click) error
+	SendInvalidClick(ctx context.Context, event *events.InvalidClick) error
+	SendConversion(ctx context.Context, event *events.Conversion) error
 }
 
 type Service struct {
 	clickRepo     ClickRepo
 	clickNotifier ClickNotifier
+	rules         []Rule
 	logger        domain.Logger
 }
 
-func New(clickRepo ClickRepo, clickNotifier ClickNotifier, logger domain.Logger) *Service {
-	return &Service{clickRepo: clickRepo, clickNotifier: clickNotifier, logger: logger}
+// New creates Service, clicks are checked by the rules in order and the first failed one tells the reason
+func New(clickRepo ClickRepo, clickNotifier ClickNotifier, logger domain.Logger, rules ...Rule) *Service {
+	return &Service{clickRepo: clickRepo, clickNotifier: clickNotifier, rules: rules, logger: logger}
 }
 
 const (
@@ -45,25 +54,116 @@ func (s *Service) AddBanner(ctx context.Context, banner *entity.BannerURL) error
 	return nil
 }
 
-func (s *Service) NewClick(ctx context.Context, bannerID int, platformID int, viewID string) (*entity.BannerURL, error) {
+// NewClick registers the click with price the banner won the auction with, clicks filtered out
+// by the rules are registered as invalid ones, valid ones get click ID appended to the landing URL
+func (s *Service) NewClick(ctx context.Context, click *entity.Click) (*entity.BannerURL, error) {
 	spanCtx, span := otel.Tracer(tracerName).Start(ctx, "NewClick")
 	defer span.End()
 
-	info, err := s.clickRepo.GetBanner(spanCtx, bannerID)
+	info, err := s.clickRepo.GetBanner(spanCtx, click.BannerID)
 	if err != nil {
-		return nil, fmt.Errorf("could not get banner - %d: %w", bannerID, err)
+		return nil, fmt.Errorf("could not get banner - %d: %w", click.BannerID, err)
+	}
+
+	now := time.Now()
+
+	if reason := s.filter(spanCtx, click, now); reason != "" {
+		if err := s.sendInvalid(spanCtx, click, reason, now); err != nil {
+			return nil, err
+		}
+
+		return s.invalidLanding(info, click), nil
+	}
+
+	record := &entity.ClickRecord{
+		ClickID:    uuid.NewString(),
+		BannerID:   click.BannerID,
+		PlatformID: click.PlatformID,
+		ViewID:     click.ViewID,
+		Price:      info.ClickPrice(click.Price),
+		CreatedAt:  now.UTC().Unix(),
+	}
+
+	if err := s.clickRepo.SaveClick(spanCtx, record, conversionWindow); err != nil {
+		return nil, fmt.Errorf("could not save click: %w", err)
 	}
 
 	err = s.clickNotifier.SendClick(spanCtx, &events.Click{
-		BannerID:   bannerID,
-		PlatformID: platformID,
-		ViewID:     viewID,
-		Price:      1,
-		CreatedAt:  time.Now().UTC().Unix(),
+		ClickID:    record.ClickID,
+		BannerID:   record.BannerID,
+		PlatformID: record.PlatformID,
+		ViewID:     record.ViewID,
+		Price:      record.Price,
+		CreatedAt:  record.CreatedAt,
 	})
 	if err != nil {
 		return nil, fmt.Errorf("could not send click: %w", err)
 	}
 
-	return info, nil
+	return &entity.BannerURL{
+		BannerID:  info.BannerID,
+		BannerURL: info.LandingURL(click, record.ClickID),
+		BidType:   info.BidType,
+		Bid:       info.Bid,
+	}, nil
+}
+
+// InvalidClick registers the click without charging the advertiser and returns info of the banner to redirect the user to
+func (s *Service) InvalidClick(ctx context.Context, click *entity.Click, reason string) (*entity.BannerURL, error) {
+	spanCtx, span := otel.Tracer(tracerName).Start(ctx, "InvalidClick")
+	defer span.End()
+
+	info, err := s.clickRepo.GetBanner(spanCtx, click.BannerID)
+	if err != nil {
+		return nil, fmt.Errorf("could not get banner - %d: %w", click.BannerID, err)
+	}
+
+	if err := s.sendInvalid(spanCtx, click, reason, time.Now()); err != nil {
+		return nil, err
+	}
+
+	return s.invalidLanding(info, click), nil
+}
+
+// invalidLanding is the landing URL without click ID, so conversions can't be attributed to invalid clicks
+func (s *Service) invalidLanding(info *entity.BannerURL, click *entity.Click) *entity.BannerURL {
+	return &entity.BannerURL{
+		BannerID:  info.BannerID,
+		BannerURL: info.LandingURL(click, ""),
+		BidType:   info.BidType,
+		Bid:       info.Bid,
+	}
+}
+
+// filter returns a reason the click is invalid for, rules which failed to check the click are skipped
+// so clicks aren't lost while redis is unavailable
+func (s *Service) filter(ctx context.Context, click *entity.Click, now time.Time) string {
+	for _, rule := range s.rules {
+		reason, err := rule.Check(ctx, click, now)
+		if err != nil {
+			s.logger.Errorw("could not check click", "err", err, "bannerID", click.BannerID)
+			continue
+		}
+
+		if reason != "" {
+			return reason
+		}
+	}
+
+	return ""
+}
+
+func (s *Service) sendInvalid(ctx context.Context, click *entity.Click, reason string, now time.Time) error {
+	err := s.clickNotifier.SendInvalidClick(ctx, &events.InvalidClick{
+		BannerID:   click.BannerID,
+		PlatformID: click.PlatformID,
+		ViewID:     click.ViewID,
+		Reason:     reason,
+		CreatedAt:  now.UTC().Unix(),
+	})
+	if err != nil {
+		return fmt.Errorf("could not send invalid click: %w", err)
+	}
+
+	return nil
 }
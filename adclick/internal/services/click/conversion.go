@@ -0,0 +1,57 @@
+package click
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/crxfoz/teaserad/adclick/internal/domain/entity"
+	"github.com/crxfoz/teaserad/adclick/internal/domain/events"
+	"go.opentelemetry.io/otel"
+)
+
+// conversionWindow is how long after the click a conversion is attributed to it
+const conversionWindow = 30 * 24 * time.Hour
+
+// NewConversion registers a conversion reported by the advertiser for the click, every click converts once
+func (s *Service) NewConversion(ctx context.Context, clickID string, value float64) error {
+	spanCtx, span := otel.Tracer(tracerName).Start(ctx, "NewConversion")
+	defer span.End()
+
+	if value < 0 {
+		return fmt.Errorf("negative value: %f", value)
+	}
+
+	record, err := s.clickRepo.GetClick(spanCtx, clickID)
+	if err != nil {
+		return fmt.Errorf("could not get click - %s: %w", clickID, err)
+	}
+
+	converted, err := s.clickRepo.MarkConversion(spanCtx, clickID, conversionWindow)
+	if err != nil {
+		return fmt.Errorf("could not mark conversion: %w", err)
+	}
+
+	if converted {
+		return entity.ErrDuplicateConversion
+	}
+
+	err = s.clickNotifier.SendConversion(spanCtx, &events.Conversion{
+		ClickID:    record.ClickID,
+		BannerID:   record.BannerID,
+		PlatformID: record.PlatformID,
+		ViewID:     record.ViewID,
+		Value:      value,
+		CreatedAt:  time.Now().UTC().Unix(),
+	})
+	if err != nil {
+		// the mark is released, so the advertiser's retry is not taken for a duplicate
+		if unmarkErr := s.clickRepo.UnmarkConversion(spanCtx, clickID); unmarkErr != nil {
+			s.logger.Errorw("could not unmark conversion", "err", unmarkErr, "click_id", clickID)
+		}
+
+		return fmt.Errorf("could not send conversion: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,78 @@
+package click
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/crxfoz/teaserad/adclick/internal/domain/entity"
+	"github.com/crxfoz/teaserad/adclick/internal/domain/events"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+)
+
+type memoryClickRepo struct {
+	ClickRepo
+	clicks    map[string]*entity.ClickRecord
+	converted map[string]bool
+}
+
+func (m *memoryClickRepo) GetClick(_ context.Context, clickID string) (*entity.ClickRecord, error) {
+	record, ok := m.clicks[clickID]
+	if !ok {
+		return nil, entity.ErrUnknownClick
+	}
+
+	return record, nil
+}
+
+func (m *memoryClickRepo) MarkConversion(_ context.Context, clickID string, _ time.Duration) (bool, error) {
+	converted := m.converted[clickID]
+	m.converted[clickID] = true
+
+	return converted, nil
+}
+
+func (m *memoryClickRepo) UnmarkConversion(_ context.Context, clickID string) error {
+	delete(m.converted, clickID)
+	return nil
+}
+
+type memoryNotifier struct {
+	ClickNotifier
+	err         error
+	conversions []*events.Conversion
+}
+
+func (m *memoryNotifier) SendConversion(_ context.Context, event *events.Conversion) error {
+	if m.err != nil {
+		return m.err
+	}
+
+	m.conversions = append(m.conversions, event)
+
+	return nil
+}
+
+func TestService_NewConversion(t *testing.T) {
+	repo := &memoryClickRepo{
+		clicks:    map[string]*entity.ClickRecord{"click": {ClickID: "click", BannerID: 1, PlatformID: 2}},
+		converted: map[string]bool{},
+	}
+	notifier := &memoryNotifier{err: errors.New("kafka is down")}
+	service := New(repo, notifier, zap.NewNop().Sugar())
+	ctx := context.Background()
+
+	assert.Error(t, service.NewConversion(ctx, "click", 10))
+	assert.False(t, repo.converted["click"])
+
+	// the advertiser retries after the failure
+	notifier.err = nil
+	assert.NoError(t, service.NewConversion(ctx, "click", 10))
+	assert.Len(t, notifier.conversions, 1)
+
+	assert.ErrorIs(t, service.NewConversion(ctx, "click", 10), entity.ErrDuplicateConversion)
+	assert.ErrorIs(t, service.NewConversion(ctx, "unknown", 10), entity.ErrUnknownClick)
+	assert.Len(t, notifier.conversions, 1)
+}
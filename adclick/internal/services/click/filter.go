@@ -0,0 +1,146 @@
+package click
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/crxfoz/teaserad/adclick/internal/domain/entity"
+)
+
+// Rule checks the click and returns a reason it's invalid for, empty reason means the click is fine
+type Rule interface {
+	Check(ctx context.Context, click *entity.Click, now time.Time) (string, error)
+}
+
+type FilterRepo interface {
+	// MarkView marks the view as clicked and tells whether it has been clicked before
+	MarkView(ctx context.Context, viewID string, ttl time.Duration) (bool, error)
+	// CountClick counts the click within the current window of the key and returns clicks so far
+	CountClick(ctx context.Context, key string, window time.Duration) (int64, error)
+}
+
+// DedupeRule lets only the first click of every show through
+type DedupeRule struct {
+	repo FilterRepo
+	ttl  time.Duration
+}
+
+// NewDedupeRule creates DedupeRule, ttl should be at least the lifetime of a click URL
+func NewDedupeRule(repo FilterRepo, ttl time.Duration) *DedupeRule {
+	return &DedupeRule{repo: repo, ttl: ttl}
+}
+
+func (r *DedupeRule) Check(ctx context.Context, click *entity.Click, _ time.Time) (string, error) {
+	if click.ViewID == "" {
+		return "", nil
+	}
+
+	clicked, err := r.repo.MarkView(ctx, click.ViewID, r.ttl)
+	if err != nil {
+		return "", fmt.Errorf("could not mark view: %w", err)
+	}
+
+	if clicked {
+		return entity.ReasonDuplicate, nil
+	}
+
+	return "", nil
+}
+
+// RateRule limits clicks from the same IP or visitor within the window
+type RateRule struct {
+	repo   FilterRepo
+	reason string
+	key    func(click *entity.Click) string
+	limit  int64
+	window time.Duration
+}
+
+func NewIPRateRule(repo FilterRepo, limit int64, window time.Duration) *RateRule {
+	return &RateRule{repo: repo, reason: entity.ReasonIPRate, limit: limit, window: window, key: func(click *entity.Click) string {
+		if click.IP == "" {
+			return ""
+		}
+
+		return "ip." + click.IP
+	}}
+}
+
+func NewVisitorRateRule(repo FilterRepo, limit int64, window time.Duration) *RateRule {
+	return &RateRule{repo: repo, reason: entity.ReasonVisitor, limit: limit, window: window, key: func(click *entity.Click) string {
+		if click.VisitorID == "" {
+			return ""
+		}
+
+		return "visitor." + click.VisitorID
+	}}
+}
+
+func (r *RateRule) Check(ctx context.Context, click *entity.Click, _ time.Time) (string, error) {
+	key := r.key(click)
+	if key == "" {
+		return "", nil
+	}
+
+	clicks, err := r.repo.CountClick(ctx, key, r.window)
+	if err != nil {
+		return "", fmt.Errorf("could not count click: %w", err)
+	}
+
+	if clicks > r.limit {
+		return r.reason, nil
+	}
+
+	return "", nil
+}
+
+// botTokens are parts of User-Agent of crawlers and HTTP libraries, real browsers never have them
+var botTokens = []string{
+	"bot", "crawl", "spider", "slurp", "curl", "wget", "python", "go-http-client",
+	"java/", "okhttp", "headless", "phantomjs", "httpclient", "scrapy",
+}
+
+// BotRule filters clicks of known bots
+type BotRule struct{}
+
+func NewBotRule() *BotRule {
+	return &BotRule{}
+}
+
+func (r *BotRule) Check(_ context.Context, click *entity.Click, _ time.Time) (string, error) {
+	ua := strings.ToLower(strings.TrimSpace(click.UserAgent))
+	if ua == "" {
+		return entity.ReasonBot, nil
+	}
+
+	for _, token := range botTokens {
+		if strings.Contains(ua, token) {
+			return entity.ReasonBot, nil
+		}
+	}
+
+	return "", nil
+}
+
+// TooFastRule filters clicks made sooner after the show than a human is able to
+type TooFastRule struct {
+	min time.Duration
+}
+
+func NewTooFastRule(min time.Duration) *TooFastRule {
+	return &TooFastRule{min: min}
+}
+
+func (r *TooFastRule) Check(_ context.Context, click *entity.Click, now time.Time) (string, error) {
+	if click.ShownAt == 0 {
+		return "", nil
+	}
+
+	if now.Sub(time.Unix(click.ShownAt, 0)) < r.min {
+		return entity.ReasonTooFast, nil
+	}
+
+	return "", nil
+}
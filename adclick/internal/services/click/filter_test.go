@@ -0,0 +1,67 @@
+package click
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/crxfoz/teaserad/adclick/internal/domain/entity"
+	"github.com/stretchr/testify/assert"
+)
+
+type memoryFilterRepo struct {
+	views  map[string]bool
+	clicks map[string]int64
+}
+
+func (m *memoryFilterRepo) MarkView(_ context.Context, viewID string, _ time.Duration) (bool, error) {
+	clicked := m.views[viewID]
+	m.views[viewID] = true
+
+	return clicked, nil
+}
+
+func (m *memoryFilterRepo) CountClick(_ context.Context, key string, _ time.Duration) (int64, error) {
+	m.clicks[key]++
+
+	return m.clicks[key], nil
+}
+
+func TestRules(t *testing.T) {
+	repo := &memoryFilterRepo{views: map[string]bool{}, clicks: map[string]int64{}}
+	now := time.Unix(1700000000, 0)
+	ctx := context.Background()
+
+	click := &entity.Click{
+		ViewID:    "view",
+		ShownAt:   now.Add(-time.Minute).Unix(),
+		IP:        "10.0.0.1",
+		UserAgent: "Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/118.0 Safari/537.36",
+	}
+
+	tests := []struct {
+		name   string
+		rule   Rule
+		click  *entity.Click
+		reason string
+	}{
+		{name: "first click", rule: NewDedupeRule(repo, time.Hour), click: click, reason: ""},
+		{name: "second click", rule: NewDedupeRule(repo, time.Hour), click: click, reason: entity.ReasonDuplicate},
+		{name: "ip within limit", rule: NewIPRateRule(repo, 1, time.Hour), click: click, reason: ""},
+		{name: "ip over limit", rule: NewIPRateRule(repo, 1, time.Hour), click: click, reason: entity.ReasonIPRate},
+		{name: "no visitor", rule: NewVisitorRateRule(repo, 0, time.Hour), click: click, reason: ""},
+		{name: "browser", rule: NewBotRule(), click: click, reason: ""},
+		{name: "crawler", rule: NewBotRule(), click: &entity.Click{UserAgent: "Googlebot/2.1"}, reason: entity.ReasonBot},
+		{name: "no user agent", rule: NewBotRule(), click: &entity.Click{}, reason: entity.ReasonBot},
+		{name: "human speed", rule: NewTooFastRule(time.Second), click: click, reason: ""},
+		{name: "too fast", rule: NewTooFastRule(time.Second), click: &entity.Click{ShownAt: now.Unix()}, reason: entity.ReasonTooFast},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			reason, err := tt.rule.Check(ctx, tt.click, now)
+			assert.Nil(t, err)
+			assert.Equal(t, tt.reason, reason)
+		})
+	}
+}
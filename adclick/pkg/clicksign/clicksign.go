@@ -0,0 +1,148 @@
+// Package clicksign signs click URLs adshow emits and verifies them in adclick so clicks can't be forged
+package clicksign
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var (
+	ErrMissing    = errors.New("click is not signed")
+	ErrUnknownKey = errors.New("unknown key")
+	ErrSignature  = errors.New("wrong signature")
+	ErrExpired    = errors.New("click url expired")
+)
+
+// Key is a secret identified by ID, the ID is put in URL so keys can be rotated
+type Key struct {
+	ID     string
+	Secret []byte
+}
+
+// ParseKeys parses keys in form of "id1:secret1,id2:secret2", the first one is used to sign
+func ParseKeys(in string) ([]Key, error) {
+	var keys []Key
+
+	for _, item := range strings.Split(in, ",") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+
+		parts := strings.SplitN(item, ":", 2)
+		if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+			return nil, fmt.Errorf("wrong key: %s", item)
+		}
+
+		keys = append(keys, Key{ID: parts[0], Secret: []byte(parts[1])})
+	}
+
+	if len(keys) == 0 {
+		return nil, fmt.Errorf("no keys")
+	}
+
+	return keys, nil
+}
+
+// Click is what the signature is calculated over
+type Click struct {
+	BannerID   int
+	PlatformID int
+	ViewID     string
+	Price      float64
+	Timestamp  int64
+}
+
+func (c Click) payload() string {
+	return fmt.Sprintf("%d:%d:%s:%s:%d",
+		c.BannerID, c.PlatformID, c.ViewID, strconv.FormatFloat(c.Price, 'f', -1, 64), c.Timestamp)
+}
+
+// Signer signs with the first key and accepts signatures of every key so URLs emitted before rotation stay valid
+type Signer struct {
+	keys []Key
+	ttl  time.Duration
+}
+
+// New creates Signer, ttl is how long a click URL is valid after the show
+func New(keys []Key, ttl time.Duration) *Signer {
+	return &Signer{keys: keys, ttl: ttl}
+}
+
+func (s *Signer) sign(key Key, click Click) string {
+	mac := hmac.New(sha256.New, key.Secret)
+	_, _ = mac.Write([]byte(click.payload()))
+
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// Query returns query parameters of the click URL
+func (s *Signer) Query(click Click) url.Values {
+	key := s.keys[0]
+
+	query := url.Values{}
+	query.Set("pid", strconv.Itoa(click.PlatformID))
+	query.Set("bid", strconv.Itoa(click.BannerID))
+	query.Set("view", click.ViewID)
+	query.Set("price", strconv.FormatFloat(click.Price, 'f', -1, 64))
+	query.Set("ts", strconv.FormatInt(click.Timestamp, 10))
+	query.Set("kid", key.ID)
+	query.Set("sig", s.sign(key, click))
+
+	return query
+}
+
+// Verify checks the signature and the expiry of the click URL
+func (s *Signer) Verify(query url.Values, now time.Time) (Click, error) {
+	var click Click
+
+	sig := query.Get("sig")
+	if sig == "" {
+		return click, ErrMissing
+	}
+
+	var err error
+	if click.PlatformID, err = strconv.Atoi(query.Get("pid")); err != nil {
+		return click, fmt.Errorf("wrong platform id: %w", err)
+	}
+
+	if click.BannerID, err = strconv.Atoi(query.Get("bid")); err != nil {
+		return click, fmt.Errorf("wrong banner id: %w", err)
+	}
+
+	if click.Price, err = strconv.ParseFloat(query.Get("price"), 64); err != nil {
+		return click, fmt.Errorf("wrong price: %w", err)
+	}
+
+	if click.Timestamp, err = strconv.ParseInt(query.Get("ts"), 10, 64); err != nil {
+		return click, fmt.Errorf("wrong timestamp: %w", err)
+	}
+
+	click.ViewID = query.Get("view")
+
+	kid := query.Get("kid")
+	for _, key := range s.keys {
+		if key.ID != kid {
+			continue
+		}
+
+		if !hmac.Equal([]byte(sig), []byte(s.sign(key, click))) {
+			return click, ErrSignature
+		}
+
+		if now.Sub(time.Unix(click.Timestamp, 0)) > s.ttl {
+			return click, ErrExpired
+		}
+
+		return click, nil
+	}
+
+	return click, ErrUnknownKey
+}
@@ -0,0 +1,52 @@
+package clicksign
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSigner_Verify(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+	click := Click{BannerID: 10, PlatformID: 20, ViewID: "view", Price: 0.35, Timestamp: now.Unix()}
+
+	oldKeys, err := ParseKeys("v1:old")
+	assert.Nil(t, err)
+
+	newKeys, err := ParseKeys("v2:new, v1:old")
+	assert.Nil(t, err)
+
+	query := New(oldKeys, time.Hour).Query(click)
+
+	// URLs signed before rotation are still accepted
+	got, err := New(newKeys, time.Hour).Verify(query, now.Add(time.Minute))
+	assert.Nil(t, err)
+	assert.Equal(t, click, got)
+
+	_, err = New(newKeys, time.Hour).Verify(query, now.Add(2*time.Hour))
+	assert.ErrorIs(t, err, ErrExpired)
+
+	_, err = New([]Key{{ID: "v2", Secret: []byte("new")}}, time.Hour).Verify(query, now)
+	assert.ErrorIs(t, err, ErrUnknownKey)
+
+	query.Set("price", "0.01")
+	_, err = New(newKeys, time.Hour).Verify(query, now)
+	assert.ErrorIs(t, err, ErrSignature)
+
+	query.Del("sig")
+	_, err = New(newKeys, time.Hour).Verify(query, now)
+	assert.ErrorIs(t, err, ErrMissing)
+}
+
+func TestParseKeys(t *testing.T) {
+	_, err := ParseKeys("")
+	assert.NotNil(t, err)
+
+	_, err = ParseKeys("v1")
+	assert.NotNil(t, err)
+
+	keys, err := ParseKeys("v1:a:b")
+	assert.Nil(t, err)
+	assert.Equal(t, []Key{{ID: "v1", Secret: []byte("a:b")}}, keys)
+}
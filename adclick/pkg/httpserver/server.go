@@ -21,6 +21,8 @@ type Server struct {
 func New(userRouter *httpdel.Router) *Server {
 	e := echo.New()
 	e.HideBanner = true
+	// adclick is behind nginx, rate limits need the real client IP
+	e.IPExtractor = echo.ExtractIPFromXFFHeader()
 
 	return &Server{
 		e:      e,
@@ -34,8 +36,11 @@ func (s *Server) BuildRoutes() {
 
 	userAPIV1 := s.e.Group("/api/v1")
 	userAPIV1.GET("/click", s.router.RegisterClick)
+	userAPIV1.GET("/conversion", s.router.Conversion)
+	userAPIV1.POST("/conversion", s.router.Conversion)
 
 	s.e.GET("/click", s.router.HTMLClick)
+	s.e.GET("/conversion.gif", s.router.ConversionPixel)
 }
 
 func (s *Server) Start(port int) error {
@@ -0,0 +1,166 @@
+// Package landing expands macros of landing URLs advertisers set on banners, crmad validates
+// the templates with it and adclick expands them at redirect time
+package landing
+
+import (
+	"fmt"
+	"net/url"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+const (
+	MacroBannerID   = "banner_id"
+	MacroPlatformID = "platform_id"
+	MacroClickID    = "click_id"
+	MacroDevice     = "device"
+	MacroCategory   = "category"
+)
+
+var (
+	macros = map[string]bool{
+		MacroBannerID:   true,
+		MacroPlatformID: true,
+		MacroClickID:    true,
+		MacroDevice:     true,
+		MacroCategory:   true,
+	}
+
+	macroRe = regexp.MustCompile(`\{([a-z_]+)\}`)
+)
+
+// Values of macros by their names
+type Values map[string]string
+
+// ValidateMacros checks the text has only known macros and no unbalanced braces
+func ValidateMacros(text string) error {
+	for _, match := range macroRe.FindAllStringSubmatch(text, -1) {
+		if !macros[match[1]] {
+			return fmt.Errorf("unknown macro: %s", match[0])
+		}
+	}
+
+	if rest := macroRe.ReplaceAllString(text, ""); strings.ContainsAny(rest, "{}") {
+		return fmt.Errorf("unbalanced braces: %s", text)
+	}
+
+	return nil
+}
+
+// Validate checks the template is an absolute http URL with known macros only
+func Validate(template string) error {
+	if err := ValidateMacros(template); err != nil {
+		return err
+	}
+
+	sample := Values{}
+	for name := range macros {
+		sample[name] = "1"
+	}
+
+	u, err := url.Parse(Expand(template, sample))
+	if err != nil {
+		return fmt.Errorf("wrong url: %w", err)
+	}
+
+	if (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return fmt.Errorf("url must be absolute http one: %s", template)
+	}
+
+	return nil
+}
+
+// HasMacro tells whether the template has the macro
+func HasMacro(template string, name string) bool {
+	return strings.Contains(template, "{"+name+"}")
+}
+
+// Expand replaces macros with values escaped for the part of URL they are in, unknown macros are left as they are
+func Expand(template string, values Values) string {
+	query := strings.IndexAny(template, "?#")
+	if query < 0 {
+		query = len(template)
+	}
+
+	return expand(template, values, func(pos int, value string) string {
+		if pos < query {
+			return url.PathEscape(value)
+		}
+
+		return url.QueryEscape(value)
+	})
+}
+
+// expand replaces macros with values escaped by the position of the macro in the template
+func expand(template string, values Values, escape func(pos int, value string) string) string {
+	var out strings.Builder
+
+	last := 0
+
+	for _, loc := range macroRe.FindAllStringSubmatchIndex(template, -1) {
+		name := template[loc[2]:loc[3]]
+		if !macros[name] {
+			continue
+		}
+
+		out.WriteString(template[last:loc[0]])
+		out.WriteString(escape(loc[0], values[name]))
+		last = loc[1]
+	}
+
+	out.WriteString(template[last:])
+
+	return out.String()
+}
+
+// Build expands the template and appends params which aren't in the URL yet, values of params may have macros too.
+// The query of the advertiser is kept as it is, since tracking URLs may be signed or depend on the order of params
+func Build(template string, values Values, params map[string]string) string {
+	out := Expand(template, values)
+	if len(params) == 0 {
+		return out
+	}
+
+	u, err := url.Parse(out)
+	if err != nil {
+		return out
+	}
+
+	query := u.Query()
+
+	names := make([]string, 0, len(params))
+	for name, value := range params {
+		if value == "" || query.Has(name) {
+			continue
+		}
+
+		names = append(names, name)
+	}
+
+	if len(names) == 0 {
+		return out
+	}
+
+	sort.Strings(names)
+
+	added := make([]string, 0, len(names))
+	for _, name := range names {
+		value := expand(params[name], values, func(_ int, value string) string { return value })
+		added = append(added, url.QueryEscape(name)+"="+url.QueryEscape(value))
+	}
+
+	base, fragment := out, ""
+	if i := strings.IndexByte(out, '#'); i >= 0 {
+		base, fragment = out[:i], out[i:]
+	}
+
+	switch {
+	case !strings.Contains(base, "?"):
+		base += "?"
+	case !strings.HasSuffix(base, "?") && !strings.HasSuffix(base, "&"):
+		base += "&"
+	}
+
+	return base + strings.Join(added, "&") + fragment
+}
@@ -0,0 +1,47 @@
+package landing
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidate(t *testing.T) {
+	assert.Nil(t, Validate("https://shop.example/item?b={banner_id}&p={platform_id}&c={click_id}"))
+	assert.Nil(t, Validate("https://shop.example/{category}/{device}"))
+
+	assert.NotNil(t, Validate("https://shop.example/?b={banner}"))
+	assert.NotNil(t, Validate("https://shop.example/?b={banner_id"))
+	assert.NotNil(t, Validate("shop.example/item"))
+	assert.NotNil(t, Validate("javascript:alert(1)"))
+	assert.NotNil(t, Validate(""))
+}
+
+func TestBuild(t *testing.T) {
+	values := Values{
+		MacroBannerID:   "10",
+		MacroPlatformID: "20",
+		MacroClickID:    "abc",
+		MacroDevice:     "mobile",
+		MacroCategory:   "home & garden",
+	}
+
+	got := Build("https://shop.example/{device}?cat={category}&utm_source=own", values, map[string]string{
+		"utm_source":   "teaserad",
+		"utm_medium":   "cpc",
+		"utm_campaign": "banner_{banner_id}",
+		"utm_content":  "",
+	})
+
+	assert.Equal(t, "https://shop.example/mobile?cat=home+%26+garden&utm_source=own&utm_campaign=banner_10&utm_medium=cpc", got)
+
+	// macros of the path are escaped as path segments
+	assert.Equal(t, "https://shop.example/home%20&%20garden/abc?c=home+%26+garden",
+		Build("https://shop.example/{category}/{click_id}?c={category}", values, nil))
+
+	// signed query of the advertiser is left untouched, params go before the fragment
+	assert.Equal(t, "https://shop.example/?z=1&a=%7e&sig=ff&utm_source=teaserad#top",
+		Build("https://shop.example/?z=1&a=%7e&sig=ff#top", values, map[string]string{"utm_source": "teaserad"}))
+	assert.Equal(t, "https://shop.example/item?utm_medium=cpc",
+		Build("https://shop.example/item", values, map[string]string{"utm_medium": "cpc"}))
+}
@@ -1,9 +1,11 @@
 package main
 
 import (
+	"context"
 	"os"
 	"os/signal"
 	"syscall"
+	"time"
 
 	"github.com/Shopify/sarama"
 	kafkaDelivery "github.com/crxfoz/teaserad/adeliver/internal/delivery/kafka"
@@ -76,7 +78,7 @@ func main() {
 	bannerService := banner.New(rRepo, kafRepo, adshowGateway)
 	delivery := kafkaDelivery.New(bannerService)
 
-	kafBuilder := kafka.New(logger)
+	kafBuilder := kafka.New(logger, kafkaProducerRepo)
 
 	// consumer group for events
 	// clicks and shows
@@ -87,8 +89,10 @@ func main() {
 	}
 
 	kafSessEvents, err := kafBuilder.NewConsumer("adeliver-consumer-events", kafConsumerEvents, func(sess *kafka.Session) error {
+		// clicks of different banners are counted concurrently, views are counted in batches
+		sess.SetWorkers(8)
 		sess.AddRoute("adclick.action.click", delivery.OnActionClick)
-		sess.AddRoute("adshow.action.show", delivery.OnActionView)
+		sess.AddBatchRoute("adshow.action.show", delivery.OnActionViews, 500, 100*time.Millisecond)
 		return nil
 	})
 	if err != nil {
@@ -103,7 +107,7 @@ func main() {
 	}()
 
 	// consumer group for banners
-	// start and stop
+	// start, stop and update
 	kafConsumerBanners, err := sarama.NewConsumerGroup(kafkaBrokers, "adeliver-banners", kafkaCfg)
 	if err != nil {
 		cmdLogger.Fatalw("could not create consumer group", "err", err)
@@ -113,6 +117,9 @@ func main() {
 	kafSessBanners, err := kafBuilder.NewConsumer("adeliver-consumer-banners", kafConsumerBanners, func(sess *kafka.Session) error {
 		sess.AddRoute("adeliver.banner.start", delivery.OnBannerStarted)
 		sess.AddRoute("adeliver.banner.stop", delivery.OnBannerStopped)
+		sess.AddRoute("adeliver.banner.update", delivery.OnBannerUpdated)
+		sess.AddRoute("adeliver.banner.creative", delivery.OnBannerCreative)
+		sess.AddRoute("adeliver.campaign.limits", delivery.OnCampaignUpdated)
 		return nil
 	})
 	if err != nil {
@@ -126,6 +133,24 @@ func main() {
 		}
 	}()
 
+	// banners paused by daily and hourly caps are resumed when a new period begins
+	resumeDone := make(chan struct{})
+	go func() {
+		ticker := time.NewTicker(time.Minute)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ticker.C:
+				if err := bannerService.ResumePaused(context.Background()); err != nil {
+					cmdLogger.Errorw("could not resume paused banners", "err", err)
+				}
+			case <-resumeDone:
+				return
+			}
+		}
+	}()
+
 	interrupt := make(chan os.Signal, 1)
 	signal.Notify(interrupt, os.Interrupt, syscall.SIGTERM)
 
@@ -134,6 +159,8 @@ func main() {
 		cmdLogger.Info("app stopped - signal:", s.String())
 	}
 
+	close(resumeDone)
+
 	if err := kafSessBanners.Stop(); err != nil {
 		cmdLogger.Errorw("could not stop consumer gracefuly", "err", err, "kind", "banners")
 	}
@@ -0,0 +1,67 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/Shopify/sarama"
+	"github.com/crxfoz/teaserad/adeliver/pkg/kafka"
+	"go.uber.org/zap"
+)
+
+// dlqreplay moves messages of the dead letter topic back to the source topic once their handler is fixed
+func main() {
+	topic := flag.String("topic", "", "source topic, its messages are taken from <topic>.dlq")
+	flag.Parse()
+
+	z, err := zap.NewDevelopment()
+	if err != nil {
+		panic(err)
+	}
+
+	defer z.Sync()
+
+	cmdLogger := z.Sugar().Named("dlqreplay")
+
+	if *topic == "" {
+		cmdLogger.Errorw("topic is required")
+		return
+	}
+
+	// TODO: isolate
+	kafkaBrokers := []string{"kafka-1:9092", "kafka-2:9092", "kafka-3:9092"}
+
+	kafkaCfg := sarama.NewConfig()
+	kafkaCfg.Producer.Return.Successes = true
+	kafkaCfg.Consumer.Return.Errors = true
+
+	client, err := sarama.NewClient(kafkaBrokers, kafkaCfg)
+	if err != nil {
+		cmdLogger.Errorw("could not connect to kafka", "err", err)
+		return
+	}
+
+	defer client.Close()
+
+	producer, err := sarama.NewSyncProducerFromClient(client)
+	if err != nil {
+		cmdLogger.Errorw("could not create producer", "err", err)
+		return
+	}
+
+	defer producer.Close()
+
+	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer cancel()
+
+	replayed, err := kafka.Replay(ctx, client, producer, *topic)
+	if err != nil {
+		cmdLogger.Errorw("could not replay messages", "err", err, "topic", *topic, "replayed", replayed)
+		return
+	}
+
+	cmdLogger.Infow("messages replayed", "topic", *topic, "replayed", replayed)
+}
@@ -2,19 +2,24 @@ package kafka
 
 import (
 	"context"
-	"encoding/json"
+	"errors"
 	"fmt"
 
 	"github.com/Shopify/sarama"
+	"github.com/crxfoz/teaserad/adeliver/internal/domain/entity"
 	"github.com/crxfoz/teaserad/adeliver/internal/domain/events"
+	kafkapkg "github.com/crxfoz/teaserad/adeliver/pkg/kafka"
 	"go.opentelemetry.io/otel"
 )
 
 type BannerService interface {
 	StopBanner(ctx context.Context, incoming events.BannerStoppedIncoming) error
 	StartBanner(ctx context.Context, incoming events.BannerStartedIncoming) error
+	UpdateLimits(ctx context.Context, incoming events.BannerLimitsIncoming) error
+	UpdateCreative(ctx context.Context, incoming events.BannerCreativeIncoming) error
+	UpdateCampaign(ctx context.Context, incoming events.CampaignLimitsIncoming) error
 	NewClick(ctx context.Context, incoming events.Click) error
-	NewView(ctx context.Context, incoming events.View) error
+	NewViews(ctx context.Context, incoming []events.View) error
 }
 
 type Consumer struct {
@@ -34,7 +39,7 @@ func (c *Consumer) OnBannerStopped(ctx context.Context, msg *sarama.ConsumerMess
 	defer span.End()
 
 	var stopped events.BannerStoppedIncoming
-	if err := json.Unmarshal(msg.Value, &stopped); err != nil {
+	if err := kafkapkg.Decode(msg, &stopped); err != nil {
 		return fmt.Errorf("could not parse message: %w", err)
 	}
 
@@ -46,23 +51,87 @@ func (c *Consumer) OnBannerStarted(ctx context.Context, msg *sarama.ConsumerMess
 	defer span.End()
 
 	var started events.BannerStartedIncoming
-	if err := json.Unmarshal(msg.Value, &started); err != nil {
+	if err := kafkapkg.Decode(msg, &started); err != nil {
 		return fmt.Errorf("could not parse message: %w", err)
 	}
 
 	return c.bannerSvc.StartBanner(spanCtx, started)
 }
 
-func (c *Consumer) OnActionView(ctx context.Context, msg *sarama.ConsumerMessage) error {
-	spanCtx, span := otel.Tracer(tracerName).Start(ctx, "OnActionView")
+func (c *Consumer) OnBannerUpdated(ctx context.Context, msg *sarama.ConsumerMessage) error {
+	spanCtx, span := otel.Tracer(tracerName).Start(ctx, "OnBannerUpdated")
 	defer span.End()
 
-	var view events.View
-	if err := json.Unmarshal(msg.Value, &view); err != nil {
+	var limits events.BannerLimitsIncoming
+	if err := kafkapkg.Decode(msg, &limits); err != nil {
 		return fmt.Errorf("could not parse message: %w", err)
 	}
 
-	return c.bannerSvc.NewView(spanCtx, view)
+	return c.bannerSvc.UpdateLimits(spanCtx, limits)
+}
+
+func (c *Consumer) OnBannerCreative(ctx context.Context, msg *sarama.ConsumerMessage) error {
+	spanCtx, span := otel.Tracer(tracerName).Start(ctx, "OnBannerCreative")
+	defer span.End()
+
+	var creative events.BannerCreativeIncoming
+	if err := kafkapkg.Decode(msg, &creative); err != nil {
+		return fmt.Errorf("could not parse message: %w", err)
+	}
+
+	return c.bannerSvc.UpdateCreative(spanCtx, creative)
+}
+
+func (c *Consumer) OnCampaignUpdated(ctx context.Context, msg *sarama.ConsumerMessage) error {
+	spanCtx, span := otel.Tracer(tracerName).Start(ctx, "OnCampaignUpdated")
+	defer span.End()
+
+	var limits events.CampaignLimitsIncoming
+	if err := kafkapkg.Decode(msg, &limits); err != nil {
+		return fmt.Errorf("could not parse message: %w", err)
+	}
+
+	return c.bannerSvc.UpdateCampaign(spanCtx, limits)
+}
+
+// OnActionViews reports messages which could not be parsed or whose banners have failed,
+// so only they are retried and dead-lettered
+func (c *Consumer) OnActionViews(ctx context.Context, msgs []*sarama.ConsumerMessage) error {
+	spanCtx, span := otel.Tracer(tracerName).Start(ctx, "OnActionViews")
+	defer span.End()
+
+	var batchErr kafkapkg.BatchError
+
+	views := make([]events.View, 0, len(msgs))
+	byBanner := make(map[int][]*sarama.ConsumerMessage)
+
+	for _, msg := range msgs {
+		var view events.View
+		if err := kafkapkg.Decode(msg, &view); err != nil {
+			batchErr.Add(msg, fmt.Errorf("could not parse message: %w", err))
+			continue
+		}
+
+		views = append(views, view)
+		byBanner[view.BannerID] = append(byBanner[view.BannerID], msg)
+	}
+
+	err := c.bannerSvc.NewViews(spanCtx, views)
+
+	var bannersErr *entity.BannersError
+
+	switch {
+	case errors.As(err, &bannersErr):
+		for bannerID, bannerErr := range bannersErr.Errs {
+			for _, msg := range byBanner[bannerID] {
+				batchErr.Add(msg, bannerErr)
+			}
+		}
+	case err != nil:
+		return err
+	}
+
+	return batchErr.ErrOrNil()
 }
 
 func (c *Consumer) OnActionClick(ctx context.Context, msg *sarama.ConsumerMessage) error {
@@ -70,7 +139,7 @@ func (c *Consumer) OnActionClick(ctx context.Context, msg *sarama.ConsumerMessag
 	defer span.End()
 
 	var click events.Click
-	if err := json.Unmarshal(msg.Value, &click); err != nil {
+	if err := kafkapkg.Decode(msg, &click); err != nil {
 		return fmt.Errorf("could not parse message: %w", err)
 	}
 
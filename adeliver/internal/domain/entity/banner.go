@@ -1,8 +1,66 @@
 package entity
 
+import (
+	"math"
+	"time"
+)
+
+const (
+	// pacingTolerance is how much delivery may run ahead of the even schedule before it gets throttled
+	pacingTolerance = 0.1
+	// pacingMinProbability keeps throttled banner being served at least sometimes
+	pacingMinProbability = 0.05
+	// pacingStep is a granularity of probability so small fluctuations don't produce new events
+	pacingStep = 0.1
+)
+
 type Banner struct {
-	ID          int     `json:"id"`
+	ID         int `json:"id"`
+	CampaignID int `json:"campaign_id"`
+	// lifetime limits, 0 means no limit
 	LimitShows  int64   `json:"limit_shows"`
 	LimitClicks int64   `json:"limit_clicks"`
 	LimitBudget float64 `json:"limit_budget"`
+	StartAt     int64   `json:"start_at"`
+	EndAt       int64   `json:"end_at"`
+	// daily and hourly caps, 0 means no cap
+	DailyShows   int64   `json:"daily_shows"`
+	DailyClicks  int64   `json:"daily_clicks"`
+	DailyBudget  float64 `json:"daily_budget"`
+	HourlyShows  int64   `json:"hourly_shows"`
+	HourlyClicks int64   `json:"hourly_clicks"`
+	HourlyBudget float64 `json:"hourly_budget"`
+}
+
+// HasFlight tells whether the banner has a bounded flight window to pace its delivery over
+func (b *Banner) HasFlight() bool {
+	return b.StartAt != 0 && b.EndAt > b.StartAt
+}
+
+// Pacing compares actual delivery with even delivery over the flight window and returns
+// a probability the banner should be served with: 1 means no throttling
+func (b *Banner) Pacing(now time.Time, shows int64, spend float64) float64 {
+	if !b.HasFlight() {
+		return 1
+	}
+
+	elapsed := float64(now.Unix()-b.StartAt) / float64(b.EndAt-b.StartAt)
+	expected := math.Min(math.Max(elapsed, 0), 1)
+
+	var actual float64
+	if b.LimitShows > 0 {
+		actual = math.Max(actual, float64(shows)/float64(b.LimitShows))
+	}
+
+	if b.LimitBudget > 0 {
+		actual = math.Max(actual, spend/b.LimitBudget)
+	}
+
+	if actual <= expected*(1+pacingTolerance) {
+		return 1
+	}
+
+	probability := math.Floor(expected/actual/pacingStep) * pacingStep
+
+	return math.Min(math.Max(probability, pacingMinProbability), 1)
 }
@@ -0,0 +1,46 @@
+package entity
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBanner_Pacing(t *testing.T) {
+	banner := &Banner{
+		LimitShows:  1000,
+		LimitBudget: 100,
+		StartAt:     1000,
+		EndAt:       2000,
+	}
+
+	tests := []struct {
+		name  string
+		now   int64
+		shows int64
+		spend float64
+		want  float64
+	}{
+		{name: "on schedule", now: 1500, shows: 500, spend: 50, want: 1},
+		{name: "within tolerance", now: 1500, shows: 540, spend: 50, want: 1},
+		{name: "shows ahead", now: 1500, shows: 1000, spend: 10, want: 0.5},
+		{name: "budget ahead", now: 1200, shows: 0, spend: 80, want: 0.2},
+		{name: "far ahead", now: 1001, shows: 900, spend: 0, want: pacingMinProbability},
+		{name: "behind schedule", now: 1900, shows: 100, spend: 10, want: 1},
+		{name: "after flight", now: 3000, shows: 1000, spend: 100, want: 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := banner.Pacing(time.Unix(tt.now, 0), tt.shows, tt.spend)
+			assert.InDelta(t, tt.want, got, 0.0001)
+		})
+	}
+}
+
+func TestBanner_PacingWithoutFlight(t *testing.T) {
+	banner := &Banner{LimitShows: 10, LimitBudget: 10}
+
+	assert.Equal(t, float64(1), banner.Pacing(time.Now(), 1000, 1000))
+}
@@ -0,0 +1,23 @@
+package entity
+
+// Campaign holds budgets shared by all banners of the campaign, 0 means no budget
+type Campaign struct {
+	ID          int     `json:"id"`
+	LimitBudget float64 `json:"limit_budget"`
+	DailyBudget float64 `json:"daily_budget"`
+}
+
+// Periods returns periods the campaign has a budget within, spend of its banners is counted only for them
+func (c *Campaign) Periods() []Period {
+	var out []Period
+
+	if c.LimitBudget > 0 {
+		out = append(out, PeriodLifetime)
+	}
+
+	if c.DailyBudget > 0 {
+		out = append(out, PeriodDay)
+	}
+
+	return out
+}
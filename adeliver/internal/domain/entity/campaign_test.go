@@ -0,0 +1,14 @@
+package entity
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCampaign_Periods(t *testing.T) {
+	assert.Empty(t, (&Campaign{}).Periods())
+	assert.Equal(t, []Period{PeriodLifetime}, (&Campaign{LimitBudget: 100}).Periods())
+	assert.Equal(t, []Period{PeriodDay}, (&Campaign{DailyBudget: 10}).Periods())
+	assert.Equal(t, []Period{PeriodLifetime, PeriodDay}, (&Campaign{LimitBudget: 100, DailyBudget: 10}).Periods())
+}
@@ -0,0 +1,33 @@
+package entity
+
+import "fmt"
+
+// BannersError tells which banners of a batch have failed, the rest of them are processed
+type BannersError struct {
+	Errs map[int]error
+}
+
+func (e *BannersError) Add(bannerID int, err error) {
+	if e.Errs == nil {
+		e.Errs = make(map[int]error)
+	}
+
+	e.Errs[bannerID] = err
+}
+
+// ErrOrNil returns nil when no banner has failed
+func (e *BannersError) ErrOrNil() error {
+	if len(e.Errs) == 0 {
+		return nil
+	}
+
+	return e
+}
+
+func (e *BannersError) Error() string {
+	for bannerID, err := range e.Errs {
+		return fmt.Sprintf("%d banners failed, e.g. banner %d: %v", len(e.Errs), bannerID, err)
+	}
+
+	return "no banners failed"
+}
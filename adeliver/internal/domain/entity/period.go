@@ -0,0 +1,62 @@
+package entity
+
+import "time"
+
+// Period is a window counters of the banner are compared against its limits within
+type Period string
+
+const (
+	PeriodLifetime Period = "lifetime"
+	PeriodDay      Period = "day"
+	PeriodHour     Period = "hour"
+)
+
+// Counter is a kind of interactions of the banner compared against its limits
+type Counter string
+
+const (
+	CounterClicks Counter = "click"
+	CounterShows  Counter = "show"
+	CounterSpend  Counter = "spend"
+)
+
+// Periods are ordered from the longest to the shortest one
+var Periods = []Period{PeriodLifetime, PeriodDay, PeriodHour}
+
+// Bucket names the window the moment belongs to, lifetime has the only unnamed bucket
+func (p Period) Bucket(now time.Time) string {
+	switch p {
+	case PeriodDay:
+		return now.UTC().Format("20060102")
+	case PeriodHour:
+		return now.UTC().Format("2006010215")
+	default:
+		return ""
+	}
+}
+
+// End returns the moment the next window begins, lifetime never ends
+func (p Period) End(now time.Time) time.Time {
+	now = now.UTC()
+
+	switch p {
+	case PeriodDay:
+		return time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC).AddDate(0, 0, 1)
+	case PeriodHour:
+		return now.Truncate(time.Hour).Add(time.Hour)
+	default:
+		return time.Time{}
+	}
+}
+
+// TTL is how long counters of the window are kept, 0 means forever
+func (p Period) TTL() time.Duration {
+	switch p {
+	case PeriodDay:
+		return 48 * time.Hour
+	case PeriodHour:
+		return 2 * time.Hour
+	default:
+		return 0
+	}
+}
@@ -0,0 +1,25 @@
+package entity
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPeriod_Bucket(t *testing.T) {
+	now := time.Date(2022, 3, 14, 23, 45, 0, 0, time.UTC)
+
+	assert.Equal(t, "", PeriodLifetime.Bucket(now))
+	assert.Equal(t, "20220314", PeriodDay.Bucket(now))
+	assert.Equal(t, "2022031423", PeriodHour.Bucket(now))
+}
+
+func TestPeriod_End(t *testing.T) {
+	now := time.Date(2022, 3, 14, 23, 45, 0, 0, time.UTC)
+
+	assert.True(t, PeriodLifetime.End(now).IsZero())
+	assert.Equal(t, time.Date(2022, 3, 15, 0, 0, 0, 0, time.UTC), PeriodDay.End(now))
+	assert.Equal(t, time.Date(2022, 3, 15, 0, 0, 0, 0, time.UTC), PeriodHour.End(now))
+	assert.Equal(t, time.Date(2022, 3, 14, 12, 0, 0, 0, time.UTC), PeriodHour.End(now.Add(-12*time.Hour)))
+}
@@ -1,9 +1,8 @@
 package events
 
-type Click struct {
-	BannerID int `json:"banner_id"`
-}
+import contracts "github.com/crxfoz/teaserad/contracts/events"
 
-type View struct {
-	BannerID int `json:"banner_id"`
-}
+type (
+	Click = contracts.Click
+	View  = contracts.View
+)
@@ -1,18 +1,15 @@
 package events
 
-type BannerStartedIncoming struct {
-	BannerID    int     `json:"banner_id"`
-	UserID      int     `json:"user_id"`
-	ImgData     []byte  `json:"img_data"`
-	BannerText  string  `json:"banner_text"`
-	BannerURL   string  `json:"banner_url"`
-	LimitShows  int64   `json:"limit_shows"`
-	LimitClicks int64   `json:"limit_clicks"`
-	LimitBudget float64 `json:"limit_budget"`
-	Device      string  `json:"device"`
-	CategoryID  int     `json:"category_id"`
-}
+import contracts "github.com/crxfoz/teaserad/contracts/events"
 
-type BannerStoppedIncoming struct {
-	BannerID int `json:"banner_id"`
-}
+// events are declared by the shared contracts, so every service agrees on their schemas
+type (
+	BannerStartedIncoming = contracts.BannerStart
+	BannerStoppedIncoming = contracts.BannerStop
+	// BannerLimitsIncoming carries limits and caps changed while the banner is delivered
+	BannerLimitsIncoming = contracts.BannerLimits
+	// BannerCreativeIncoming carries creative approved while the banner is delivered
+	BannerCreativeIncoming = contracts.BannerCreative
+	// CampaignLimitsIncoming carries budgets of the campaign shared by its banners
+	CampaignLimitsIncoming = contracts.CampaignLimits
+)
@@ -1,23 +1,14 @@
 package events
 
-type BannerStart struct {
-	BannerID    int     `json:"banner_id"`
-	UserID      int     `json:"user_id"`
-	ImgData     []byte  `json:"img_data"`
-	BannerText  string  `json:"banner_text"`
-	BannerURL   string  `json:"banner_url"`
-	LimitShows  int64   `json:"limit_shows"`
-	LimitClicks int64   `json:"limit_clicks"`
-	LimitBudget float64 `json:"limit_budget"`
-	Device      string  `json:"device"`
-	CategoryID  int     `json:"category_id"`
-}
+import contracts "github.com/crxfoz/teaserad/contracts/events"
 
-type BannerStop struct {
-	BannerID int `json:"banner_id"`
-}
-
-type BannerReachedLimits struct {
-	BannerID int    `json:"banner_id"`
-	Reason   string `json:"reason"`
-}
+type (
+	BannerStart      = contracts.ServingStart
+	BannerStop       = contracts.ServingStop
+	BannerThrottle   = contracts.ServingThrottle
+	BannerUnthrottle = contracts.ServingUnthrottle
+	// BannerPaused is sent when banner reached one of its caps and won't be shown until the next period
+	BannerPaused        = contracts.BannerPaused
+	BannerResumed       = contracts.BannerResumed
+	BannerReachedLimits = contracts.BannerReachedLimits
+)
@@ -2,25 +2,26 @@ package kafka
 
 import (
 	"context"
-	"encoding/json"
 	"fmt"
 
 	"github.com/Shopify/sarama"
 	"github.com/crxfoz/teaserad/adeliver/internal/domain/events"
-	"go.opentelemetry.io/contrib/instrumentation/github.com/Shopify/sarama/otelsarama"
+	kafkapkg "github.com/crxfoz/teaserad/adeliver/pkg/kafka"
 	"go.opentelemetry.io/otel"
 )
 
 const (
 	topicReachedLimits = "adeliver.banner.limits"
+	topicPaused        = "adeliver.banner.paused"
+	topicResumed       = "adeliver.banner.resumed"
 )
 
 type BannerRepo struct {
-	conn sarama.SyncProducer
+	producer *kafkapkg.Producer
 }
 
 func New(conn sarama.SyncProducer) *BannerRepo {
-	return &BannerRepo{conn: conn}
+	return &BannerRepo{producer: kafkapkg.NewProducer(conn, "adeliver")}
 }
 
 const (
@@ -31,22 +32,30 @@ func (r *BannerRepo) NotifyBannerStopped(ctx context.Context, event events.Banne
 	spanCtx, span := otel.Tracer(tracerName).Start(ctx, "NotifyBannerStopped")
 	defer span.End()
 
-	out, err := json.Marshal(event)
-	if err != nil {
-		return fmt.Errorf("could not marshal msg: %w", err)
+	if err := r.producer.Publish(spanCtx, topicReachedLimits, event); err != nil {
+		return fmt.Errorf("could not publish event: %w", err)
 	}
 
-	pitem := &sarama.ProducerMessage{
-		Topic: topicReachedLimits,
-		Key:   sarama.StringEncoder("1"), // TODO: use different keys
-		Value: sarama.ByteEncoder(out),
+	return nil
+}
+
+func (r *BannerRepo) NotifyBannerPaused(ctx context.Context, event events.BannerPaused) error {
+	spanCtx, span := otel.Tracer(tracerName).Start(ctx, "NotifyBannerPaused")
+	defer span.End()
+
+	if err := r.producer.Publish(spanCtx, topicPaused, event); err != nil {
+		return fmt.Errorf("could not publish event: %w", err)
 	}
 
-	otel.GetTextMapPropagator().Inject(spanCtx, otelsarama.NewProducerMessageCarrier(pitem))
+	return nil
+}
+
+func (r *BannerRepo) NotifyBannerResumed(ctx context.Context, event events.BannerResumed) error {
+	spanCtx, span := otel.Tracer(tracerName).Start(ctx, "NotifyBannerResumed")
+	defer span.End()
 
-	_, _, err = r.conn.SendMessage(pitem)
-	if err != nil {
-		return fmt.Errorf("could not send message: %w", err)
+	if err := r.producer.Publish(spanCtx, topicResumed, event); err != nil {
+		return fmt.Errorf("could not publish event: %w", err)
 	}
 
 	return nil
@@ -5,20 +5,39 @@ import (
 	"encoding/json"
 	"fmt"
 	"strconv"
+	"time"
 
 	"github.com/crxfoz/teaserad/adeliver/internal/domain/entity"
+	"github.com/crxfoz/teaserad/adeliver/internal/domain/events"
 	"github.com/go-redis/redis/v8"
 	"go.opentelemetry.io/otel"
 )
 
 const (
-	fieldClick = "click"
-	fieldShow  = "show"
-	fieldSpend = "spend"
+	fieldClick = string(entity.CounterClicks)
+	fieldShow  = string(entity.CounterShows)
+	fieldSpend = string(entity.CounterSpend)
 )
 
+// countedTTL is how long events are remembered as counted, retries of the event within it are not counted again
+const countedTTL = 24 * time.Hour
+
+// limitFields are fields of banner info holding limits of each kind of counter per period
+var limitFields = map[string]map[entity.Period]string{
+	fieldClick: {entity.PeriodLifetime: "limit_clicks", entity.PeriodDay: "daily_clicks", entity.PeriodHour: "hourly_clicks"},
+	fieldShow:  {entity.PeriodLifetime: "limit_shows", entity.PeriodDay: "daily_shows", entity.PeriodHour: "hourly_shows"},
+	fieldSpend: {entity.PeriodLifetime: "limit_budget", entity.PeriodDay: "daily_budget", entity.PeriodHour: "hourly_budget"},
+}
+
+// campaignLimitFields are fields of campaign info holding its budget per period
+var campaignLimitFields = map[entity.Period]string{entity.PeriodLifetime: "limit_budget", entity.PeriodDay: "daily_budget"}
+
+// keyPaused is a schedule of paused banners of the node scored by time they should be resumed at
+const keyPaused = "paused"
+
 type Cluster interface {
 	Node(int) *redis.Client
+	Nodes() []*redis.Client
 }
 
 type Redis struct {
@@ -39,10 +58,55 @@ func (r *Redis) ketInteractions(bannerID int, kind string) string {
 	return fmt.Sprintf("interactions.%d.%s", bannerID, kind)
 }
 
+func (r *Redis) keyPacing(bannerID int) string {
+	return fmt.Sprintf("pacing.%d", bannerID)
+}
+
+func (r *Redis) keyReached(bannerID int, kind string) string {
+	return fmt.Sprintf("reached.%d.%s", bannerID, kind)
+}
+
+func (r *Redis) keyCounted(bannerID int, kind string, eventID string) string {
+	return fmt.Sprintf("counted.%d.%s.%s", bannerID, kind, eventID)
+}
+
 func (r *Redis) keyInfo(bannerID int) string {
 	return fmt.Sprintf("info.%d", bannerID)
 }
 
+func (r *Redis) keyStart(bannerID int) string {
+	return fmt.Sprintf("start.%d", bannerID)
+}
+
+func (r *Redis) keyCampaign(campaignID int) string {
+	return fmt.Sprintf("campaign.%d", campaignID)
+}
+
+func (r *Redis) keyCampaignBanners(campaignID int) string {
+	return fmt.Sprintf("campaign.%d.banners", campaignID)
+}
+
+func (r *Redis) keyCampaignSpend(campaignID int) string {
+	return fmt.Sprintf("campaign.%d.spend", campaignID)
+}
+
+func (r *Redis) keyCampaignReached(campaignID int) string {
+	return fmt.Sprintf("campaign.%d.reached", campaignID)
+}
+
+func (r *Redis) keyCampaignCounted(campaignID int, eventID string) string {
+	return fmt.Sprintf("campaign.%d.counted.%s", campaignID, eventID)
+}
+
+// keyPeriod suffixes the key with the bucket of the period, lifetime keys stay as is
+func (r *Redis) keyPeriod(key string, period entity.Period, now time.Time) string {
+	if bucket := period.Bucket(now); bucket != "" {
+		return key + "." + bucket
+	}
+
+	return key
+}
+
 func (r *Redis) GetBanner(ctx context.Context, bannerID int) (*entity.Banner, error) {
 	spanCtx, span := otel.Tracer(tracerName).Start(ctx, "GetBanner")
 	defer span.End()
@@ -121,6 +185,10 @@ func (r *Redis) GetShows(ctx context.Context, bannerID int) (int64, error) {
 
 	res := conn.Get(spanCtx, r.ketInteractions(bannerID, fieldShow))
 	err := res.Err()
+	if err == redis.Nil {
+		return 0, nil
+	}
+
 	if err != nil {
 		return 0, fmt.Errorf("could not get shows: %w", err)
 	}
@@ -146,6 +214,10 @@ func (r *Redis) GetSpend(ctx context.Context, bannerID int) (float64, error) {
 
 	res := conn.Get(spanCtx, r.ketInteractions(bannerID, fieldSpend))
 	err := res.Err()
+	if err == redis.Nil {
+		return 0, nil
+	}
+
 	if err != nil {
 		return 0, fmt.Errorf("could not get spend: %w", err)
 	}
@@ -163,62 +235,508 @@ func (r *Redis) GetSpend(ctx context.Context, bannerID int) (float64, error) {
 	return out, nil
 }
 
-func (r *Redis) AddClick(ctx context.Context, bannerID int) (int64, error) {
+// AddClick, AddShows and AddSpend return periods whose limits have been crossed by this event,
+// the event is counted once however many times it's retried
+func (r *Redis) AddClick(ctx context.Context, bannerID int, clickID string, now time.Time) ([]entity.Period, error) {
 	spanCtx, span := otel.Tracer(tracerName).Start(ctx, "AddClick")
 	defer span.End()
 
+	return r.incrAndCheck(spanCtx, bannerID, fieldClick, []string{clickID}, 1, false, now)
+}
+
+// AddShows counts a show per view
+func (r *Redis) AddShows(ctx context.Context, bannerID int, viewIDs []string, now time.Time) ([]entity.Period, error) {
+	spanCtx, span := otel.Tracer(tracerName).Start(ctx, "AddShows")
+	defer span.End()
+
+	return r.incrAndCheck(spanCtx, bannerID, fieldShow, viewIDs, 1, false, now)
+}
+
+// AddSpend reports reaching the budget as soon as it is spent completely
+func (r *Redis) AddSpend(ctx context.Context, bannerID int, clickID string, price float64, now time.Time) ([]entity.Period, error) {
+	spanCtx, span := otel.Tracer(tracerName).Start(ctx, "AddSpend")
+	defer span.End()
+
+	return r.incrAndCheck(spanCtx, bannerID, fieldSpend, []string{clickID}, price, true, now)
+}
+
+// UnmarkReached removes reached flags of the periods, so the limit is reported again, e.g. to the retry of the event
+// whose crossing could not be handled
+func (r *Redis) UnmarkReached(ctx context.Context, bannerID int, counter entity.Counter, periods []entity.Period, now time.Time) error {
+	spanCtx, span := otel.Tracer(tracerName).Start(ctx, "UnmarkReached")
+	defer span.End()
+
+	if len(periods) == 0 {
+		return nil
+	}
+
 	conn := r.cluster.Node(bannerID)
 
-	res := conn.Incr(spanCtx, r.ketInteractions(bannerID, fieldClick))
-	err := res.Err()
+	var keys []string
+	for _, period := range periods {
+		keys = append(keys, r.keyPeriod(r.keyReached(bannerID, string(counter)), period, now))
+	}
+
+	if err := conn.Del(spanCtx, keys...).Err(); err != nil {
+		return fmt.Errorf("could not unmark reached flags: %w", err)
+	}
+
+	return nil
+}
+
+// ResetReached allows banner to report reaching its limits once again, e.g. after restart with new limits
+func (r *Redis) ResetReached(ctx context.Context, bannerID int, now time.Time) error {
+	spanCtx, span := otel.Tracer(tracerName).Start(ctx, "ResetReached")
+	defer span.End()
+
+	conn := r.cluster.Node(bannerID)
+
+	var keys []string
+	for _, kind := range []string{fieldClick, fieldShow, fieldSpend} {
+		for _, period := range entity.Periods {
+			keys = append(keys, r.keyPeriod(r.keyReached(bannerID, kind), period, now))
+		}
+	}
+
+	if err := conn.Del(spanCtx, keys...).Err(); err != nil {
+		return fmt.Errorf("could not reset reached flags: %w", err)
+	}
+
+	return nil
+}
+
+// Reached reports whether the banner has crossed any of its limits within the current periods,
+// such banner is paused or stopped and is not served now
+func (r *Redis) Reached(ctx context.Context, bannerID int, now time.Time) (bool, error) {
+	spanCtx, span := otel.Tracer(tracerName).Start(ctx, "Reached")
+	defer span.End()
+
+	conn := r.cluster.Node(bannerID)
+
+	var keys []string
+	for _, kind := range []string{fieldClick, fieldShow, fieldSpend} {
+		for _, period := range entity.Periods {
+			keys = append(keys, r.keyPeriod(r.keyReached(bannerID, kind), period, now))
+		}
+	}
+
+	found, err := conn.Exists(spanCtx, keys...).Result()
 	if err != nil {
-		return 0, fmt.Errorf("could not incr clicks: %w", err)
+		return false, fmt.Errorf("could not check reached flags: %w", err)
 	}
 
-	out, err := res.Result()
+	return found > 0, nil
+}
+
+func (r *Redis) incrAndCheck(ctx context.Context, bannerID int, kind string, eventIDs []string, delta float64, inclusive bool, now time.Time) ([]entity.Period, error) {
+	conn := r.cluster.Node(bannerID)
+
+	keys := []string{r.keyInfo(bannerID)}
+
+	for _, period := range entity.Periods {
+		keys = append(keys,
+			r.keyPeriod(r.ketInteractions(bannerID, kind), period, now),
+			r.keyPeriod(r.keyReached(bannerID, kind), period, now))
+	}
+
+	marks, unmarked := r.marks(eventIDs, func(eventID string) string {
+		return r.keyCounted(bannerID, kind, eventID)
+	})
+
+	keys = append(keys, marks...)
+	args := []interface{}{delta, inclusive, int64(countedTTL.Seconds()), unmarked}
+
+	for _, period := range entity.Periods {
+		args = append(args, limitFields[kind][period], int64(period.TTL().Seconds()))
+	}
+
+	res := scriptIncrAndCheck.Run(ctx, conn, keys, args...)
+
+	crossed, err := res.Int64Slice()
 	if err != nil {
-		return 0, fmt.Errorf("could not get result: %w", err)
+		return nil, fmt.Errorf("could not incr %s: %w", kind, err)
+	}
+
+	var out []entity.Period
+	for i, period := range entity.Periods {
+		if i < len(crossed) && crossed[i] == 1 {
+			out = append(out, period)
+		}
 	}
 
 	return out, nil
 }
 
-func (r *Redis) AddShow(ctx context.Context, bannerID int) (int64, error) {
-	spanCtx, span := otel.Tracer(tracerName).Start(ctx, "AddShow")
+// marks returns keys marking the events as counted and the number of events without id,
+// such events can't be told apart from each other and are counted every time
+func (r *Redis) marks(eventIDs []string, key func(eventID string) string) ([]string, int) {
+	var (
+		out      []string
+		unmarked int
+	)
+
+	for _, eventID := range eventIDs {
+		if eventID == "" {
+			unmarked++
+			continue
+		}
+
+		out = append(out, key(eventID))
+	}
+
+	return out, unmarked
+}
+
+// SetPacing stores current serving probability of the banner and reports whether it differs from the previous one
+func (r *Redis) SetPacing(ctx context.Context, bannerID int, probability float64) (bool, error) {
+	spanCtx, span := otel.Tracer(tracerName).Start(ctx, "SetPacing")
 	defer span.End()
 
 	conn := r.cluster.Node(bannerID)
 
-	res := conn.Incr(spanCtx, r.ketInteractions(bannerID, fieldShow))
-	err := res.Err()
+	value := strconv.FormatFloat(probability, 'f', 2, 64)
+
+	res := conn.GetSet(spanCtx, r.keyPacing(bannerID), value)
+	prev, err := res.Result()
+	if err == redis.Nil {
+		// banner has not been throttled yet
+		return probability < 1, nil
+	}
+
 	if err != nil {
-		return 0, fmt.Errorf("could not incr shows: %w", err)
+		return false, fmt.Errorf("could not set pacing: %w", err)
 	}
 
-	out, err := res.Result()
+	return prev != value, nil
+}
+
+// SaveStart keeps the start event of the banner so it can be started again after pause
+func (r *Redis) SaveStart(ctx context.Context, start events.BannerStart) error {
+	spanCtx, span := otel.Tracer(tracerName).Start(ctx, "SaveStart")
+	defer span.End()
+
+	conn := r.cluster.Node(start.BannerID)
+
+	raw, err := json.Marshal(start)
 	if err != nil {
-		return 0, fmt.Errorf("could not get result: %w", err)
+		return fmt.Errorf("could not marshal: %w", err)
+	}
+
+	if err := conn.Set(spanCtx, r.keyStart(start.BannerID), raw, 0).Err(); err != nil {
+		return fmt.Errorf("could not store start: %w", err)
+	}
+
+	return nil
+}
+
+func (r *Redis) GetStart(ctx context.Context, bannerID int) (*events.BannerStart, error) {
+	spanCtx, span := otel.Tracer(tracerName).Start(ctx, "GetStart")
+	defer span.End()
+
+	conn := r.cluster.Node(bannerID)
+
+	out, err := conn.Get(spanCtx, r.keyStart(bannerID)).Result()
+	if err != nil {
+		return nil, fmt.Errorf("could not get start: %w", err)
+	}
+
+	var start events.BannerStart
+	if err := json.Unmarshal([]byte(out), &start); err != nil {
+		return nil, fmt.Errorf("could not unmarshal: %w", err)
+	}
+
+	return &start, nil
+}
+
+// PauseBanner schedules the banner to be resumed and reports whether the pause has been set or extended
+func (r *Redis) PauseBanner(ctx context.Context, bannerID int, until time.Time) (bool, error) {
+	spanCtx, span := otel.Tracer(tracerName).Start(ctx, "PauseBanner")
+	defer span.End()
+
+	conn := r.cluster.Node(bannerID)
+
+	paused, err := scriptPause.Run(spanCtx, conn, []string{keyPaused}, bannerID, until.Unix()).Int()
+	if err != nil {
+		return false, fmt.Errorf("could not pause: %w", err)
+	}
+
+	return paused == 1, nil
+}
+
+// UnpauseBanner removes the banner from schedule, e.g. when it has been stopped for good
+func (r *Redis) UnpauseBanner(ctx context.Context, bannerID int) error {
+	spanCtx, span := otel.Tracer(tracerName).Start(ctx, "UnpauseBanner")
+	defer span.End()
+
+	conn := r.cluster.Node(bannerID)
+
+	if err := conn.ZRem(spanCtx, keyPaused, bannerID).Err(); err != nil {
+		return fmt.Errorf("could not unpause: %w", err)
+	}
+
+	return nil
+}
+
+// PausedDue returns banners whose pause is over
+func (r *Redis) PausedDue(ctx context.Context, now time.Time) ([]int, error) {
+	spanCtx, span := otel.Tracer(tracerName).Start(ctx, "PausedDue")
+	defer span.End()
+
+	var out []int
+
+	for _, conn := range r.cluster.Nodes() {
+		ids, err := conn.ZRangeByScore(spanCtx, keyPaused, &redis.ZRangeBy{
+			Min: "-inf",
+			Max: strconv.FormatInt(now.Unix(), 10),
+		}).Result()
+		if err != nil {
+			return nil, fmt.Errorf("could not get paused: %w", err)
+		}
+
+		for _, item := range ids {
+			bannerID, err := strconv.Atoi(item)
+			if err != nil {
+				return nil, fmt.Errorf("could not parse banner id: %w", err)
+			}
+
+			out = append(out, bannerID)
+		}
 	}
 
 	return out, nil
 }
 
-func (r *Redis) AddSpend(ctx context.Context, bannerID int, price float64) (float64, error) {
-	spanCtx, span := otel.Tracer(tracerName).Start(ctx, "AddSpend")
+// ResumeBanner reports true only once for the banner whose pause is over, so only one replica resumes it
+func (r *Redis) ResumeBanner(ctx context.Context, bannerID int, now time.Time) (bool, error) {
+	spanCtx, span := otel.Tracer(tracerName).Start(ctx, "ResumeBanner")
 	defer span.End()
 
 	conn := r.cluster.Node(bannerID)
 
-	res := conn.IncrByFloat(spanCtx, r.ketInteractions(bannerID, fieldShow), price)
-	err := res.Err()
+	resumed, err := scriptResume.Run(spanCtx, conn, []string{keyPaused}, bannerID, now.Unix()).Int()
 	if err != nil {
-		return 0, fmt.Errorf("could not incr shows: %w", err)
+		return false, fmt.Errorf("could not resume: %w", err)
 	}
 
-	out, err := res.Result()
+	return resumed == 1, nil
+}
+
+// SaveCampaign overwrites budgets of the campaign, its counters are kept on the node of the campaign
+func (r *Redis) SaveCampaign(ctx context.Context, campaign entity.Campaign) error {
+	spanCtx, span := otel.Tracer(tracerName).Start(ctx, "SaveCampaign")
+	defer span.End()
+
+	conn := r.cluster.Node(campaign.ID)
+
+	raw, err := json.Marshal(campaign)
 	if err != nil {
-		return 0, fmt.Errorf("could not get result: %w", err)
+		return fmt.Errorf("could not marshal: %w", err)
+	}
+
+	if err := conn.Set(spanCtx, r.keyCampaign(campaign.ID), raw, 0).Err(); err != nil {
+		return fmt.Errorf("could not store campaign: %w", err)
+	}
+
+	return nil
+}
+
+func (r *Redis) GetCampaign(ctx context.Context, campaignID int) (*entity.Campaign, error) {
+	spanCtx, span := otel.Tracer(tracerName).Start(ctx, "GetCampaign")
+	defer span.End()
+
+	conn := r.cluster.Node(campaignID)
+
+	out, err := conn.Get(spanCtx, r.keyCampaign(campaignID)).Result()
+	if err != nil {
+		return nil, fmt.Errorf("could not get campaign: %w", err)
+	}
+
+	var campaign entity.Campaign
+	if err := json.Unmarshal([]byte(out), &campaign); err != nil {
+		return nil, fmt.Errorf("could not unmarshal: %w", err)
+	}
+
+	return &campaign, nil
+}
+
+// JoinCampaign adds the banner to banners which are stopped once the campaign spends its budget
+func (r *Redis) JoinCampaign(ctx context.Context, campaignID int, bannerID int) error {
+	spanCtx, span := otel.Tracer(tracerName).Start(ctx, "JoinCampaign")
+	defer span.End()
+
+	conn := r.cluster.Node(campaignID)
+
+	if err := conn.SAdd(spanCtx, r.keyCampaignBanners(campaignID), bannerID).Err(); err != nil {
+		return fmt.Errorf("could not add banner to campaign: %w", err)
+	}
+
+	return nil
+}
+
+// LeaveCampaign removes the stopped banner from banners of its campaign, so it's not paused and resumed along with them
+func (r *Redis) LeaveCampaign(ctx context.Context, bannerID int) error {
+	spanCtx, span := otel.Tracer(tracerName).Start(ctx, "LeaveCampaign")
+	defer span.End()
+
+	out, err := r.cluster.Node(bannerID).Get(spanCtx, r.keyInfo(bannerID)).Result()
+	if err == redis.Nil {
+		// banner has never been started
+		return nil
+	}
+
+	if err != nil {
+		return fmt.Errorf("could not get info: %w", err)
+	}
+
+	var banner entity.Banner
+	if err := json.Unmarshal([]byte(out), &banner); err != nil {
+		return fmt.Errorf("could not unmarshal: %w", err)
+	}
+
+	if banner.CampaignID == 0 {
+		return nil
+	}
+
+	conn := r.cluster.Node(banner.CampaignID)
+
+	if err := conn.SRem(spanCtx, r.keyCampaignBanners(banner.CampaignID), bannerID).Err(); err != nil {
+		return fmt.Errorf("could not remove banner from campaign: %w", err)
+	}
+
+	return nil
+}
+
+func (r *Redis) CampaignBanners(ctx context.Context, campaignID int) ([]int, error) {
+	spanCtx, span := otel.Tracer(tracerName).Start(ctx, "CampaignBanners")
+	defer span.End()
+
+	conn := r.cluster.Node(campaignID)
+
+	ids, err := conn.SMembers(spanCtx, r.keyCampaignBanners(campaignID)).Result()
+	if err != nil {
+		return nil, fmt.Errorf("could not get campaign banners: %w", err)
+	}
+
+	out := make([]int, 0, len(ids))
+	for _, item := range ids {
+		bannerID, err := strconv.Atoi(item)
+		if err != nil {
+			return nil, fmt.Errorf("could not parse banner id: %w", err)
+		}
+
+		out = append(out, bannerID)
+	}
+
+	return out, nil
+}
+
+// AddCampaignSpend counts spend of a banner to its campaign and returns periods whose budget has been spent by this click,
+// each period is reported once like limits of banners are and each click is counted once
+func (r *Redis) AddCampaignSpend(ctx context.Context, campaign entity.Campaign, clickID string, price float64, now time.Time) ([]entity.Period, error) {
+	spanCtx, span := otel.Tracer(tracerName).Start(ctx, "AddCampaignSpend")
+	defer span.End()
+
+	periods := campaign.Periods()
+	if len(periods) == 0 {
+		return nil, nil
+	}
+
+	conn := r.cluster.Node(campaign.ID)
+
+	keys := []string{r.keyCampaign(campaign.ID)}
+
+	for _, period := range periods {
+		keys = append(keys,
+			r.keyPeriod(r.keyCampaignSpend(campaign.ID), period, now),
+			r.keyPeriod(r.keyCampaignReached(campaign.ID), period, now))
+	}
+
+	marks, unmarked := r.marks([]string{clickID}, func(eventID string) string {
+		return r.keyCampaignCounted(campaign.ID, eventID)
+	})
+
+	keys = append(keys, marks...)
+	args := []interface{}{price, true, int64(countedTTL.Seconds()), unmarked}
+
+	for _, period := range periods {
+		args = append(args, campaignLimitFields[period], int64(period.TTL().Seconds()))
+	}
+
+	crossed, err := scriptIncrAndCheck.Run(spanCtx, conn, keys, args...).Int64Slice()
+	if err != nil {
+		return nil, fmt.Errorf("could not incr campaign spend: %w", err)
+	}
+
+	var out []entity.Period
+	for i, period := range periods {
+		if i < len(crossed) && crossed[i] == 1 {
+			out = append(out, period)
+		}
 	}
 
 	return out, nil
 }
+
+// CampaignReached returns periods whose budget the campaign has spent, banners of the campaign are not served within them
+func (r *Redis) CampaignReached(ctx context.Context, campaignID int, now time.Time) ([]entity.Period, error) {
+	spanCtx, span := otel.Tracer(tracerName).Start(ctx, "CampaignReached")
+	defer span.End()
+
+	conn := r.cluster.Node(campaignID)
+
+	var out []entity.Period
+	for _, period := range []entity.Period{entity.PeriodLifetime, entity.PeriodDay} {
+		found, err := conn.Exists(spanCtx, r.keyPeriod(r.keyCampaignReached(campaignID), period, now)).Result()
+		if err != nil {
+			return nil, fmt.Errorf("could not check reached flag: %w", err)
+		}
+
+		if found > 0 {
+			out = append(out, period)
+		}
+	}
+
+	return out, nil
+}
+
+// ResetCampaignReached allows campaign to report spending its budget once again, e.g. after its budget is changed
+func (r *Redis) ResetCampaignReached(ctx context.Context, campaignID int, now time.Time) error {
+	spanCtx, span := otel.Tracer(tracerName).Start(ctx, "ResetCampaignReached")
+	defer span.End()
+
+	conn := r.cluster.Node(campaignID)
+
+	keys := []string{
+		r.keyPeriod(r.keyCampaignReached(campaignID), entity.PeriodLifetime, now),
+		r.keyPeriod(r.keyCampaignReached(campaignID), entity.PeriodDay, now),
+	}
+
+	if err := conn.Del(spanCtx, keys...).Err(); err != nil {
+		return fmt.Errorf("could not reset reached flags: %w", err)
+	}
+
+	return nil
+}
+
+// UnmarkCampaignReached removes reached flags of the periods, so spending the budget is reported again
+func (r *Redis) UnmarkCampaignReached(ctx context.Context, campaignID int, periods []entity.Period, now time.Time) error {
+	spanCtx, span := otel.Tracer(tracerName).Start(ctx, "UnmarkCampaignReached")
+	defer span.End()
+
+	if len(periods) == 0 {
+		return nil
+	}
+
+	conn := r.cluster.Node(campaignID)
+
+	var keys []string
+	for _, period := range periods {
+		keys = append(keys, r.keyPeriod(r.keyCampaignReached(campaignID), period, now))
+	}
+
+	if err := conn.Del(spanCtx, keys...).Err(); err != nil {
+		return fmt.Errorf("could not unmark reached flags: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,48 @@
+package redis
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/crxfoz/teaserad/adeliver/internal/domain/entity"
+	clusterredis "github.com/crxfoz/teaserad/adeliver/pkg/redis"
+	"github.com/go-redis/redis/v8"
+	"github.com/stretchr/testify/assert"
+)
+
+// newTestRedis connects to the local redis and cleans its test database
+func newTestRedis(t *testing.T) *Redis {
+	conn := redis.NewClient(&redis.Options{Addr: "127.0.0.1:6379", DB: 15})
+
+	if err := conn.Ping(context.Background()).Err(); err != nil {
+		t.Skipf("redis is not available: %v", err)
+	}
+
+	assert.NoError(t, conn.FlushDB(context.Background()).Err())
+
+	return New(clusterredis.New([]*redis.Client{conn}))
+}
+
+func TestRedis_AddSpend(t *testing.T) {
+	repo := newTestRedis(t)
+	ctx := context.Background()
+	now := time.Now()
+
+	// banner without lifetime budget is never stopped by its spend
+	assert.NoError(t, repo.AddBanner(ctx, entity.Banner{ID: 1}))
+
+	crossed, err := repo.AddSpend(ctx, 1, "a", 1, now)
+	assert.NoError(t, err)
+	assert.Empty(t, crossed)
+
+	assert.NoError(t, repo.AddBanner(ctx, entity.Banner{ID: 2, LimitBudget: 2}))
+
+	crossed, err = repo.AddSpend(ctx, 2, "a", 1, now)
+	assert.NoError(t, err)
+	assert.Empty(t, crossed)
+
+	crossed, err = repo.AddSpend(ctx, 2, "b", 1, now)
+	assert.NoError(t, err)
+	assert.Equal(t, []entity.Period{entity.PeriodLifetime}, crossed)
+}
@@ -0,0 +1,102 @@
+package redis
+
+import "github.com/go-redis/redis/v8"
+
+// scriptIncrAndCheck increments counters of every period and compares them against the limits stored in banner info.
+// For each period returns 1 only for the first call which crossed the limit, so the limit is reported exactly once.
+// Periods with ttl are caps and their counters expire. The limit equal to 0 means there is no limit within the period,
+// so the banner without lifetime budget is never stopped by its spend.
+// Each event is counted once, retried events are only checked against the limits,
+// so they report the limit again if its reached flag has been removed after a failure.
+//
+// KEYS[1] - banner info, then counter and reached flag of each period, then marks of the counted events
+// ARGV[1] - increment per event, ARGV[2] - "1" if reaching the limit counts as crossing, ARGV[3] - ttl of the marks,
+// ARGV[4] - number of events without id which are always counted, then limit field of banner info and ttl of each period
+var scriptIncrAndCheck = redis.NewScript(`
+local info = redis.call('GET', KEYS[1])
+if not info then
+	return redis.error_reply('banner not found')
+end
+
+info = cjson.decode(info)
+
+local periods = (#ARGV - 4) / 2
+
+local fresh = tonumber(ARGV[4])
+for i = 2 + 2 * periods, #KEYS do
+	if redis.call('SET', KEYS[i], 1, 'NX', 'EX', ARGV[3]) then
+		fresh = fresh + 1
+	end
+end
+
+local delta = ARGV[1]
+if fresh ~= 1 then
+	delta = tostring(fresh * tonumber(ARGV[1]))
+end
+
+local out = {}
+for i = 1, periods do
+	local counter, flag = KEYS[2 * i], KEYS[2 * i + 1]
+	local limit = tonumber(info[ARGV[3 + 2 * i]]) or 0
+	local ttl = tonumber(ARGV[4 + 2 * i])
+
+	local value
+	if fresh > 0 then
+		value = tonumber(redis.call('INCRBYFLOAT', counter, delta))
+		if ttl > 0 then
+			redis.call('EXPIRE', counter, ttl)
+		end
+	else
+		value = tonumber(redis.call('GET', counter)) or 0
+	end
+
+	local crossed = false
+	if limit > 0 then
+		if ARGV[2] == '1' then
+			crossed = value >= limit
+		else
+			crossed = value > limit
+		end
+	end
+
+	out[i] = 0
+	if crossed and redis.call('SETNX', flag, 1) == 1 then
+		if ttl > 0 then
+			redis.call('EXPIRE', flag, ttl)
+		end
+		out[i] = 1
+	end
+end
+
+return out
+`)
+
+// scriptPause schedules the banner to be resumed, pause can only be extended.
+// Returns 1 if the banner has been paused or its pause has been extended.
+//
+// KEYS[1] - schedule of paused banners
+// ARGV[1] - banner id, ARGV[2] - unix time the banner should be resumed at
+var scriptPause = redis.NewScript(`
+local current = tonumber(redis.call('ZSCORE', KEYS[1], ARGV[1]))
+if current and current >= tonumber(ARGV[2]) then
+	return 0
+end
+
+redis.call('ZADD', KEYS[1], ARGV[2], ARGV[1])
+return 1
+`)
+
+// scriptResume removes the banner from schedule if its pause is over.
+// Returns 1 only for the caller which actually resumed the banner.
+//
+// KEYS[1] - schedule of paused banners
+// ARGV[1] - banner id, ARGV[2] - current unix time
+var scriptResume = redis.NewScript(`
+local current = tonumber(redis.call('ZSCORE', KEYS[1], ARGV[1]))
+if not current or current > tonumber(ARGV[2]) then
+	return 0
+end
+
+redis.call('ZREM', KEYS[1], ARGV[1])
+return 1
+`)
@@ -3,6 +3,7 @@ package banner
 import (
 	"context"
 	"fmt"
+	"time"
 
 	"github.com/crxfoz/teaserad/adeliver/internal/domain/entity"
 	"github.com/crxfoz/teaserad/adeliver/internal/domain/events"
@@ -15,19 +16,46 @@ type BannerRepo interface {
 	GetClick(ctx context.Context, bannerID int) (int64, error)
 	GetShows(ctx context.Context, bannerID int) (int64, error)
 	GetSpend(ctx context.Context, bannerID int) (float64, error)
-	AddClick(ctx context.Context, bannerID int) (int64, error)
-	AddShow(ctx context.Context, bannerID int) (int64, error)
-	AddSpend(ctx context.Context, bannerID int, price float64) (float64, error)
+	// AddClick, AddShows and AddSpend atomically increment counters of every period and report
+	// the period only once - for the event which crossed the banner's limit within it.
+	// Each click and view is counted once, so retried events are safe to add again
+	AddClick(ctx context.Context, bannerID int, clickID string, now time.Time) ([]entity.Period, error)
+	AddShows(ctx context.Context, bannerID int, viewIDs []string, now time.Time) ([]entity.Period, error)
+	AddSpend(ctx context.Context, bannerID int, clickID string, price float64, now time.Time) ([]entity.Period, error)
+	UnmarkReached(ctx context.Context, bannerID int, counter entity.Counter, periods []entity.Period, now time.Time) error
+	ResetReached(ctx context.Context, bannerID int, now time.Time) error
+	Reached(ctx context.Context, bannerID int, now time.Time) (bool, error)
+	SetPacing(ctx context.Context, bannerID int, probability float64) (bool, error)
+	SaveStart(ctx context.Context, start events.BannerStart) error
+	GetStart(ctx context.Context, bannerID int) (*events.BannerStart, error)
+	PauseBanner(ctx context.Context, bannerID int, until time.Time) (bool, error)
+	UnpauseBanner(ctx context.Context, bannerID int) error
+	PausedDue(ctx context.Context, now time.Time) ([]int, error)
+	ResumeBanner(ctx context.Context, bannerID int, now time.Time) (bool, error)
+	// budgets of a campaign are shared by its banners, so they are checked against spend of all the banners
+	SaveCampaign(ctx context.Context, campaign entity.Campaign) error
+	GetCampaign(ctx context.Context, campaignID int) (*entity.Campaign, error)
+	JoinCampaign(ctx context.Context, campaignID int, bannerID int) error
+	LeaveCampaign(ctx context.Context, bannerID int) error
+	CampaignBanners(ctx context.Context, campaignID int) ([]int, error)
+	AddCampaignSpend(ctx context.Context, campaign entity.Campaign, clickID string, price float64, now time.Time) ([]entity.Period, error)
+	UnmarkCampaignReached(ctx context.Context, campaignID int, periods []entity.Period, now time.Time) error
+	CampaignReached(ctx context.Context, campaignID int, now time.Time) ([]entity.Period, error)
+	ResetCampaignReached(ctx context.Context, campaignID int, now time.Time) error
 }
 
 // BannerNotify signal other services that banner has been stopped because reached its limits
 type BannerNotify interface {
 	NotifyBannerStopped(context.Context, events.BannerReachedLimits) error
+	NotifyBannerPaused(context.Context, events.BannerPaused) error
+	NotifyBannerResumed(context.Context, events.BannerResumed) error
 }
 
 type BannerDispatcher interface {
 	StartBanner(ctx context.Context, started events.BannerStart) error
 	StopBanner(ctx context.Context, stopped events.BannerStop) error
+	ThrottleBanner(ctx context.Context, throttle events.BannerThrottle) error
+	UnthrottleBanner(ctx context.Context, unthrottle events.BannerUnthrottle) error
 }
 
 type BannerService struct {
@@ -48,6 +76,14 @@ func (b *BannerService) StopBanner(ctx context.Context, incoming events.BannerSt
 	spanCtx, span := otel.Tracer(tracerName).Start(ctx, "StopBanner")
 	defer span.End()
 
+	if err := b.repo.UnpauseBanner(spanCtx, incoming.BannerID); err != nil {
+		return fmt.Errorf("could not unpause banner: %w", err)
+	}
+
+	if err := b.repo.LeaveCampaign(spanCtx, incoming.BannerID); err != nil {
+		return fmt.Errorf("could not remove banner from campaign: %w", err)
+	}
+
 	if err := b.dispatcher.StopBanner(spanCtx, events.BannerStop{BannerID: incoming.BannerID}); err != nil {
 		return fmt.Errorf("could not stop banner on dispatcher: %w", err)
 	}
@@ -58,20 +94,51 @@ func (b *BannerService) StartBanner(ctx context.Context, incoming events.BannerS
 	spanCtx, span := otel.Tracer(tracerName).Start(ctx, "StartBanner")
 	defer span.End()
 
-	_, err := b.repo.GetBanner(spanCtx, incoming.BannerID)
+	// limits are always overwritten so restarted banner gets the latest ones
+	err := b.repo.AddBanner(spanCtx, entity.Banner{
+		ID:          incoming.BannerID,
+		CampaignID:  incoming.CampaignID,
+		LimitShows:  incoming.LimitShows,
+		LimitClicks: incoming.LimitClicks,
+		LimitBudget: incoming.LimitBudget,
+		StartAt:     incoming.StartAt,
+		EndAt:       incoming.EndAt,
+
+		DailyShows:   incoming.DailyShows,
+		DailyClicks:  incoming.DailyClicks,
+		DailyBudget:  incoming.DailyBudget,
+		HourlyShows:  incoming.HourlyShows,
+		HourlyClicks: incoming.HourlyClicks,
+		HourlyBudget: incoming.HourlyBudget,
+	})
 	if err != nil {
-		errCreate := b.repo.AddBanner(spanCtx, entity.Banner{
-			ID:          incoming.BannerID,
-			LimitShows:  incoming.LimitShows,
-			LimitClicks: incoming.LimitClicks,
-			LimitBudget: incoming.LimitBudget,
+		return fmt.Errorf("could not add banner: %w", err)
+	}
+
+	if err := b.repo.ResetReached(spanCtx, incoming.BannerID, time.Now()); err != nil {
+		return fmt.Errorf("could not reset reached limits: %w", err)
+	}
+
+	if err := b.repo.UnpauseBanner(spanCtx, incoming.BannerID); err != nil {
+		return fmt.Errorf("could not unpause banner: %w", err)
+	}
+
+	if incoming.CampaignID != 0 {
+		err := b.repo.SaveCampaign(spanCtx, entity.Campaign{
+			ID:          incoming.CampaignID,
+			LimitBudget: incoming.CampaignBudget,
+			DailyBudget: incoming.CampaignDailyBudget,
 		})
-		if errCreate != nil {
-			return fmt.Errorf("could not add new banner: %w", err)
+		if err != nil {
+			return fmt.Errorf("could not save campaign: %w", err)
+		}
+
+		if err := b.repo.JoinCampaign(spanCtx, incoming.CampaignID, incoming.BannerID); err != nil {
+			return fmt.Errorf("could not add banner to campaign: %w", err)
 		}
 	}
 
-	err = b.dispatcher.StartBanner(spanCtx, events.BannerStart{
+	start := events.BannerStart{
 		BannerID:    incoming.BannerID,
 		UserID:      incoming.UserID,
 		ImgData:     incoming.ImgData,
@@ -82,72 +149,541 @@ func (b *BannerService) StartBanner(ctx context.Context, incoming events.BannerS
 		LimitBudget: incoming.LimitBudget,
 		Device:      incoming.Device,
 		CategoryID:  incoming.CategoryID,
-	})
+		StartAt:     incoming.StartAt,
+		EndAt:       incoming.EndAt,
+		Schedule:    incoming.Schedule,
+		TimeZone:    incoming.TimeZone,
+		Geo:         incoming.Geo,
+
+		FrequencyCap:    incoming.FrequencyCap,
+		FrequencyPeriod: incoming.FrequencyPeriod,
+		BidType:         incoming.BidType,
+		Bid:             incoming.Bid,
+		UTM:             incoming.UTM,
+	}
+
+	// start event is kept to restart the banner when it's paused by its caps
+	if err := b.repo.SaveStart(spanCtx, start); err != nil {
+		return fmt.Errorf("could not save start: %w", err)
+	}
 
+	// banner of the campaign which has spent its budget waits along with the other banners of the campaign
+	limited, err := b.campaignLimited(spanCtx, incoming.BannerID, incoming.CampaignID, time.Now())
 	if err != nil {
+		return err
+	}
+
+	if limited {
+		return nil
+	}
+
+	if err := b.dispatcher.StartBanner(spanCtx, start); err != nil {
 		return fmt.Errorf("could not start banner on dispatcher: %w", err)
 	}
 
 	return nil
 }
 
-func (b *BannerService) NewClick(ctx context.Context, incoming events.Click) error {
-	spanCtx, span := otel.Tracer(tracerName).Start(ctx, "NewClick")
+// UpdateLimits replaces limits of the live banner so they take effect with the next click or view,
+// banner paused by its caps keeps waiting for the next period
+func (b *BannerService) UpdateLimits(ctx context.Context, incoming events.BannerLimitsIncoming) error {
+	spanCtx, span := otel.Tracer(tracerName).Start(ctx, "UpdateLimits")
 	defer span.End()
 
-	limits, err := b.repo.GetBanner(spanCtx, incoming.BannerID)
+	banner, err := b.repo.GetBanner(spanCtx, incoming.BannerID)
 	if err != nil {
 		return fmt.Errorf("could not get banner: %w", err)
 	}
 
-	clicks, err := b.repo.AddClick(spanCtx, incoming.BannerID)
+	banner.LimitShows = incoming.LimitShows
+	banner.LimitClicks = incoming.LimitClicks
+	banner.LimitBudget = incoming.LimitBudget
+	banner.DailyShows = incoming.DailyShows
+	banner.DailyClicks = incoming.DailyClicks
+	banner.DailyBudget = incoming.DailyBudget
+	banner.HourlyShows = incoming.HourlyShows
+	banner.HourlyClicks = incoming.HourlyClicks
+	banner.HourlyBudget = incoming.HourlyBudget
+
+	if err := b.repo.AddBanner(spanCtx, *banner); err != nil {
+		return fmt.Errorf("could not update banner: %w", err)
+	}
+
+	if err := b.repo.ResetReached(spanCtx, incoming.BannerID, time.Now()); err != nil {
+		return fmt.Errorf("could not reset reached limits: %w", err)
+	}
+
+	// start event is what the banner is resumed with after pause
+	start, err := b.repo.GetStart(spanCtx, incoming.BannerID)
 	if err != nil {
-		return fmt.Errorf("could not add click: %w", err)
+		return fmt.Errorf("could not get start: %w", err)
+	}
+
+	start.LimitShows = incoming.LimitShows
+	start.LimitClicks = incoming.LimitClicks
+	start.LimitBudget = incoming.LimitBudget
+
+	if err := b.repo.SaveStart(spanCtx, *start); err != nil {
+		return fmt.Errorf("could not save start: %w", err)
+	}
+
+	return b.pace(spanCtx, incoming.BannerID)
+}
+
+// UpdateCreative replaces the creative the banner is served with, unlike restart it keeps caps reached by the banner.
+// Banner which is paused now gets the creative when it's resumed
+func (b *BannerService) UpdateCreative(ctx context.Context, incoming events.BannerCreativeIncoming) error {
+	spanCtx, span := otel.Tracer(tracerName).Start(ctx, "UpdateCreative")
+	defer span.End()
+
+	start, err := b.repo.GetStart(spanCtx, incoming.BannerID)
+	if err != nil {
+		return fmt.Errorf("could not get start: %w", err)
 	}
 
-	if clicks > limits.LimitClicks {
-		err = b.notify.NotifyBannerStopped(spanCtx, events.BannerReachedLimits{
-			BannerID: incoming.BannerID,
-			Reason:   "clicks"})
+	start.ImgData = incoming.ImgData
+	start.BannerText = incoming.BannerText
+	start.BannerURL = incoming.BannerURL
+
+	if err := b.repo.SaveStart(spanCtx, *start); err != nil {
+		return fmt.Errorf("could not save start: %w", err)
+	}
+
+	reached, err := b.repo.Reached(spanCtx, incoming.BannerID, time.Now())
+	if err != nil {
+		return fmt.Errorf("could not check reached limits: %w", err)
+	}
+
+	if reached {
+		return nil
+	}
+
+	banner, err := b.repo.GetBanner(spanCtx, incoming.BannerID)
+	if err != nil {
+		return fmt.Errorf("could not get banner: %w", err)
+	}
+
+	if banner.CampaignID != 0 {
+		periods, err := b.repo.CampaignReached(spanCtx, banner.CampaignID, time.Now())
 		if err != nil {
-			return fmt.Errorf("could not notify banner to stop: %w", err)
+			return fmt.Errorf("could not check reached campaign budget: %w", err)
 		}
 
-		err = b.dispatcher.StopBanner(spanCtx, events.BannerStop{BannerID: incoming.BannerID})
-		if err != nil {
-			return fmt.Errorf("could not stop banner on dispatcher: %w", err)
+		if len(periods) > 0 {
+			return nil
 		}
 	}
 
+	// serving banners are upserted by the start, so it only replaces the creative
+	if err := b.dispatcher.StartBanner(spanCtx, *start); err != nil {
+		return fmt.Errorf("could not update banner on dispatcher: %w", err)
+	}
+
 	return nil
 }
 
-func (b *BannerService) NewView(ctx context.Context, incoming events.View) error {
-	spanCtx, span := otel.Tracer(tracerName).Start(ctx, "NewView")
+// UpdateCampaign replaces budgets of the campaign so they take effect with the next click,
+// banners paused by the campaign's budget keep waiting for the next period
+func (b *BannerService) UpdateCampaign(ctx context.Context, incoming events.CampaignLimitsIncoming) error {
+	spanCtx, span := otel.Tracer(tracerName).Start(ctx, "UpdateCampaign")
 	defer span.End()
 
-	limits, err := b.repo.GetBanner(spanCtx, incoming.BannerID)
+	err := b.repo.SaveCampaign(spanCtx, entity.Campaign{
+		ID:          incoming.CampaignID,
+		LimitBudget: incoming.TotalBudget,
+		DailyBudget: incoming.DailyBudget,
+	})
 	if err != nil {
-		return fmt.Errorf("could not get banner: %w", err)
+		return fmt.Errorf("could not save campaign: %w", err)
 	}
 
-	views, err := b.repo.AddShow(spanCtx, incoming.BannerID)
+	if err := b.repo.ResetCampaignReached(spanCtx, incoming.CampaignID, time.Now()); err != nil {
+		return fmt.Errorf("could not reset reached budget: %w", err)
+	}
+
+	return nil
+}
+
+const (
+	reasonClicks         = "clicks"
+	reasonViews          = "views"
+	reasonBudget         = "budget"
+	reasonCampaignBudget = "campaign_budget"
+)
+
+func (b *BannerService) NewClick(ctx context.Context, incoming events.Click) error {
+	spanCtx, span := otel.Tracer(tracerName).Start(ctx, "NewClick")
+	defer span.End()
+
+	now := time.Now()
+
+	clicksCrossed, err := b.repo.AddClick(spanCtx, incoming.BannerID, incoming.ClickID, now)
 	if err != nil {
 		return fmt.Errorf("could not add click: %w", err)
 	}
 
-	if views > limits.LimitShows {
-		err = b.notify.NotifyBannerStopped(spanCtx, events.BannerReachedLimits{
-			BannerID: incoming.BannerID,
-			Reason:   "views"})
-		if err != nil {
-			return fmt.Errorf("could not notify banner to stop: %w", err)
+	budgetCrossed, err := b.repo.AddSpend(spanCtx, incoming.BannerID, incoming.ClickID, incoming.Price, now)
+	if err != nil {
+		return fmt.Errorf("could not add spend: %w", err)
+	}
+
+	limited, err := b.crossedLimits(spanCtx, incoming.BannerID, now,
+		crossing{reason: reasonClicks, counter: entity.CounterClicks, periods: clicksCrossed},
+		crossing{reason: reasonBudget, counter: entity.CounterSpend, periods: budgetCrossed})
+	if err != nil {
+		return err
+	}
+
+	campaignLimited, err := b.addCampaignSpend(spanCtx, incoming.BannerID, incoming.ClickID, incoming.Price, now)
+	if err != nil {
+		return err
+	}
+
+	if limited || campaignLimited {
+		return nil
+	}
+
+	return b.pace(spanCtx, incoming.BannerID)
+}
+
+// NewViews counts views of each banner at once, so limits and pacing are checked once per banner.
+// Failure of a banner doesn't stop the others, *entity.BannersError tells which ones to retry.
+// A banner fails if its views could not be counted or their limits could not be handled, retried views
+// are not counted again but the limits they have crossed are reported again
+func (b *BannerService) NewViews(ctx context.Context, incoming []events.View) error {
+	spanCtx, span := otel.Tracer(tracerName).Start(ctx, "NewViews")
+	defer span.End()
+
+	now := time.Now()
+
+	var bannerIDs []int
+
+	viewIDs := make(map[int][]string)
+	for _, view := range incoming {
+		if _, ok := viewIDs[view.BannerID]; !ok {
+			bannerIDs = append(bannerIDs, view.BannerID)
 		}
 
-		err = b.dispatcher.StopBanner(spanCtx, events.BannerStop{BannerID: incoming.BannerID})
+		viewIDs[view.BannerID] = append(viewIDs[view.BannerID], view.ViewID)
+	}
+
+	var failed entity.BannersError
+
+	for _, bannerID := range bannerIDs {
+		if err := b.addViews(spanCtx, bannerID, viewIDs[bannerID], now); err != nil {
+			failed.Add(bannerID, fmt.Errorf("could not add views: %w", err))
+		}
+	}
+
+	return failed.ErrOrNil()
+}
+
+func (b *BannerService) addViews(ctx context.Context, bannerID int, viewIDs []string, now time.Time) error {
+	viewsCrossed, err := b.repo.AddShows(ctx, bannerID, viewIDs, now)
+	if err != nil {
+		return fmt.Errorf("could not add view: %w", err)
+	}
+
+	limited, err := b.crossedLimits(ctx, bannerID, now,
+		crossing{reason: reasonViews, counter: entity.CounterShows, periods: viewsCrossed})
+	if err != nil {
+		return err
+	}
+
+	if limited {
+		return nil
+	}
+
+	return b.pace(ctx, bannerID)
+}
+
+// ResumePaused starts again banners whose caps have been reset by the beginning of a new period
+func (b *BannerService) ResumePaused(ctx context.Context) error {
+	spanCtx, span := otel.Tracer(tracerName).Start(ctx, "ResumePaused")
+	defer span.End()
+
+	now := time.Now()
+
+	bannerIDs, err := b.repo.PausedDue(spanCtx, now)
+	if err != nil {
+		return fmt.Errorf("could not get paused banners: %w", err)
+	}
+
+	for _, bannerID := range bannerIDs {
+		resumed, err := b.repo.ResumeBanner(spanCtx, bannerID, now)
 		if err != nil {
-			return fmt.Errorf("could not stop banner on dispatcher: %w", err)
+			return fmt.Errorf("could not resume banner %d: %w", bannerID, err)
 		}
+
+		// banner has been resumed by another replica
+		if !resumed {
+			continue
+		}
+
+		if err := b.resume(spanCtx, bannerID); err != nil {
+			// schedule the banner again so the next run retries
+			if _, errPause := b.repo.PauseBanner(spanCtx, bannerID, now); errPause != nil {
+				return fmt.Errorf("could not reschedule banner %d: %w, original reason: %s", bannerID, errPause, err.Error())
+			}
+
+			return fmt.Errorf("could not resume banner %d: %w", bannerID, err)
+		}
+	}
+
+	return nil
+}
+
+func (b *BannerService) resume(ctx context.Context, bannerID int) error {
+	start, err := b.repo.GetStart(ctx, bannerID)
+	if err != nil {
+		return fmt.Errorf("could not get start: %w", err)
+	}
+
+	if err := b.dispatcher.StartBanner(ctx, *start); err != nil {
+		return fmt.Errorf("could not start banner on dispatcher: %w", err)
+	}
+
+	if err := b.notify.NotifyBannerResumed(ctx, events.BannerResumed{BannerID: bannerID}); err != nil {
+		return fmt.Errorf("could not notify banner resumed: %w", err)
+	}
+
+	return nil
+}
+
+// addCampaignSpend counts the price of the click to the campaign of the banner and stops or pauses
+// all banners of the campaign once it spends its budget. Reports whether the banners are not shown anymore
+func (b *BannerService) addCampaignSpend(ctx context.Context, bannerID int, clickID string, price float64, now time.Time) (bool, error) {
+	banner, err := b.repo.GetBanner(ctx, bannerID)
+	if err != nil {
+		return false, fmt.Errorf("could not get banner: %w", err)
+	}
+
+	if banner.CampaignID == 0 || price <= 0 {
+		return false, nil
+	}
+
+	campaign, err := b.repo.GetCampaign(ctx, banner.CampaignID)
+	if err != nil {
+		return false, fmt.Errorf("could not get campaign: %w", err)
+	}
+
+	crossed, err := b.repo.AddCampaignSpend(ctx, *campaign, clickID, price, now)
+	if err != nil {
+		return false, fmt.Errorf("could not add campaign spend: %w", err)
+	}
+
+	if len(crossed) == 0 {
+		return false, nil
+	}
+
+	bannerIDs, err := b.repo.CampaignBanners(ctx, campaign.ID)
+	if err != nil {
+		return false, fmt.Errorf("could not get campaign banners: %w", err)
+	}
+
+	for _, item := range bannerIDs {
+		if _, err := b.crossedLimits(ctx, item, now, crossing{reason: reasonCampaignBudget, periods: crossed}); err != nil {
+			// the budget is reported again to the retry of the click, so the rest of the banners are stopped by it
+			if errUnmark := b.repo.UnmarkCampaignReached(ctx, campaign.ID, crossed, now); errUnmark != nil {
+				return false, fmt.Errorf("could not unmark reached campaign budget: %w, original reason: %s", errUnmark, err.Error())
+			}
+
+			return false, fmt.Errorf("could not stop banner %d of campaign %d: %w", item, campaign.ID, err)
+		}
+	}
+
+	return true, nil
+}
+
+// campaignLimited stops or pauses the banner if its campaign has spent the budget already.
+// Reports whether the banner is not shown
+func (b *BannerService) campaignLimited(ctx context.Context, bannerID int, campaignID int, now time.Time) (bool, error) {
+	if campaignID == 0 {
+		return false, nil
+	}
+
+	periods, err := b.repo.CampaignReached(ctx, campaignID, now)
+	if err != nil {
+		return false, fmt.Errorf("could not check reached campaign budget: %w", err)
+	}
+
+	return b.crossedLimits(ctx, bannerID, now, crossing{reason: reasonCampaignBudget, periods: periods})
+}
+
+// crossing is a kind of limit with periods whose limits have been crossed by the event,
+// counter is empty for limits which are not the banner's own
+type crossing struct {
+	reason  string
+	counter entity.Counter
+	periods []entity.Period
+}
+
+// crossedLimits stops the banner for good if it crossed any of its lifetime limits, otherwise pauses it until
+// the end of the longest period whose cap has been crossed. Reports whether the banner is not shown anymore.
+// Limits are reported once, so if the banner could not be stopped or paused they are unmarked
+// to be reported again to the retry of the event
+func (b *BannerService) crossedLimits(ctx context.Context, bannerID int, now time.Time, crossings ...crossing) (bool, error) {
+	limited, err := b.applyCrossings(ctx, bannerID, now, crossings)
+	if err == nil {
+		return limited, nil
+	}
+
+	for _, item := range crossings {
+		if item.counter == "" {
+			continue
+		}
+
+		if errUnmark := b.repo.UnmarkReached(ctx, bannerID, item.counter, item.periods, now); errUnmark != nil {
+			return false, fmt.Errorf("could not unmark reached limits: %w, original reason: %s", errUnmark, err.Error())
+		}
+	}
+
+	return false, err
+}
+
+func (b *BannerService) applyCrossings(ctx context.Context, bannerID int, now time.Time, crossings []crossing) (bool, error) {
+	var (
+		stopped     bool
+		pauseReason string
+		pauseUntil  time.Time
+	)
+
+	for _, item := range crossings {
+		for _, period := range item.periods {
+			if period == entity.PeriodLifetime {
+				if err := b.reachedLimits(ctx, bannerID, item.reason); err != nil {
+					return false, err
+				}
+
+				stopped = true
+				continue
+			}
+
+			if until := period.End(now); until.After(pauseUntil) {
+				pauseReason = fmt.Sprintf("%s_%s", period, item.reason)
+				pauseUntil = until
+			}
+		}
+	}
+
+	if stopped {
+		return true, nil
+	}
+
+	if pauseUntil.IsZero() {
+		return false, nil
+	}
+
+	return true, b.pause(ctx, bannerID, pauseReason, pauseUntil)
+}
+
+// pause removes the banner from showing until the next period begins
+func (b *BannerService) pause(ctx context.Context, bannerID int, reason string, until time.Time) error {
+	paused, err := b.repo.PauseBanner(ctx, bannerID, until)
+	if err != nil {
+		return fmt.Errorf("could not pause banner: %w", err)
+	}
+
+	if !paused {
+		return nil
+	}
+
+	if err := b.stopPaused(ctx, bannerID, reason, until); err != nil {
+		// the pause is set again by the retry, so the banner is stopped and the pause is notified by it
+		if errUnpause := b.repo.UnpauseBanner(ctx, bannerID); errUnpause != nil {
+			return fmt.Errorf("could not unpause banner: %w, original reason: %s", errUnpause, err.Error())
+		}
+
+		return err
+	}
+
+	return nil
+}
+
+func (b *BannerService) stopPaused(ctx context.Context, bannerID int, reason string, until time.Time) error {
+	err := b.dispatcher.StopBanner(ctx, events.BannerStop{BannerID: bannerID})
+	if err != nil {
+		return fmt.Errorf("could not stop banner on dispatcher: %w", err)
+	}
+
+	err = b.notify.NotifyBannerPaused(ctx, events.BannerPaused{
+		BannerID: bannerID,
+		Reason:   reason,
+		Until:    until.Unix()})
+	if err != nil {
+		return fmt.Errorf("could not notify banner paused: %w", err)
+	}
+
+	return nil
+}
+
+// pace throttles the banner if it's delivered faster than its flight window allows and
+// unthrottles it when delivery gets back to schedule
+func (b *BannerService) pace(ctx context.Context, bannerID int) error {
+	banner, err := b.repo.GetBanner(ctx, bannerID)
+	if err != nil {
+		return fmt.Errorf("could not get banner: %w", err)
+	}
+
+	if !banner.HasFlight() {
+		return nil
+	}
+
+	shows, err := b.repo.GetShows(ctx, bannerID)
+	if err != nil {
+		return fmt.Errorf("could not get shows: %w", err)
+	}
+
+	spend, err := b.repo.GetSpend(ctx, bannerID)
+	if err != nil {
+		return fmt.Errorf("could not get spend: %w", err)
+	}
+
+	probability := banner.Pacing(time.Now(), shows, spend)
+
+	changed, err := b.repo.SetPacing(ctx, bannerID, probability)
+	if err != nil {
+		return fmt.Errorf("could not store pacing: %w", err)
+	}
+
+	if !changed {
+		return nil
+	}
+
+	if probability < 1 {
+		err = b.dispatcher.ThrottleBanner(ctx, events.BannerThrottle{BannerID: bannerID, Probability: probability})
+	} else {
+		err = b.dispatcher.UnthrottleBanner(ctx, events.BannerUnthrottle{BannerID: bannerID})
+	}
+
+	if err != nil {
+		return fmt.Errorf("could not change pacing on dispatcher: %w", err)
+	}
+
+	return nil
+}
+
+// reachedLimits notifies other services that the banner has been stopped and removes it from showing
+func (b *BannerService) reachedLimits(ctx context.Context, bannerID int, reason string) error {
+	// banner which has been paused by its caps must not be resumed anymore
+	if err := b.repo.UnpauseBanner(ctx, bannerID); err != nil {
+		return fmt.Errorf("could not unpause banner: %w", err)
+	}
+
+	if err := b.repo.LeaveCampaign(ctx, bannerID); err != nil {
+		return fmt.Errorf("could not remove banner from campaign: %w", err)
+	}
+
+	err := b.notify.NotifyBannerStopped(ctx, events.BannerReachedLimits{
+		BannerID: bannerID,
+		Reason:   reason})
+	if err != nil {
+		return fmt.Errorf("could not notify banner to stop: %w", err)
+	}
+
+	err = b.dispatcher.StopBanner(ctx, events.BannerStop{BannerID: bannerID})
+	if err != nil {
+		return fmt.Errorf("could not stop banner on dispatcher: %w", err)
 	}
 
 	return nil
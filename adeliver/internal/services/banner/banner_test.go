@@ -0,0 +1,79 @@
+package banner
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/crxfoz/teaserad/adeliver/internal/domain/entity"
+	"github.com/crxfoz/teaserad/adeliver/internal/domain/events"
+	"github.com/stretchr/testify/assert"
+)
+
+func startBanner(t *testing.T, incoming events.BannerStartedIncoming) (*BannerService, *memoryRepo, *memoryNotify, *memoryDispatcher) {
+	repo := newMemoryRepo()
+	notify := &memoryNotify{}
+	dispatcher := &memoryDispatcher{serving: map[int]bool{}}
+	service := New(repo, notify, dispatcher)
+
+	assert.NoError(t, service.StartBanner(context.Background(), incoming))
+
+	return service, repo, notify, dispatcher
+}
+
+func TestBannerService_NewClickRetriesStop(t *testing.T) {
+	service, repo, notify, dispatcher := startBanner(t, events.BannerStartedIncoming{BannerID: 1, LimitClicks: 1})
+	ctx := context.Background()
+
+	assert.NoError(t, service.NewClick(ctx, events.Click{ClickID: "a", BannerID: 1}))
+
+	notify.failures = 1
+	assert.Error(t, service.NewClick(ctx, events.Click{ClickID: "b", BannerID: 1}))
+	assert.Empty(t, notify.stopped)
+	assert.True(t, dispatcher.serving[1])
+
+	// retry of the click is not counted again, but it reports the crossed limit once more
+	assert.NoError(t, service.NewClick(ctx, events.Click{ClickID: "b", BannerID: 1}))
+	assert.Equal(t, int64(2), repo.clicks[1])
+	assert.Equal(t, []events.BannerReachedLimits{{BannerID: 1, Reason: reasonClicks}}, notify.stopped)
+	assert.False(t, dispatcher.serving[1])
+}
+
+func TestBannerService_NewClickRetriesPause(t *testing.T) {
+	service, repo, notify, dispatcher := startBanner(t, events.BannerStartedIncoming{BannerID: 1, HourlyClicks: 1})
+	ctx := context.Background()
+
+	assert.NoError(t, service.NewClick(ctx, events.Click{ClickID: "a", BannerID: 1}))
+
+	notify.failures = 1
+	assert.Error(t, service.NewClick(ctx, events.Click{ClickID: "b", BannerID: 1}))
+	assert.Empty(t, repo.paused)
+
+	assert.NoError(t, service.NewClick(ctx, events.Click{ClickID: "b", BannerID: 1}))
+	assert.Equal(t, int64(2), repo.clicks[1])
+	assert.Len(t, notify.paused, 1)
+	assert.Equal(t, "hour_clicks", notify.paused[0].Reason)
+	assert.Len(t, repo.paused, 1)
+	assert.False(t, dispatcher.serving[1])
+}
+
+func TestBannerService_NewViewsRetried(t *testing.T) {
+	service, repo, notify, dispatcher := startBanner(t, events.BannerStartedIncoming{BannerID: 1, LimitShows: 2})
+	ctx := context.Background()
+
+	views := []events.View{{ViewID: "a", BannerID: 1}, {ViewID: "b", BannerID: 1}, {ViewID: "c", BannerID: 1}}
+
+	notify.failures = 1
+	err := service.NewViews(ctx, views)
+	assert.Error(t, err)
+
+	var bannersErr *entity.BannersError
+	assert.True(t, errors.As(err, &bannersErr))
+	assert.Contains(t, bannersErr.Errs, 1)
+
+	// views have been counted already, so the retry only stops the banner
+	assert.NoError(t, service.NewViews(ctx, views))
+	assert.Equal(t, int64(3), repo.shows[1])
+	assert.Equal(t, []events.BannerReachedLimits{{BannerID: 1, Reason: reasonViews}}, notify.stopped)
+	assert.False(t, dispatcher.serving[1])
+}
@@ -0,0 +1,324 @@
+package banner
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/crxfoz/teaserad/adeliver/internal/domain/entity"
+	"github.com/crxfoz/teaserad/adeliver/internal/domain/events"
+	"github.com/stretchr/testify/assert"
+)
+
+// memoryRepo counts clicks of banners against their lifetime and hourly limits, shows against lifetime ones
+// and spend of campaigns, each click and view is counted once
+type memoryRepo struct {
+	BannerRepo
+	banners       map[int]*entity.Banner
+	clicks        map[int]int64
+	shows         map[int]int64
+	bannerReached map[int]map[entity.Period]bool
+	campaigns     map[int]*entity.Campaign
+	members       map[int]map[int]bool
+	spend         map[int]float64
+	reached       map[int]map[entity.Period]bool
+	paused        map[int]time.Time
+	counted       map[string]bool
+}
+
+func newMemoryRepo() *memoryRepo {
+	return &memoryRepo{
+		banners:       map[int]*entity.Banner{},
+		clicks:        map[int]int64{},
+		shows:         map[int]int64{},
+		bannerReached: map[int]map[entity.Period]bool{},
+		campaigns:     map[int]*entity.Campaign{},
+		members:       map[int]map[int]bool{},
+		spend:         map[int]float64{},
+		reached:       map[int]map[entity.Period]bool{},
+		paused:        map[int]time.Time{},
+		counted:       map[string]bool{},
+	}
+}
+
+// count reports whether the event has not been counted yet
+func (m *memoryRepo) count(kind string, eventID string) bool {
+	if eventID == "" {
+		return true
+	}
+
+	key := kind + "." + eventID
+	if m.counted[key] {
+		return false
+	}
+
+	m.counted[key] = true
+
+	return true
+}
+
+func (m *memoryRepo) AddBanner(_ context.Context, banner entity.Banner) error {
+	m.banners[banner.ID] = &banner
+	return nil
+}
+
+func (m *memoryRepo) GetBanner(_ context.Context, bannerID int) (*entity.Banner, error) {
+	banner := *m.banners[bannerID]
+	return &banner, nil
+}
+
+func (m *memoryRepo) AddClick(_ context.Context, bannerID int, clickID string, _ time.Time) ([]entity.Period, error) {
+	if m.count(fmt.Sprintf("click.%d", bannerID), clickID) {
+		m.clicks[bannerID]++
+	}
+
+	if m.bannerReached[bannerID] == nil {
+		m.bannerReached[bannerID] = map[entity.Period]bool{}
+	}
+
+	banner := m.banners[bannerID]
+
+	var out []entity.Period
+	for period, limit := range map[entity.Period]int64{entity.PeriodLifetime: banner.LimitClicks, entity.PeriodHour: banner.HourlyClicks} {
+		if limit > 0 && m.clicks[bannerID] > limit && !m.bannerReached[bannerID][period] {
+			m.bannerReached[bannerID][period] = true
+			out = append(out, period)
+		}
+	}
+
+	return out, nil
+}
+
+func (m *memoryRepo) AddShows(_ context.Context, bannerID int, viewIDs []string, _ time.Time) ([]entity.Period, error) {
+	for _, viewID := range viewIDs {
+		if m.count(fmt.Sprintf("show.%d", bannerID), viewID) {
+			m.shows[bannerID]++
+		}
+	}
+
+	if m.bannerReached[bannerID] == nil {
+		m.bannerReached[bannerID] = map[entity.Period]bool{}
+	}
+
+	banner := m.banners[bannerID]
+
+	var out []entity.Period
+	if banner.LimitShows > 0 && m.shows[bannerID] > banner.LimitShows && !m.bannerReached[bannerID][entity.PeriodLifetime] {
+		m.bannerReached[bannerID][entity.PeriodLifetime] = true
+		out = append(out, entity.PeriodLifetime)
+	}
+
+	return out, nil
+}
+
+func (m *memoryRepo) AddSpend(context.Context, int, string, float64, time.Time) ([]entity.Period, error) {
+	return nil, nil
+}
+
+func (m *memoryRepo) UnmarkReached(_ context.Context, bannerID int, _ entity.Counter, periods []entity.Period, _ time.Time) error {
+	for _, period := range periods {
+		delete(m.bannerReached[bannerID], period)
+	}
+
+	return nil
+}
+
+func (m *memoryRepo) ResetReached(context.Context, int, time.Time) error { return nil }
+
+func (m *memoryRepo) SaveStart(context.Context, events.BannerStart) error { return nil }
+
+func (m *memoryRepo) PauseBanner(_ context.Context, bannerID int, until time.Time) (bool, error) {
+	if m.paused[bannerID].Equal(until) {
+		return false, nil
+	}
+
+	m.paused[bannerID] = until
+
+	return true, nil
+}
+
+func (m *memoryRepo) UnpauseBanner(_ context.Context, bannerID int) error {
+	delete(m.paused, bannerID)
+	return nil
+}
+
+func (m *memoryRepo) SaveCampaign(_ context.Context, campaign entity.Campaign) error {
+	m.campaigns[campaign.ID] = &campaign
+	return nil
+}
+
+func (m *memoryRepo) GetCampaign(_ context.Context, campaignID int) (*entity.Campaign, error) {
+	campaign := *m.campaigns[campaignID]
+	return &campaign, nil
+}
+
+func (m *memoryRepo) JoinCampaign(_ context.Context, campaignID int, bannerID int) error {
+	if m.members[campaignID] == nil {
+		m.members[campaignID] = map[int]bool{}
+	}
+
+	m.members[campaignID][bannerID] = true
+
+	return nil
+}
+
+func (m *memoryRepo) LeaveCampaign(_ context.Context, bannerID int) error {
+	delete(m.members[m.banners[bannerID].CampaignID], bannerID)
+	return nil
+}
+
+func (m *memoryRepo) CampaignBanners(_ context.Context, campaignID int) ([]int, error) {
+	var out []int
+	for bannerID := range m.members[campaignID] {
+		out = append(out, bannerID)
+	}
+
+	return out, nil
+}
+
+func (m *memoryRepo) AddCampaignSpend(_ context.Context, campaign entity.Campaign, clickID string, price float64, _ time.Time) ([]entity.Period, error) {
+	if m.count(fmt.Sprintf("campaign.%d", campaign.ID), clickID) {
+		m.spend[campaign.ID] += price
+	}
+
+	if m.reached[campaign.ID] == nil {
+		m.reached[campaign.ID] = map[entity.Period]bool{}
+	}
+
+	var out []entity.Period
+	for period, limit := range map[entity.Period]float64{entity.PeriodLifetime: campaign.LimitBudget, entity.PeriodDay: campaign.DailyBudget} {
+		if limit > 0 && m.spend[campaign.ID] >= limit && !m.reached[campaign.ID][period] {
+			m.reached[campaign.ID][period] = true
+			out = append(out, period)
+		}
+	}
+
+	return out, nil
+}
+
+func (m *memoryRepo) UnmarkCampaignReached(_ context.Context, campaignID int, periods []entity.Period, _ time.Time) error {
+	for _, period := range periods {
+		delete(m.reached[campaignID], period)
+	}
+
+	return nil
+}
+
+func (m *memoryRepo) CampaignReached(_ context.Context, campaignID int, _ time.Time) ([]entity.Period, error) {
+	var out []entity.Period
+	for _, period := range []entity.Period{entity.PeriodLifetime, entity.PeriodDay} {
+		if m.reached[campaignID][period] {
+			out = append(out, period)
+		}
+	}
+
+	return out, nil
+}
+
+// memoryNotify fails the given number of notifications before it starts to deliver them
+type memoryNotify struct {
+	BannerNotify
+	failures int
+	stopped  []events.BannerReachedLimits
+	paused   []events.BannerPaused
+}
+
+func (m *memoryNotify) fail() error {
+	if m.failures == 0 {
+		return nil
+	}
+
+	m.failures--
+
+	return errors.New("broker is unavailable")
+}
+
+func (m *memoryNotify) NotifyBannerStopped(_ context.Context, event events.BannerReachedLimits) error {
+	if err := m.fail(); err != nil {
+		return err
+	}
+
+	m.stopped = append(m.stopped, event)
+	return nil
+}
+
+func (m *memoryNotify) NotifyBannerPaused(_ context.Context, event events.BannerPaused) error {
+	if err := m.fail(); err != nil {
+		return err
+	}
+
+	m.paused = append(m.paused, event)
+	return nil
+}
+
+type memoryDispatcher struct {
+	BannerDispatcher
+	serving map[int]bool
+}
+
+func (m *memoryDispatcher) StartBanner(_ context.Context, started events.BannerStart) error {
+	m.serving[started.BannerID] = true
+	return nil
+}
+
+func (m *memoryDispatcher) StopBanner(_ context.Context, stopped events.BannerStop) error {
+	delete(m.serving, stopped.BannerID)
+	return nil
+}
+
+func startCampaignBanners(t *testing.T, totalBudget float64, dailyBudget float64) (*BannerService, *memoryRepo, *memoryNotify, *memoryDispatcher) {
+	repo := newMemoryRepo()
+	notify := &memoryNotify{}
+	dispatcher := &memoryDispatcher{serving: map[int]bool{}}
+	service := New(repo, notify, dispatcher)
+
+	for _, bannerID := range []int{1, 2} {
+		err := service.StartBanner(context.Background(), events.BannerStartedIncoming{
+			BannerID:            bannerID,
+			CampaignID:          3,
+			LimitShows:          1000,
+			LimitClicks:         1000,
+			LimitBudget:         totalBudget,
+			CampaignBudget:      totalBudget,
+			CampaignDailyBudget: dailyBudget,
+		})
+		assert.NoError(t, err)
+	}
+
+	return service, repo, notify, dispatcher
+}
+
+func TestBannerService_CampaignBudget(t *testing.T) {
+	service, _, notify, dispatcher := startCampaignBanners(t, 1, 0)
+	ctx := context.Background()
+
+	// each banner has the whole budget of the campaign, but they spend it together
+	assert.NoError(t, service.NewClick(ctx, events.Click{BannerID: 1, Price: 0.6}))
+	assert.Len(t, dispatcher.serving, 2)
+
+	assert.NoError(t, service.NewClick(ctx, events.Click{BannerID: 2, Price: 0.6}))
+	assert.Empty(t, dispatcher.serving)
+	assert.ElementsMatch(t, []events.BannerReachedLimits{
+		{BannerID: 1, Reason: reasonCampaignBudget},
+		{BannerID: 2, Reason: reasonCampaignBudget},
+	}, notify.stopped)
+
+	// banner started after the campaign has spent its budget is not served
+	assert.NoError(t, service.StartBanner(ctx, events.BannerStartedIncoming{BannerID: 4, CampaignID: 3, CampaignBudget: 1}))
+	assert.Empty(t, dispatcher.serving)
+	assert.Len(t, notify.stopped, 3)
+}
+
+func TestBannerService_CampaignDailyBudget(t *testing.T) {
+	service, repo, notify, dispatcher := startCampaignBanners(t, 100, 1)
+	ctx := context.Background()
+
+	assert.NoError(t, service.NewClick(ctx, events.Click{BannerID: 1, Price: 1}))
+	assert.Empty(t, dispatcher.serving)
+	assert.Empty(t, notify.stopped)
+	assert.Len(t, notify.paused, 2)
+	assert.Equal(t, "day_campaign_budget", notify.paused[0].Reason)
+	assert.Len(t, repo.paused, 2)
+}
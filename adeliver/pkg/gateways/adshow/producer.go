@@ -2,26 +2,28 @@ package adshow
 
 import (
 	"context"
-	"encoding/json"
 	"fmt"
 
 	"github.com/Shopify/sarama"
 	"github.com/crxfoz/teaserad/adeliver/internal/domain/events"
-	"go.opentelemetry.io/contrib/instrumentation/github.com/Shopify/sarama/otelsarama"
+	"github.com/crxfoz/teaserad/adeliver/pkg/kafka"
 	"go.opentelemetry.io/otel"
 )
 
 const (
 	topicStart = "adshow.banner.start"
 	topicStop  = "adshow.banner.stop"
+
+	topicThrottle   = "adshow.banner.throttle"
+	topicUnthrottle = "adshow.banner.unthrottle"
 )
 
 type Producer struct {
-	conn sarama.SyncProducer
+	producer *kafka.Producer
 }
 
 func New(conn sarama.SyncProducer) *Producer {
-	return &Producer{conn: conn}
+	return &Producer{producer: kafka.NewProducer(conn, "adeliver")}
 }
 
 const (
@@ -32,47 +34,41 @@ func (p *Producer) StartBanner(ctx context.Context, event events.BannerStart) er
 	spanCtx, span := otel.Tracer(tracerName).Start(ctx, "StartBanner")
 	defer span.End()
 
-	out, err := json.Marshal(event)
-	if err != nil {
-		return fmt.Errorf("could not marshal msg: %w", err)
+	if err := p.producer.Publish(spanCtx, topicStart, event); err != nil {
+		return fmt.Errorf("could not publish event: %w", err)
 	}
 
-	pitem := &sarama.ProducerMessage{
-		Topic: topicStart,
-		Key:   sarama.StringEncoder("1"), // TODO: use different keys
-		Value: sarama.ByteEncoder(out),
-	}
+	return nil
+}
 
-	otel.GetTextMapPropagator().Inject(spanCtx, otelsarama.NewProducerMessageCarrier(pitem))
+func (p *Producer) StopBanner(ctx context.Context, event events.BannerStop) error {
+	spanCtx, span := otel.Tracer(tracerName).Start(ctx, "StopBanner")
+	defer span.End()
 
-	_, _, err = p.conn.SendMessage(pitem)
-	if err != nil {
-		return fmt.Errorf("could not send message: %w", err)
+	if err := p.producer.Publish(spanCtx, topicStop, event); err != nil {
+		return fmt.Errorf("could not publish event: %w", err)
 	}
 
 	return nil
 }
 
-func (p *Producer) StopBanner(ctx context.Context, event events.BannerStop) error {
-	spanCtx, span := otel.Tracer(tracerName).Start(ctx, "StopBanner")
+func (p *Producer) ThrottleBanner(ctx context.Context, event events.BannerThrottle) error {
+	spanCtx, span := otel.Tracer(tracerName).Start(ctx, "ThrottleBanner")
 	defer span.End()
 
-	out, err := json.Marshal(event)
-	if err != nil {
-		return fmt.Errorf("could not marshal msg: %w", err)
+	if err := p.producer.Publish(spanCtx, topicThrottle, event); err != nil {
+		return fmt.Errorf("could not publish event: %w", err)
 	}
 
-	pitem := &sarama.ProducerMessage{
-		Topic: topicStop,
-		Key:   sarama.StringEncoder("1"), // TODO: use different keys
-		Value: sarama.ByteEncoder(out),
-	}
+	return nil
+}
 
-	otel.GetTextMapPropagator().Inject(spanCtx, otelsarama.NewProducerMessageCarrier(pitem))
+func (p *Producer) UnthrottleBanner(ctx context.Context, event events.BannerUnthrottle) error {
+	spanCtx, span := otel.Tracer(tracerName).Start(ctx, "UnthrottleBanner")
+	defer span.End()
 
-	_, _, err = p.conn.SendMessage(pitem)
-	if err != nil {
-		return fmt.Errorf("could not send message: %w", err)
+	if err := p.producer.Publish(spanCtx, topicUnthrottle, event); err != nil {
+		return fmt.Errorf("could not publish event: %w", err)
 	}
 
 	return nil
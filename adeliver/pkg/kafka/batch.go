@@ -0,0 +1,62 @@
+package kafka
+
+import (
+	"time"
+
+	"github.com/Shopify/sarama"
+)
+
+// consumeBatches collects messages until the batch is full or its wait is over, then processes them at once
+func (i *IncomeHandler) consumeBatches(session sarama.ConsumerGroupSession, claim sarama.ConsumerGroupClaim, r *route) error {
+	var batch []*sarama.ConsumerMessage
+
+	timer := time.NewTimer(r.batchWait)
+	timer.Stop()
+
+	defer timer.Stop()
+
+	flush := func() bool {
+		if len(batch) == 0 {
+			return true
+		}
+
+		// offsets are left uncommitted, so the batch is consumed again after rebalance
+		if err := i.handleMsg(session.Context(), batch, r); err != nil {
+			return false
+		}
+
+		session.MarkMessage(batch[len(batch)-1], "")
+		batch = nil
+
+		return true
+	}
+
+	for {
+		select {
+		case item, ok := <-claim.Messages():
+			if !ok {
+				flush()
+				return nil
+			}
+
+			batch = append(batch, item)
+			if len(batch) == 1 {
+				timer.Reset(r.batchWait)
+			}
+
+			if len(batch) < r.batchSize {
+				continue
+			}
+
+			timer.Stop()
+
+			if !flush() {
+				return nil
+			}
+		case <-timer.C:
+			if !flush() {
+				return nil
+			}
+		}
+	}
+}
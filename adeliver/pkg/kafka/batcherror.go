@@ -0,0 +1,62 @@
+package kafka
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/Shopify/sarama"
+)
+
+// BatchError is returned by RouteBatchFn when only some messages of the batch have failed,
+// the rest are processed, so only the failed ones are attempted again and dead-lettered
+type BatchError struct {
+	failed []*sarama.ConsumerMessage
+	errs   map[*sarama.ConsumerMessage]error
+}
+
+func (e *BatchError) Add(msg *sarama.ConsumerMessage, err error) {
+	if e.errs == nil {
+		e.errs = make(map[*sarama.ConsumerMessage]error)
+	}
+
+	if _, ok := e.errs[msg]; !ok {
+		e.failed = append(e.failed, msg)
+	}
+
+	e.errs[msg] = err
+}
+
+// ErrOrNil returns nil when nothing has failed
+func (e *BatchError) ErrOrNil() error {
+	if len(e.failed) == 0 {
+		return nil
+	}
+
+	return e
+}
+
+func (e *BatchError) Error() string {
+	return fmt.Sprintf("%d messages of batch failed, first one: %v", len(e.failed), e.errs[e.failed[0]])
+}
+
+// failedOf returns the messages err is about, all of them unless it's a BatchError
+func failedOf(err error, items []*sarama.ConsumerMessage) []*sarama.ConsumerMessage {
+	var batchErr *BatchError
+	if errors.As(err, &batchErr) && len(batchErr.failed) != 0 {
+		return batchErr.failed
+	}
+
+	return items
+}
+
+// reasonOf returns why the message has failed
+func reasonOf(err error, item *sarama.ConsumerMessage) error {
+	var batchErr *BatchError
+	if errors.As(err, &batchErr) {
+		if reason, ok := batchErr.errs[item]; ok {
+			return reason
+		}
+	}
+
+	return err
+}
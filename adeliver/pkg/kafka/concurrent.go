@@ -0,0 +1,147 @@
+package kafka
+
+import (
+	"hash/fnv"
+	"sync"
+
+	"github.com/Shopify/sarama"
+)
+
+// workerQueue is how many messages wait for each worker, so a slow key blocks the claim only when its queue is full
+const workerQueue = 16
+
+type processed struct {
+	item *sarama.ConsumerMessage
+	err  error
+}
+
+// consumeConcurrently spreads messages among workers by key, offset is marked only when all messages before it are processed
+func (i *IncomeHandler) consumeConcurrently(session sarama.ConsumerGroupSession, claim sarama.ConsumerGroupClaim, r *route) error {
+	queues := make([]chan *sarama.ConsumerMessage, i.workers)
+	// workers never wait for results to be collected
+	results := make(chan processed, i.workers*(workerQueue+1))
+
+	var wg sync.WaitGroup
+
+	for w := range queues {
+		queues[w] = make(chan *sarama.ConsumerMessage, workerQueue)
+
+		wg.Add(1)
+
+		go func(queue <-chan *sarama.ConsumerMessage) {
+			defer wg.Done()
+
+			for item := range queue {
+				results <- processed{item: item, err: i.handleMsg(session.Context(), []*sarama.ConsumerMessage{item}, r)}
+			}
+		}(queues[w])
+	}
+
+	tracker := newOffsetTracker()
+
+	collect := func(res processed) {
+		// offset of the message is left uncommitted as well as all after it, so they are consumed again after rebalance
+		if res.err != nil {
+			return
+		}
+
+		if item := tracker.done(res.item.Offset); item != nil {
+			session.MarkMessage(item, "")
+		}
+	}
+
+	var next int
+
+	messages := claim.Messages()
+	for messages != nil {
+		select {
+		case item, ok := <-messages:
+			if !ok {
+				messages = nil
+				continue
+			}
+
+			tracker.add(item)
+
+			queue := queues[workerFor(item, len(queues), &next)]
+			for sent := false; !sent; {
+				select {
+				case queue <- item:
+					sent = true
+				case res := <-results:
+					collect(res)
+				}
+			}
+		case res := <-results:
+			collect(res)
+		}
+	}
+
+	for _, queue := range queues {
+		close(queue)
+	}
+
+	wg.Wait()
+	close(results)
+
+	for res := range results {
+		collect(res)
+	}
+
+	return nil
+}
+
+// workerFor keeps messages with the same key on the same worker, messages without key are spread evenly
+func workerFor(item *sarama.ConsumerMessage, workers int, next *int) int {
+	if item.Key == nil {
+		*next = (*next + 1) % workers
+		return *next
+	}
+
+	h := fnv.New32a()
+	_, _ = h.Write(item.Key)
+
+	return int(h.Sum32() % uint32(workers))
+}
+
+// offsetTracker keeps messages of a partition in order they are consumed until all before them are processed
+type offsetTracker struct {
+	pending []*trackedOffset
+	byID    map[int64]*trackedOffset
+}
+
+type trackedOffset struct {
+	item *sarama.ConsumerMessage
+	done bool
+}
+
+func newOffsetTracker() *offsetTracker {
+	return &offsetTracker{byID: make(map[int64]*trackedOffset)}
+}
+
+func (t *offsetTracker) add(item *sarama.ConsumerMessage) {
+	tracked := &trackedOffset{item: item}
+
+	t.pending = append(t.pending, tracked)
+	t.byID[item.Offset] = tracked
+}
+
+// done returns the last message which can be marked, nil if some message before it is still processed
+func (t *offsetTracker) done(offset int64) *sarama.ConsumerMessage {
+	tracked, ok := t.byID[offset]
+	if !ok {
+		return nil
+	}
+
+	tracked.done = true
+
+	var last *sarama.ConsumerMessage
+
+	for len(t.pending) != 0 && t.pending[0].done {
+		last = t.pending[0].item
+		delete(t.byID, last.Offset)
+		t.pending = t.pending[1:]
+	}
+
+	return last
+}
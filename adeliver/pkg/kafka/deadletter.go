@@ -0,0 +1,185 @@
+package kafka
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/Shopify/sarama"
+)
+
+// headers added to dead-lettered messages, the original ones are kept
+const (
+	headerError     = "dlq-error"
+	headerAttempts  = "dlq-attempts"
+	headerTopic     = "dlq-topic"
+	headerPartition = "dlq-partition"
+	headerOffset    = "dlq-offset"
+
+	headerPrefix = "dlq-"
+)
+
+// DeadLetterTopic is where messages of the topic are sent if they could not be processed
+func DeadLetterTopic(topic string) string {
+	return topic + ".dlq"
+}
+
+func deadLetterMessage(item *sarama.ConsumerMessage, reason error, attempts int) *sarama.ProducerMessage {
+	msg := &sarama.ProducerMessage{
+		Topic: DeadLetterTopic(item.Topic),
+		Value: sarama.ByteEncoder(item.Value),
+	}
+
+	if item.Key != nil {
+		msg.Key = sarama.ByteEncoder(item.Key)
+	}
+
+	for _, header := range item.Headers {
+		msg.Headers = append(msg.Headers, *header)
+	}
+
+	msg.Headers = append(msg.Headers,
+		sarama.RecordHeader{Key: []byte(headerError), Value: []byte(reason.Error())},
+		sarama.RecordHeader{Key: []byte(headerAttempts), Value: []byte(strconv.Itoa(attempts))},
+		sarama.RecordHeader{Key: []byte(headerTopic), Value: []byte(item.Topic)},
+		sarama.RecordHeader{Key: []byte(headerPartition), Value: []byte(strconv.Itoa(int(item.Partition)))},
+		sarama.RecordHeader{Key: []byte(headerOffset), Value: []byte(strconv.FormatInt(item.Offset, 10))},
+	)
+
+	return msg
+}
+
+// replayMessage restores the message the way it has been sent to the source topic
+func replayMessage(item *sarama.ConsumerMessage, topic string) *sarama.ProducerMessage {
+	msg := &sarama.ProducerMessage{
+		Topic: topic,
+		Value: sarama.ByteEncoder(item.Value),
+	}
+
+	if item.Key != nil {
+		msg.Key = sarama.ByteEncoder(item.Key)
+	}
+
+	for _, header := range item.Headers {
+		if strings.HasPrefix(string(header.Key), headerPrefix) {
+			if string(header.Key) == headerTopic {
+				msg.Topic = string(header.Value)
+			}
+
+			continue
+		}
+
+		msg.Headers = append(msg.Headers, *header)
+	}
+
+	return msg
+}
+
+// Replay moves messages which are in the dead letter topic of the topic at the moment back to it
+// and reports how many of them are moved. Progress is committed for the replay group,
+// so messages are not replayed twice
+func Replay(ctx context.Context, client sarama.Client, producer sarama.SyncProducer, topic string) (int, error) {
+	dlq := DeadLetterTopic(topic)
+
+	offsets, err := sarama.NewOffsetManagerFromClient(dlq+".replay", client)
+	if err != nil {
+		return 0, fmt.Errorf("could not create offset manager: %w", err)
+	}
+
+	defer offsets.Close()
+
+	consumer, err := sarama.NewConsumerFromClient(client)
+	if err != nil {
+		return 0, fmt.Errorf("could not create consumer: %w", err)
+	}
+
+	defer consumer.Close()
+
+	partitions, err := client.Partitions(dlq)
+	if err != nil {
+		return 0, fmt.Errorf("could not get partitions: %w", err)
+	}
+
+	var replayed int
+
+	for _, partition := range partitions {
+		n, err := replayPartition(ctx, client, consumer, offsets, producer, dlq, partition, topic)
+		replayed += n
+
+		if err != nil {
+			return replayed, fmt.Errorf("could not replay partition %d: %w", partition, err)
+		}
+	}
+
+	return replayed, nil
+}
+
+func replayPartition(
+	ctx context.Context,
+	client sarama.Client,
+	consumer sarama.Consumer,
+	offsets sarama.OffsetManager,
+	producer sarama.SyncProducer,
+	dlq string,
+	partition int32,
+	topic string,
+) (int, error) {
+	partitionOffsets, err := offsets.ManagePartition(dlq, partition)
+	if err != nil {
+		return 0, fmt.Errorf("could not manage offsets: %w", err)
+	}
+
+	defer partitionOffsets.Close()
+	// progress is committed before the partition is released
+	defer offsets.Commit()
+
+	oldest, err := client.GetOffset(dlq, partition, sarama.OffsetOldest)
+	if err != nil {
+		return 0, fmt.Errorf("could not get oldest offset: %w", err)
+	}
+
+	// messages added during replay wait for the next one
+	newest, err := client.GetOffset(dlq, partition, sarama.OffsetNewest)
+	if err != nil {
+		return 0, fmt.Errorf("could not get newest offset: %w", err)
+	}
+
+	next, _ := partitionOffsets.NextOffset()
+	if next < oldest {
+		next = oldest
+	}
+
+	if next >= newest {
+		return 0, nil
+	}
+
+	partitionConsumer, err := consumer.ConsumePartition(dlq, partition, next)
+	if err != nil {
+		return 0, fmt.Errorf("could not consume: %w", err)
+	}
+
+	defer partitionConsumer.Close()
+
+	var replayed int
+
+	for {
+		select {
+		case item := <-partitionConsumer.Messages():
+			if _, _, err := producer.SendMessage(replayMessage(item, topic)); err != nil {
+				return replayed, fmt.Errorf("could not send message: %w", err)
+			}
+
+			partitionOffsets.MarkOffset(item.Offset+1, "")
+			replayed++
+
+			if item.Offset+1 >= newest {
+				return replayed, nil
+			}
+		case err := <-partitionConsumer.Errors():
+			return replayed, fmt.Errorf("consumer failed: %w", err)
+		case <-ctx.Done():
+			return replayed, ctx.Err()
+		}
+	}
+}
@@ -2,18 +2,24 @@ package kafka
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"time"
 
 	"github.com/Shopify/sarama"
 	"github.com/crxfoz/teaserad/adeliver/internal/domain"
 	"go.opentelemetry.io/contrib/instrumentation/github.com/Shopify/sarama/otelsarama"
 	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
 	"go.uber.org/zap"
 )
 
 type IncomeHandler struct {
-	logger domain.Logger
-	routes map[string]RouteFn
+	logger      domain.Logger
+	routes      map[string]*route
+	deadLetters sarama.SyncProducer
+	workers     int
 }
 
 func (i *IncomeHandler) Setup(_ sarama.ConsumerGroupSession) error {
@@ -24,27 +30,85 @@ func (i *IncomeHandler) Cleanup(_ sarama.ConsumerGroupSession) error {
 	return nil
 }
 
-func (i *IncomeHandler) handleMsg(item *sarama.ConsumerMessage, fn RouteFn) {
-	prevCtx := otel.GetTextMapPropagator().Extract(context.Background(), otelsarama.NewConsumerMessageCarrier(item))
-	ctx, span := otel.Tracer("consumer").Start(prevCtx, "OnMessage")
+// handleMsg retries the route and dead-letters the messages which still fail,
+// error means the messages are neither processed nor dead-lettered since the session is over
+func (i *IncomeHandler) handleMsg(sessCtx context.Context, items []*sarama.ConsumerMessage, r *route) error {
+	ctx, span := i.startSpan(sessCtx, items)
 	defer span.End()
 
-	if err := fn(ctx, item); err != nil {
-		i.logger.Errorw("could not process msg", "err", err)
-		return
+	attempts, failed, err := r.process(ctx, items)
+	if err == nil {
+		return nil
+	}
+
+	if sessCtx.Err() != nil {
+		return sessCtx.Err()
+	}
+
+	i.logger.Errorw("could not process msg", "err", err, "topic", items[0].Topic, "attempts", attempts,
+		"count", len(items), "failed", len(failed))
+
+	for _, item := range failed {
+		if err := i.deadLetter(sessCtx, item, reasonOf(err, item), attempts); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// startSpan continues the trace of the producer, batch starts its own one
+func (i *IncomeHandler) startSpan(ctx context.Context, items []*sarama.ConsumerMessage) (context.Context, trace.Span) {
+	if len(items) > 1 {
+		return otel.Tracer("consumer").Start(ctx, "OnBatch", trace.WithAttributes(attribute.Int("batch.size", len(items))))
+	}
+
+	prevCtx := otel.GetTextMapPropagator().Extract(ctx, otelsarama.NewConsumerMessageCarrier(items[0]))
+
+	return otel.Tracer("consumer").Start(prevCtx, "OnMessage")
+}
+
+// deadLetter keeps sending the message to the dead letter topic, the partition is blocked meanwhile
+func (i *IncomeHandler) deadLetter(ctx context.Context, item *sarama.ConsumerMessage, reason error, attempts int) error {
+	delay := defaultBackoff
+
+	for {
+		_, _, err := i.deadLetters.SendMessage(deadLetterMessage(item, reason, attempts))
+		if err == nil {
+			return nil
+		}
+
+		i.logger.Errorw("could not send msg to dead letter topic", "err", err, "topic", item.Topic)
+
+		if !wait(ctx, delay) {
+			return ctx.Err()
+		}
+
+		delay = nextBackoff(delay)
 	}
 }
 
 func (i *IncomeHandler) ConsumeClaim(session sarama.ConsumerGroupSession, claim sarama.ConsumerGroupClaim) error {
+	routeFn, ok := i.routes[claim.Topic()]
+	if !ok {
+		i.logger.Warnw("got unexpected claim", "topic", claim.Topic())
+		return nil
+	}
+
+	switch {
+	case routeFn.batchFn != nil:
+		return i.consumeBatches(session, claim, routeFn)
+	case i.workers > 1:
+		return i.consumeConcurrently(session, claim, routeFn)
+	}
+
 	for item := range claim.Messages() {
-		if routeFn, ok := i.routes[item.Topic]; ok {
-			i.handleMsg(item, routeFn)
-		} else {
-			i.logger.Warnw("got unexpected msg", "topic", item.Topic)
+		// offset is left uncommitted, so the message is consumed again after rebalance
+		if err := i.handleMsg(session.Context(), []*sarama.ConsumerMessage{item}, routeFn); err != nil {
+			return nil
 		}
 
-		// TODO: ack?
-		// session.MarkMessage(item, "commited")
+		session.MarkMessage(item, "")
 	}
 
 	return nil
@@ -52,23 +116,28 @@ func (i *IncomeHandler) ConsumeClaim(session sarama.ConsumerGroupSession, claim
 
 type RouteFn func(context.Context, *sarama.ConsumerMessage) error
 
+type RouteBatchFn func(context.Context, []*sarama.ConsumerMessage) error
+
 type Kafka struct {
-	logger *zap.SugaredLogger
+	logger      *zap.SugaredLogger
+	deadLetters sarama.SyncProducer
 }
 
-func New(logger *zap.SugaredLogger) *Kafka {
-	return &Kafka{logger: logger}
+// New creates consumers which send messages that could not be processed to the `<topic>.dlq` topic via deadLetters
+func New(logger *zap.SugaredLogger, deadLetters sarama.SyncProducer) *Kafka {
+	return &Kafka{logger: logger, deadLetters: deadLetters}
 }
 
 type Session struct {
-	routes  map[string]RouteFn
+	routes  map[string]*route
+	workers int
 	handler sarama.ConsumerGroupHandler
 	conn    sarama.ConsumerGroup
 }
 
 func newSession(conn sarama.ConsumerGroup) *Session {
 	return &Session{
-		routes: make(map[string]RouteFn),
+		routes: make(map[string]*route),
 		conn:   conn,
 	}
 }
@@ -82,12 +151,20 @@ func (s *Session) topics() []string {
 
 	return out
 }
+
+// Start blocks until Stop is called
 func (s *Session) Start() error {
-	if err := s.conn.Consume(context.Background(), s.topics(), s.handler); err != nil {
-		return fmt.Errorf("could not setup consumer: %w", err)
-	}
+	// consume returns on every rebalance, so it's called again to join the new session
+	for {
+		err := s.conn.Consume(context.Background(), s.topics(), s.handler)
+		if errors.Is(err, sarama.ErrClosedConsumerGroup) {
+			return nil
+		}
 
-	return nil
+		if err != nil {
+			return fmt.Errorf("could not setup consumer: %w", err)
+		}
+	}
 }
 
 func (s *Session) Stop() error {
@@ -98,8 +175,25 @@ func (s *Session) Stop() error {
 	return nil
 }
 
-func (s *Session) AddRoute(topic string, route RouteFn) {
-	s.routes[topic] = route
+// AddRoute handles messages of the topic with fn, by default it's retried a few times before the message is dead-lettered
+func (s *Session) AddRoute(topic string, fn RouteFn, opts ...RouteOption) {
+	s.routes[topic] = newRoute(fn, nil, opts)
+}
+
+// AddBatchRoute handles messages of the topic with fn in batches of up to size messages collected within wait,
+// the whole batch is retried and dead-lettered if it fails
+func (s *Session) AddBatchRoute(topic string, fn RouteBatchFn, size int, wait time.Duration, opts ...RouteOption) {
+	r := newRoute(nil, fn, opts)
+	r.batchSize = size
+	r.batchWait = wait
+
+	s.routes[topic] = r
+}
+
+// SetWorkers makes each claim process messages with different keys concurrently by the given number of workers,
+// messages with the same key are still processed in order. Batch routes are not affected
+func (s *Session) SetWorkers(workers int) {
+	s.workers = workers
 }
 
 func (k *Kafka) NewConsumer(withName string, conn sarama.ConsumerGroup, sessBuilder func(session *Session) error) (*Session, error) {
@@ -109,8 +203,10 @@ func (k *Kafka) NewConsumer(withName string, conn sarama.ConsumerGroup, sessBuil
 	}
 
 	handler := &IncomeHandler{
-		logger: k.logger.Named(withName),
-		routes: sess.routes,
+		logger:      k.logger.Named(withName),
+		routes:      sess.routes,
+		deadLetters: k.deadLetters,
+		workers:     sess.workers,
 	}
 
 	wrappedHandler := otelsarama.WrapConsumerGroupHandler(handler)
@@ -0,0 +1,263 @@
+package kafka
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Shopify/sarama"
+	"github.com/Shopify/sarama/mocks"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+)
+
+type fakeSession struct {
+	sarama.ConsumerGroupSession
+	marked []int64
+}
+
+func (f *fakeSession) Context() context.Context {
+	return context.Background()
+}
+
+func (f *fakeSession) MarkMessage(msg *sarama.ConsumerMessage, _ string) {
+	f.marked = append(f.marked, msg.Offset)
+}
+
+type fakeClaim struct {
+	sarama.ConsumerGroupClaim
+	topic    string
+	messages chan *sarama.ConsumerMessage
+}
+
+func newFakeClaim(topic string, items ...*sarama.ConsumerMessage) *fakeClaim {
+	claim := &fakeClaim{topic: topic, messages: make(chan *sarama.ConsumerMessage, len(items))}
+	for _, item := range items {
+		claim.messages <- item
+	}
+
+	close(claim.messages)
+
+	return claim
+}
+
+func (f *fakeClaim) Topic() string {
+	return f.topic
+}
+
+func (f *fakeClaim) Messages() <-chan *sarama.ConsumerMessage {
+	return f.messages
+}
+
+func TestIncomeHandler_ConsumeClaim(t *testing.T) {
+	failure := errors.New("banner not found")
+
+	var calls int
+
+	sess := newSession(nil)
+	sess.AddRoute("topic", func(_ context.Context, msg *sarama.ConsumerMessage) error {
+		calls++
+		if string(msg.Value) == "bad" {
+			return failure
+		}
+
+		return nil
+	}, WithRetry(2, 0))
+
+	producer := mocks.NewSyncProducer(t, nil)
+	producer.ExpectSendMessageWithMessageCheckerFunctionAndSucceed(func(msg *sarama.ProducerMessage) error {
+		if msg.Topic != "topic.dlq" {
+			return errors.New("wrong topic")
+		}
+
+		replayed := replayMessage(&sarama.ConsumerMessage{Value: []byte("bad"), Headers: consumerHeaders(msg.Headers)}, "other")
+		if replayed.Topic != "topic" || len(replayed.Headers) != 1 {
+			return errors.New("message is not restored")
+		}
+
+		return nil
+	})
+
+	handler := &IncomeHandler{logger: zap.NewNop().Sugar(), routes: sess.routes, deadLetters: producer}
+
+	traceHeader := &sarama.RecordHeader{Key: []byte("traceparent"), Value: []byte("00-1-2-01")}
+	claim := newFakeClaim("topic",
+		&sarama.ConsumerMessage{Topic: "topic", Offset: 1, Value: []byte("good")},
+		&sarama.ConsumerMessage{Topic: "topic", Offset: 2, Value: []byte("bad"), Headers: []*sarama.RecordHeader{traceHeader}},
+		&sarama.ConsumerMessage{Topic: "topic", Offset: 3, Value: []byte("good")})
+
+	consumerSess := &fakeSession{}
+
+	assert.NoError(t, handler.ConsumeClaim(consumerSess, claim))
+	assert.Equal(t, 4, calls)
+	assert.Equal(t, []int64{1, 2, 3}, consumerSess.marked)
+	assert.NoError(t, producer.Close())
+}
+
+func consumerHeaders(headers []sarama.RecordHeader) []*sarama.RecordHeader {
+	out := make([]*sarama.RecordHeader, 0, len(headers))
+	for i := range headers {
+		out = append(out, &headers[i])
+	}
+
+	return out
+}
+
+func TestDeadLetterMessage(t *testing.T) {
+	item := &sarama.ConsumerMessage{Topic: "topic", Partition: 2, Offset: 10, Key: []byte("1"), Value: []byte("payload")}
+
+	msg := deadLetterMessage(item, errors.New("failed"), 3)
+
+	headers := map[string]string{}
+	for _, header := range msg.Headers {
+		headers[string(header.Key)] = string(header.Value)
+	}
+
+	assert.Equal(t, "topic.dlq", msg.Topic)
+	assert.Equal(t, map[string]string{
+		headerError:     "failed",
+		headerAttempts:  "3",
+		headerTopic:     "topic",
+		headerPartition: "2",
+		headerOffset:    "10",
+	}, headers)
+
+	value, _ := msg.Value.Encode()
+	assert.Equal(t, "payload", string(value))
+}
+
+func TestIncomeHandler_ConsumeClaimConcurrently(t *testing.T) {
+	var (
+		mu    sync.Mutex
+		order = map[string][]int64{}
+	)
+
+	sess := newSession(nil)
+	sess.SetWorkers(4)
+	sess.AddRoute("topic", func(_ context.Context, msg *sarama.ConsumerMessage) error {
+		mu.Lock()
+		defer mu.Unlock()
+
+		order[string(msg.Key)] = append(order[string(msg.Key)], msg.Offset)
+
+		return nil
+	})
+
+	var items []*sarama.ConsumerMessage
+	for offset := int64(0); offset < 100; offset++ {
+		items = append(items, &sarama.ConsumerMessage{Topic: "topic", Offset: offset, Key: []byte(strconv.Itoa(int(offset % 7)))})
+	}
+
+	handler := &IncomeHandler{logger: zap.NewNop().Sugar(), routes: sess.routes, workers: sess.workers}
+	consumerSess := &fakeSession{}
+
+	assert.NoError(t, handler.ConsumeClaim(consumerSess, newFakeClaim("topic", items...)))
+
+	for key, offsets := range order {
+		assert.True(t, sort.SliceIsSorted(offsets, func(i, j int) bool { return offsets[i] < offsets[j] }), key)
+	}
+
+	// marks never go back, the last one covers all messages
+	assert.True(t, sort.SliceIsSorted(consumerSess.marked, func(i, j int) bool { return consumerSess.marked[i] < consumerSess.marked[j] }))
+	assert.Equal(t, int64(99), consumerSess.marked[len(consumerSess.marked)-1])
+}
+
+func TestOffsetTracker_Done(t *testing.T) {
+	tracker := newOffsetTracker()
+	for offset := int64(1); offset <= 3; offset++ {
+		tracker.add(&sarama.ConsumerMessage{Offset: offset})
+	}
+
+	assert.Nil(t, tracker.done(2))
+	assert.Nil(t, tracker.done(5))
+
+	item := tracker.done(1)
+	if assert.NotNil(t, item) {
+		assert.Equal(t, int64(2), item.Offset)
+	}
+
+	item = tracker.done(3)
+	if assert.NotNil(t, item) {
+		assert.Equal(t, int64(3), item.Offset)
+	}
+}
+
+func TestIncomeHandler_ConsumeBatches(t *testing.T) {
+	var sizes []int
+
+	sess := newSession(nil)
+	sess.AddBatchRoute("topic", func(_ context.Context, msgs []*sarama.ConsumerMessage) error {
+		sizes = append(sizes, len(msgs))
+		return nil
+	}, 2, time.Minute)
+
+	handler := &IncomeHandler{logger: zap.NewNop().Sugar(), routes: sess.routes}
+	consumerSess := &fakeSession{}
+
+	claim := newFakeClaim("topic",
+		&sarama.ConsumerMessage{Topic: "topic", Offset: 1},
+		&sarama.ConsumerMessage{Topic: "topic", Offset: 2},
+		&sarama.ConsumerMessage{Topic: "topic", Offset: 3})
+
+	assert.NoError(t, handler.ConsumeClaim(consumerSess, claim))
+	assert.Equal(t, []int{2, 1}, sizes)
+	assert.Equal(t, []int64{2, 3}, consumerSess.marked)
+}
+
+func TestIncomeHandler_ConsumeBatchesPartially(t *testing.T) {
+	failure := errors.New("could not parse message")
+
+	var attempted [][]int64
+
+	sess := newSession(nil)
+	sess.AddBatchRoute("topic", func(_ context.Context, msgs []*sarama.ConsumerMessage) error {
+		var (
+			offsets  []int64
+			batchErr BatchError
+		)
+
+		for _, msg := range msgs {
+			offsets = append(offsets, msg.Offset)
+			if string(msg.Value) == "bad" {
+				batchErr.Add(msg, failure)
+			}
+		}
+
+		attempted = append(attempted, offsets)
+
+		return batchErr.ErrOrNil()
+	}, 3, time.Minute, WithRetry(2, 0))
+
+	producer := mocks.NewSyncProducer(t, nil)
+	producer.ExpectSendMessageWithMessageCheckerFunctionAndSucceed(func(msg *sarama.ProducerMessage) error {
+		for _, header := range msg.Headers {
+			if string(header.Key) == headerOffset && string(header.Value) != "2" {
+				return errors.New("processed message is dead-lettered")
+			}
+
+			if string(header.Key) == headerError && string(header.Value) != failure.Error() {
+				return errors.New("reason of the message is lost")
+			}
+		}
+
+		return nil
+	})
+
+	handler := &IncomeHandler{logger: zap.NewNop().Sugar(), routes: sess.routes, deadLetters: producer}
+	consumerSess := &fakeSession{}
+
+	claim := newFakeClaim("topic",
+		&sarama.ConsumerMessage{Topic: "topic", Offset: 1, Value: []byte("good")},
+		&sarama.ConsumerMessage{Topic: "topic", Offset: 2, Value: []byte("bad")},
+		&sarama.ConsumerMessage{Topic: "topic", Offset: 3, Value: []byte("good")})
+
+	assert.NoError(t, handler.ConsumeClaim(consumerSess, claim))
+	// only the failed message is attempted again
+	assert.Equal(t, [][]int64{{1, 2, 3}, {2}}, attempted)
+	assert.Equal(t, []int64{3}, consumerSess.marked)
+	assert.NoError(t, producer.Close())
+}
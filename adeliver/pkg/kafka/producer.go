@@ -0,0 +1,103 @@
+package kafka
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/Shopify/sarama"
+	contracts "github.com/crxfoz/teaserad/contracts/events"
+	"go.opentelemetry.io/contrib/instrumentation/github.com/Shopify/sarama/otelsarama"
+	"go.opentelemetry.io/otel"
+)
+
+// Outbox stores messages within the transaction of the context, they are sent to kafka after it's committed
+type Outbox interface {
+	Add(ctx context.Context, msg *sarama.ProducerMessage) error
+}
+
+// Producer sends events keyed by the event, so events with the same key are consumed in order they have been sent,
+// e.g. start and stop of the same banner. Envelope of the event and the trace of the context are sent in headers
+type Producer struct {
+	source      string
+	contentType string
+	send        func(ctx context.Context, msg *sarama.ProducerMessage) error
+}
+
+type ProducerOption func(p *Producer)
+
+// WithContentType makes producer encode events with the content type when they have such encoding,
+// e.g. protobuf for views and clicks. Consumers decode any of them, so it's switched per producer
+func WithContentType(contentType string) ProducerOption {
+	return func(p *Producer) {
+		p.contentType = contentType
+	}
+}
+
+// NewProducer sends events on behalf of the source service
+func NewProducer(conn sarama.SyncProducer, source string, opts ...ProducerOption) *Producer {
+	return newProducer(source, func(_ context.Context, msg *sarama.ProducerMessage) error {
+		_, _, err := conn.SendMessage(msg)
+		return err
+	}, opts)
+}
+
+// NewOutboxProducer adds events to the outbox instead of sending them right away
+func NewOutboxProducer(outbox Outbox, source string, opts ...ProducerOption) *Producer {
+	return newProducer(source, outbox.Add, opts)
+}
+
+func newProducer(source string, send func(ctx context.Context, msg *sarama.ProducerMessage) error, opts []ProducerOption) *Producer {
+	p := &Producer{source: source, contentType: contracts.ContentTypeJSON, send: send}
+	for _, opt := range opts {
+		opt(p)
+	}
+
+	return p
+}
+
+func (p *Producer) Publish(ctx context.Context, topic string, event contracts.Event) error {
+	out, contentType, err := contracts.Encode(event, p.contentType)
+	if err != nil {
+		return fmt.Errorf("could not encode msg: %w", err)
+	}
+
+	pitem := &sarama.ProducerMessage{
+		Topic: topic,
+		Key:   sarama.StringEncoder(event.PartitionKey()),
+		Value: sarama.ByteEncoder(out),
+	}
+
+	envelope := contracts.NewEnvelope(p.source, event)
+	envelope.ContentType = contentType
+
+	for key, value := range envelope.Headers() {
+		pitem.Headers = append(pitem.Headers, sarama.RecordHeader{Key: []byte(key), Value: []byte(value)})
+	}
+
+	otel.GetTextMapPropagator().Inject(ctx, otelsarama.NewProducerMessageCarrier(pitem))
+
+	if err := p.send(ctx, pitem); err != nil {
+		return fmt.Errorf("could not send message: %w", err)
+	}
+
+	return nil
+}
+
+// Decode reads the event of the message in the encoding it has been sent with, upgrading it to the current version
+func Decode(msg *sarama.ConsumerMessage, event contracts.Event) error {
+	headers := make(map[string]string, len(msg.Headers))
+	for _, header := range msg.Headers {
+		headers[string(header.Key)] = string(header.Value)
+	}
+
+	envelope, err := contracts.ParseEnvelope(headers)
+	if err != nil {
+		return fmt.Errorf("could not parse envelope: %w", err)
+	}
+
+	if err := contracts.Decode(envelope, msg.Value, event); err != nil {
+		return fmt.Errorf("could not decode event: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,94 @@
+package kafka
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/Shopify/sarama"
+	"github.com/Shopify/sarama/mocks"
+	contracts "github.com/crxfoz/teaserad/contracts/events"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestProducer_Publish(t *testing.T) {
+	var sent *sarama.ProducerMessage
+
+	conn := mocks.NewSyncProducer(t, nil)
+	conn.ExpectSendMessageWithMessageCheckerFunctionAndSucceed(func(msg *sarama.ProducerMessage) error {
+		key, _ := msg.Key.Encode()
+		if msg.Topic != "topic" || string(key) != "42" {
+			return errors.New("unexpected message")
+		}
+
+		sent = msg
+
+		return nil
+	})
+
+	stop := contracts.BannerStop{BannerID: 42}
+
+	assert.NoError(t, NewProducer(conn, "adeliver").Publish(context.Background(), "topic", stop))
+	assert.NoError(t, conn.Close())
+
+	// consumer gets the event back along with its envelope
+	value, _ := sent.Value.Encode()
+	received := &sarama.ConsumerMessage{Topic: sent.Topic, Value: value}
+	for i := range sent.Headers {
+		received.Headers = append(received.Headers, &sent.Headers[i])
+	}
+
+	var decoded contracts.BannerStop
+	assert.NoError(t, Decode(received, &decoded))
+	assert.Equal(t, stop, decoded)
+
+	var wrong contracts.BannerStart
+	assert.ErrorIs(t, Decode(received, &wrong), contracts.ErrUnexpectedType)
+}
+
+func TestProducer_PublishProtobuf(t *testing.T) {
+	var sent []*sarama.ProducerMessage
+
+	conn := mocks.NewSyncProducer(t, nil)
+	for i := 0; i < 2; i++ {
+		conn.ExpectSendMessageWithMessageCheckerFunctionAndSucceed(func(msg *sarama.ProducerMessage) error {
+			sent = append(sent, msg)
+			return nil
+		})
+	}
+
+	producer := NewProducer(conn, "adshow", WithContentType(contracts.ContentTypeProtobuf))
+
+	view := contracts.View{ViewID: "5f0c", BannerID: 42, PlatformID: 7, Country: "DE", CreatedAt: 100}
+	assert.NoError(t, producer.Publish(context.Background(), "adshow.action.show", view))
+	// events without binary encoding are still sent as JSON
+	assert.NoError(t, producer.Publish(context.Background(), "adclick.action.click.invalid", contracts.InvalidClick{BannerID: 42}))
+	assert.NoError(t, conn.Close())
+
+	received := make([]*sarama.ConsumerMessage, 0, len(sent))
+	contentTypes := make([]string, 0, len(sent))
+
+	for _, msg := range sent {
+		value, _ := msg.Value.Encode()
+		item := &sarama.ConsumerMessage{Topic: msg.Topic, Value: value}
+
+		for i := range msg.Headers {
+			item.Headers = append(item.Headers, &msg.Headers[i])
+			if string(msg.Headers[i].Key) == contracts.HeaderContentType {
+				contentTypes = append(contentTypes, string(msg.Headers[i].Value))
+			}
+		}
+
+		received = append(received, item)
+	}
+
+	assert.Equal(t, []string{contracts.ContentTypeProtobuf, contracts.ContentTypeJSON}, contentTypes)
+
+	var decoded contracts.View
+	assert.NoError(t, Decode(received[0], &decoded))
+	assert.Equal(t, view, decoded)
+
+	var invalid contracts.InvalidClick
+	assert.NoError(t, Decode(received[1], &invalid))
+	assert.Equal(t, 42, invalid.BannerID)
+}
@@ -0,0 +1,100 @@
+package kafka
+
+import (
+	"context"
+	"time"
+
+	"github.com/Shopify/sarama"
+)
+
+const (
+	defaultAttempts = 3
+	defaultBackoff  = 100 * time.Millisecond
+	// maxBackoff bounds the delay between attempts
+	maxBackoff = 10 * time.Second
+)
+
+type route struct {
+	fn        RouteFn
+	batchFn   RouteBatchFn
+	batchSize int
+	batchWait time.Duration
+	attempts  int
+	backoff   time.Duration
+}
+
+func newRoute(fn RouteFn, batchFn RouteBatchFn, opts []RouteOption) *route {
+	r := &route{fn: fn, batchFn: batchFn, attempts: defaultAttempts, backoff: defaultBackoff}
+	for _, opt := range opts {
+		opt(r)
+	}
+
+	return r
+}
+
+type RouteOption func(r *route)
+
+// WithRetry sets how many times the message is processed before it's dead-lettered
+// and the delay after the first failure, it's doubled after each next one
+func WithRetry(attempts int, backoff time.Duration) RouteOption {
+	return func(r *route) {
+		if attempts < 1 {
+			attempts = 1
+		}
+
+		r.attempts = attempts
+		r.backoff = backoff
+	}
+}
+
+func (r *route) call(ctx context.Context, items []*sarama.ConsumerMessage) error {
+	if r.batchFn != nil {
+		return r.batchFn(ctx, items)
+	}
+
+	return r.fn(ctx, items[0])
+}
+
+// process calls the route until it succeeds, runs out of attempts or ctx is done,
+// it returns the number of attempts made, the messages which still fail and the last error.
+// Messages of the batch which are not reported by BatchError are processed, so they are not attempted again
+func (r *route) process(ctx context.Context, items []*sarama.ConsumerMessage) (int, []*sarama.ConsumerMessage, error) {
+	delay := r.backoff
+
+	for attempt := 1; ; attempt++ {
+		err := r.call(ctx, items)
+		if err == nil {
+			return attempt, nil, nil
+		}
+
+		items = failedOf(err, items)
+
+		if attempt >= r.attempts || !wait(ctx, delay) {
+			return attempt, items, err
+		}
+
+		delay = nextBackoff(delay)
+	}
+}
+
+func nextBackoff(delay time.Duration) time.Duration {
+	delay *= 2
+	if delay > maxBackoff {
+		return maxBackoff
+	}
+
+	return delay
+}
+
+// wait reports false if ctx is done before the delay passes
+func wait(ctx context.Context, delay time.Duration) bool {
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
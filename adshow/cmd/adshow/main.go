@@ -3,11 +3,14 @@ package main
 import (
 	"context"
 	"fmt"
+	"io"
 	"os"
 	"os/signal"
 	"syscall"
+	"time"
 
 	"github.com/Shopify/sarama"
+	"github.com/crxfoz/teaserad/adclick/pkg/clicksign"
 	"github.com/crxfoz/teaserad/adeliver/pkg/kafka"
 	"github.com/crxfoz/teaserad/adshow/internal/delivery/http"
 	kafdel "github.com/crxfoz/teaserad/adshow/internal/delivery/kafka"
@@ -15,6 +18,7 @@ import (
 	"github.com/crxfoz/teaserad/adshow/internal/repo/mysql"
 	tarantoolrepo "github.com/crxfoz/teaserad/adshow/internal/repo/tarantool"
 	"github.com/crxfoz/teaserad/adshow/internal/services/show"
+	"github.com/crxfoz/teaserad/adshow/pkg/geoip"
 	"github.com/crxfoz/teaserad/adshow/pkg/httpserver"
 	clustertnt "github.com/crxfoz/teaserad/adshow/pkg/tarantool"
 	"github.com/crxfoz/teaserad/crmad/pkg/tracer"
@@ -26,6 +30,9 @@ import (
 	"go.uber.org/zap"
 )
 
+// clickTTL is how long click URLs of shown banners are valid
+const clickTTL = 24 * time.Hour
+
 func main() {
 	z, err := zap.NewDevelopment()
 	if err != nil {
@@ -60,7 +67,9 @@ func main() {
 
 	wrappedKafkaProducer := otelsarama.WrapSyncProducer(kafkaCfg, kafkaProducer)
 
-	kafRep := kafkaRepo.New(context.Background(), wrappedKafkaProducer, logger.Named("kafka-producer"))
+	// views are switched to protobuf by KAFKA_CONTENT_TYPE=application/x-protobuf once consumers are ready
+	kafRep := kafkaRepo.New(context.Background(), wrappedKafkaProducer, os.Getenv("KAFKA_CONTENT_TYPE"),
+		logger.Named("kafka-producer"))
 
 	sqlConn, err := sqlx.Connect("mysql",
 		fmt.Sprintf("%s:%s@(%s:%s)/%s",
@@ -83,10 +92,19 @@ func main() {
 		return
 	}
 
+	geoReader := openGeoIP(os.Getenv("GEOIP_DB"), cmdLogger)
+	defer geoReader.Close()
+
+	signKeys, err := clicksign.ParseKeys(os.Getenv("CLICK_SIGN_KEYS"))
+	if err != nil {
+		cmdLogger.Errorw("could not parse click sign keys", "err", err)
+		return
+	}
+
 	platformRepo := mysql.New(sqlConn)
 	tntCluster := clustertnt.New([]*tarantool.Connection{tntConn})
 	tntRepo := tarantoolrepo.New(tntCluster, logger.Named("tarantol-repo"))
-	showService := show.New(tntRepo, platformRepo, kafRep)
+	showService := show.New(tntRepo, platformRepo, kafRep, geoReader, "/adclick/click", clicksign.New(signKeys, clickTTL))
 	kafkaHandler := kafdel.New(showService)
 
 	kafkaConsumer, err := sarama.NewConsumerGroup(kafkaBrokers, "adshow", kafkaCfg)
@@ -95,11 +113,14 @@ func main() {
 		return
 	}
 
-	kafSvc := kafka.New(logger.Named("kafka-consumer"))
+	kafSvc := kafka.New(logger.Named("kafka-consumer"), kafkaProducer)
 
 	kafSvcSess, err := kafSvc.NewConsumer("adshow-consumer", kafkaConsumer, func(sess *kafka.Session) error {
 		sess.AddRoute("adshow.banner.start", kafkaHandler.OnBannerStarted)
 		sess.AddRoute("adshow.banner.stop", kafkaHandler.OnBannerStopped)
+		sess.AddRoute("adshow.banner.throttle", kafkaHandler.OnBannerThrottled)
+		sess.AddRoute("adshow.banner.unthrottle", kafkaHandler.OnBannerUnthrottled)
+		sess.AddRoute("adclick.action.click", kafkaHandler.OnActionClick)
 		return nil
 	})
 	if err != nil {
@@ -136,3 +157,25 @@ func main() {
 		cmdLogger.Errorw("could not stop http-server", "err", err)
 	}
 }
+
+type geoLocator interface {
+	show.GeoLocator
+	io.Closer
+}
+
+// openGeoIP opens MaxMind-format database at the path, without it geo targeted banners are not shown
+// since location of every visitor is unknown
+func openGeoIP(path string, cmdLogger *zap.SugaredLogger) geoLocator {
+	if path == "" {
+		cmdLogger.Warnw("GEOIP_DB is not set, location of visitors is unknown")
+		return geoip.Nop{}
+	}
+
+	reader, err := geoip.Open(path)
+	if err != nil {
+		cmdLogger.Warnw("could not open geoip database, location of visitors is unknown", "path", path, "err", err)
+		return geoip.Nop{}
+	}
+
+	return reader
+}
@@ -2,6 +2,8 @@ package http
 
 import (
 	"context"
+	"crypto/rand"
+	"encoding/hex"
 	"net/http"
 	"strconv"
 
@@ -14,7 +16,7 @@ import (
 type ShowService interface {
 	AddPlatform(ctx context.Context, platform *entity.Platform) error
 	ShowBanners(ctx context.Context, limit int, hitCtx *entity.HitContext) ([]*entity.Banner, error)
-	GetBannersForPlatform(ctx context.Context, platformID int, deviceType string, limit int) ([]*entity.Banner, error)
+	GetBannersForPlatform(ctx context.Context, platformID int, deviceType string, loc entity.Location, limit int) ([]*entity.Banner, error)
 }
 
 type Routes struct {
@@ -33,6 +35,10 @@ type addPlatform struct {
 
 const (
 	tracerName = "http-delivery"
+
+	visitorParam        = "vid"
+	visitorCookie       = "vid"
+	visitorCookieMaxAge = 365 * 24 * 60 * 60
 )
 
 func (r *Routes) GetBanners(c echo.Context) error {
@@ -53,7 +59,12 @@ func (r *Routes) GetBanners(c echo.Context) error {
 
 	d := c.QueryParam("device")
 
-	banners, err := r.showService.GetBannersForPlatform(spanCtx, platformID, d, limit)
+	loc := entity.Location{
+		Country: c.QueryParam("country"),
+		Region:  c.QueryParam("region"),
+	}
+
+	banners, err := r.showService.GetBannersForPlatform(spanCtx, platformID, d, loc, limit)
 	if err != nil {
 		r.logger.Errorw("could not get banners", "err", err, "endpoint", "GetBanners")
 		return c.JSON(http.StatusInternalServerError, HTTPError{"could not get banners"})
@@ -81,6 +92,8 @@ func (r *Routes) ShowBanners(c echo.Context) error {
 	banners, err := r.showService.ShowBanners(spanCtx, limit, &entity.HitContext{
 		UserAgent:  c.Request().UserAgent(),
 		PlatformID: platformID,
+		IP:         c.RealIP(),
+		VisitorID:  r.visitorID(c),
 	})
 	if err != nil {
 		r.logger.Errorw("could not get banners", "err", err, "endpoint", "ShowBanners")
@@ -90,6 +103,35 @@ func (r *Routes) ShowBanners(c echo.Context) error {
 	return c.JSON(http.StatusOK, banners)
 }
 
+// visitorID returns the visitor ID passed by server-to-server calls or stored in the cookie,
+// a new visitor gets the cookie issued
+func (r *Routes) visitorID(c echo.Context) string {
+	if vid := c.QueryParam(visitorParam); vid != "" {
+		return vid
+	}
+
+	if cookie, err := c.Cookie(visitorCookie); err == nil && cookie.Value != "" {
+		return cookie.Value
+	}
+
+	buf := make([]byte, 16)
+	if _, err := rand.Read(buf); err != nil {
+		r.logger.Errorw("could not generate visitor id", "err", err)
+		return ""
+	}
+
+	vid := hex.EncodeToString(buf)
+	c.SetCookie(&http.Cookie{
+		Name:     visitorCookie,
+		Value:    vid,
+		Path:     "/",
+		MaxAge:   visitorCookieMaxAge,
+		HttpOnly: true,
+	})
+
+	return vid
+}
+
 func (r *Routes) AddPlatform(c echo.Context) error {
 	spanCtx, span := otel.Tracer(tracerName).Start(c.Request().Context(), "AddPlatform")
 	defer span.End()
@@ -0,0 +1,57 @@
+package http
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+)
+
+func TestRoutes_VisitorID(t *testing.T) {
+	routes := New(nil, zap.NewNop().Sugar())
+	e := echo.New()
+
+	tests := []struct {
+		name   string
+		target string
+		cookie string
+		want   string
+	}{
+		{name: "query param", target: "/banners?vid=server", cookie: "browser", want: "server"},
+		{name: "cookie", target: "/banners", cookie: "browser", want: "browser"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, tt.target, nil)
+			req.AddCookie(&http.Cookie{Name: visitorCookie, Value: tt.cookie})
+			rec := httptest.NewRecorder()
+
+			assert.Equal(t, tt.want, routes.visitorID(e.NewContext(req, rec)))
+			// known visitor keeps the cookie
+			assert.Empty(t, rec.Result().Cookies())
+		})
+	}
+
+	t.Run("new visitor", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/banners", nil)
+		rec := httptest.NewRecorder()
+
+		vid := routes.visitorID(e.NewContext(req, rec))
+		assert.Len(t, vid, 32)
+
+		cookies := rec.Result().Cookies()
+		if assert.Len(t, cookies, 1) {
+			assert.Equal(t, visitorCookie, cookies[0].Name)
+			assert.Equal(t, vid, cookies[0].Value)
+			assert.True(t, cookies[0].HttpOnly)
+			assert.Equal(t, visitorCookieMaxAge, cookies[0].MaxAge)
+		}
+
+		other := routes.visitorID(e.NewContext(httptest.NewRequest(http.MethodGet, "/banners", nil), httptest.NewRecorder()))
+		assert.NotEqual(t, vid, other)
+	})
+}
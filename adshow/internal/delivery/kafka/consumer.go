@@ -2,10 +2,10 @@ package kafka
 
 import (
 	"context"
-	"encoding/json"
 	"fmt"
 
 	"github.com/Shopify/sarama"
+	kafkapkg "github.com/crxfoz/teaserad/adeliver/pkg/kafka"
 	"github.com/crxfoz/teaserad/adshow/internal/domain/events"
 	"go.opentelemetry.io/otel"
 )
@@ -13,6 +13,9 @@ import (
 type BannerService interface {
 	StartBanner(ctx context.Context, banner *events.BannerStart) error
 	StopBanner(ctx context.Context, bannerID int) error
+	ThrottleBanner(ctx context.Context, bannerID int, probability float64) error
+	UnthrottleBanner(ctx context.Context, bannerID int) error
+	RegisterClick(ctx context.Context, bannerID int) error
 }
 
 type Consumer struct {
@@ -32,7 +35,7 @@ func (c *Consumer) OnBannerStopped(ctx context.Context, msg *sarama.ConsumerMess
 	defer span.End()
 
 	var stopped events.BannerStop
-	if err := json.Unmarshal(msg.Value, &stopped); err != nil {
+	if err := kafkapkg.Decode(msg, &stopped); err != nil {
 		return fmt.Errorf("could not parse message: %w", err)
 	}
 
@@ -44,9 +47,45 @@ func (c *Consumer) OnBannerStarted(ctx context.Context, msg *sarama.ConsumerMess
 	defer span.End()
 
 	var started events.BannerStart
-	if err := json.Unmarshal(msg.Value, &started); err != nil {
+	if err := kafkapkg.Decode(msg, &started); err != nil {
 		return fmt.Errorf("could not parse message: %w", err)
 	}
 
 	return c.bannerSvc.StartBanner(spanCtx, &started)
 }
+
+func (c *Consumer) OnBannerThrottled(ctx context.Context, msg *sarama.ConsumerMessage) error {
+	spanCtx, span := otel.Tracer(tracerName).Start(ctx, "OnBannerThrottled")
+	defer span.End()
+
+	var throttle events.BannerThrottle
+	if err := kafkapkg.Decode(msg, &throttle); err != nil {
+		return fmt.Errorf("could not parse message: %w", err)
+	}
+
+	return c.bannerSvc.ThrottleBanner(spanCtx, throttle.BannerID, throttle.Probability)
+}
+
+func (c *Consumer) OnBannerUnthrottled(ctx context.Context, msg *sarama.ConsumerMessage) error {
+	spanCtx, span := otel.Tracer(tracerName).Start(ctx, "OnBannerUnthrottled")
+	defer span.End()
+
+	var unthrottle events.BannerUnthrottle
+	if err := kafkapkg.Decode(msg, &unthrottle); err != nil {
+		return fmt.Errorf("could not parse message: %w", err)
+	}
+
+	return c.bannerSvc.UnthrottleBanner(spanCtx, unthrottle.BannerID)
+}
+
+func (c *Consumer) OnActionClick(ctx context.Context, msg *sarama.ConsumerMessage) error {
+	spanCtx, span := otel.Tracer(tracerName).Start(ctx, "OnActionClick")
+	defer span.End()
+
+	var click events.Click
+	if err := kafkapkg.Decode(msg, &click); err != nil {
+		return fmt.Errorf("could not parse message: %w", err)
+	}
+
+	return c.bannerSvc.RegisterClick(spanCtx, click.BannerID)
+}
@@ -0,0 +1,64 @@
+package entity
+
+const (
+	// BidCPC is a price of a click, BidCPM is a price of thousand shows
+	BidCPC = "cpc"
+	BidCPM = "cpm"
+
+	// DefaultBid is a price of a click banners had before bids were introduced
+	DefaultBid = 1
+
+	// MinCTR keeps price of a click of CPM banners bounded by their bid
+	MinCTR = 0.001
+
+	// ctrPrior and ctrPriorViews smooth CTR of banners with few views towards the average one
+	ctrPrior      = 0.01
+	ctrPriorViews = 100
+
+	// explorationViews is how many recent views a banner needs to be ranked by its own CTR only
+	explorationViews = 1000
+)
+
+// CTRStat holds recent views and clicks of the banner
+type CTRStat struct {
+	Views  int64 `json:"views"`
+	Clicks int64 `json:"clicks"`
+}
+
+// PredictCTR returns CTR smoothed with the prior one so banners with few views are neither buried nor overrated
+func (s CTRStat) PredictCTR() float64 {
+	ctr := (float64(s.Clicks) + ctrPrior*ctrPriorViews) / (float64(s.Views) + ctrPriorViews)
+	if ctr < MinCTR {
+		return MinCTR
+	}
+
+	if ctr > 1 {
+		return 1
+	}
+
+	return ctr
+}
+
+// IsNew tells whether the banner hasn't been shown enough to trust its CTR
+func (s CTRStat) IsNew() bool {
+	return s.Views < explorationViews
+}
+
+// ExpectedRevenue of a single show of the banner
+func (b *Banner) ExpectedRevenue(ctr float64) float64 {
+	if b.BidType == BidCPM {
+		return b.Bid / 1000
+	}
+
+	return b.Bid * ctr
+}
+
+// ClickPrice returns price of a click the banner wins the auction with, every banner is charged per click
+// so CPM bid is converted to the effective price of a click
+func (b *Banner) ClickPrice(ctr float64) float64 {
+	if b.BidType == BidCPM {
+		return b.Bid / 1000 / ctr
+	}
+
+	return b.Bid
+}
@@ -0,0 +1,29 @@
+package entity
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCTRStat_PredictCTR(t *testing.T) {
+	assert.InDelta(t, ctrPrior, CTRStat{}.PredictCTR(), 0.0001)
+	assert.InDelta(t, 0.05, CTRStat{Views: 100000, Clicks: 5000}.PredictCTR(), 0.001)
+	assert.Equal(t, float64(MinCTR), CTRStat{Views: 1000000}.PredictCTR())
+
+	assert.True(t, CTRStat{Views: 10, Clicks: 10}.IsNew())
+	assert.False(t, CTRStat{Views: explorationViews}.IsNew())
+}
+
+func TestBanner_ClickPrice(t *testing.T) {
+	cpc := &Banner{BidType: BidCPC, Bid: 2}
+	assert.InDelta(t, 0.1, cpc.ExpectedRevenue(0.05), 0.0001)
+	assert.InDelta(t, 2, cpc.ClickPrice(0.05), 0.0001)
+
+	cpm := &Banner{BidType: BidCPM, Bid: 50}
+	assert.InDelta(t, 0.05, cpm.ExpectedRevenue(0.05), 0.0001)
+	assert.InDelta(t, 1, cpm.ClickPrice(0.05), 0.0001)
+
+	// the cheapest CTR makes the click cost the bid itself
+	assert.InDelta(t, 50, cpm.ClickPrice(MinCTR), 0.0001)
+}
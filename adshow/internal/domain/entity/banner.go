@@ -1,11 +1,16 @@
 package entity
 
-import "math/rand"
+import (
+	"math/rand"
+	"time"
+
+	"github.com/crxfoz/teaserad/adshow/pkg/useragent"
+)
 
 const (
-	DeviceDesktop = "desktop"
-	DeviceMobile  = "mobile"
-	DeviceTablet  = "tablet"
+	DeviceDesktop = useragent.DeviceDesktop
+	DeviceMobile  = useragent.DeviceMobile
+	DeviceTablet  = useragent.DeviceTablet
 )
 
 type Banner struct {
@@ -17,6 +22,89 @@ type Banner struct {
 	BannerURL  string `json:"banner_url"`
 	Device     string `json:"device"`
 	CategoryID int    `json:"category_id"`
+	StartAt    int64  `json:"-"`
+	EndAt      int64  `json:"-"`
+	// Schedule holds active hours of each day of the week in TimeZone
+	Schedule [7][]int `json:"-"`
+	TimeZone string   `json:"-"`
+	// Geo holds country and region codes the banner is targeted at
+	Geo []string `json:"-"`
+	// FrequencyCap is how many times the same visitor can be shown the banner within FrequencyPeriod seconds
+	FrequencyCap    int   `json:"-"`
+	FrequencyPeriod int64 `json:"-"`
+	// BidType tells whether Bid is paid for a click or for thousand shows
+	BidType string  `json:"-"`
+	Bid     float64 `json:"-"`
+	// Price of a click the banner won the auction with
+	Price float64 `json:"price"`
+	// ViewID identifies the show of the banner, ClickURL carries it to adclick
+	ViewID   string `json:"view_id"`
+	ClickURL string `json:"click_url"`
+}
+
+// FrequencyCapped tells whether the visitor who has been shown the banner that many times within its frequency period
+// must not be shown it again, banner without frequency cap is shown any number of times
+func (b *Banner) FrequencyCapped(shows int) bool {
+	return b.FrequencyCap > 0 && shows >= b.FrequencyCap
+}
+
+// MatchesGeo tells whether the banner is targeted at the location, banner without geo targeting matches any
+func (b *Banner) MatchesGeo(loc Location) bool {
+	if len(b.Geo) == 0 {
+		return true
+	}
+
+	for _, code := range b.Geo {
+		if loc.Country != "" && code == loc.Country {
+			return true
+		}
+
+		if loc.Region != "" && code == loc.Region {
+			return true
+		}
+	}
+
+	return false
+}
+
+// IsActiveAt tells whether the banner is within its flight and schedule, crmad stops banners
+// outside of them on its own so this is a safety net against delayed events
+func (b *Banner) IsActiveAt(now time.Time) bool {
+	if b.StartAt != 0 && now.Unix() < b.StartAt {
+		return false
+	}
+
+	if b.EndAt != 0 && now.Unix() >= b.EndAt {
+		return false
+	}
+
+	loc, err := time.LoadLocation(b.TimeZone)
+	if err != nil {
+		loc = time.UTC
+	}
+
+	local := now.In(loc)
+
+	var scheduled bool
+	for day, hours := range b.Schedule {
+		if len(hours) == 0 {
+			continue
+		}
+
+		scheduled = true
+
+		if time.Weekday(day) != local.Weekday() {
+			continue
+		}
+
+		for _, hour := range hours {
+			if hour == local.Hour() {
+				return true
+			}
+		}
+	}
+
+	return !scheduled
 }
 
 type Platform struct {
@@ -40,12 +128,20 @@ func (p Platforms) Ids() []int {
 	return out
 }
 
+// Location of the client resolved by its IP, empty one is unknown
+type Location struct {
+	Country string `json:"country"`
+	Region  string `json:"region"`
+}
+
 type HitContext struct {
 	UserAgent  string `json:"user_agent"`
 	PlatformID int    `json:"platform_id"`
+	IP         string `json:"ip"`
+	VisitorID  string `json:"visitor_id"`
 }
 
-func (hc HitContext) DeviceType() string {
-	// TODO: device type
-	return DeviceDesktop
+// Agent parses User-Agent of the hit, device types are the same as banners are targeted at
+func (hc HitContext) Agent() useragent.Agent {
+	return useragent.Parse(hc.UserAgent)
 }
@@ -0,0 +1,29 @@
+package entity
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBanner_MatchesGeo(t *testing.T) {
+	banner := &Banner{Geo: []string{"BY", "RU-MOW"}}
+
+	assert.True(t, banner.MatchesGeo(Location{Country: "BY", Region: "BY-HM"}))
+	assert.True(t, banner.MatchesGeo(Location{Country: "RU", Region: "RU-MOW"}))
+	assert.False(t, banner.MatchesGeo(Location{Country: "RU", Region: "RU-SPE"}))
+	assert.False(t, banner.MatchesGeo(Location{}))
+
+	assert.True(t, (&Banner{}).MatchesGeo(Location{}))
+}
+
+func TestBanner_FrequencyCapped(t *testing.T) {
+	banner := &Banner{FrequencyCap: 2, FrequencyPeriod: 3600}
+
+	assert.False(t, banner.FrequencyCapped(0))
+	assert.False(t, banner.FrequencyCapped(1))
+	assert.True(t, banner.FrequencyCapped(2))
+	assert.True(t, banner.FrequencyCapped(3))
+
+	assert.False(t, (&Banner{}).FrequencyCapped(100))
+}
@@ -2,14 +2,13 @@ package kafka
 
 import (
 	"context"
-	"encoding/json"
 	"fmt"
 	"sync"
 
 	"github.com/Shopify/sarama"
+	kafkapkg "github.com/crxfoz/teaserad/adeliver/pkg/kafka"
 	"github.com/crxfoz/teaserad/adshow/internal/domain"
 	"github.com/crxfoz/teaserad/adshow/internal/domain/events"
-	"go.opentelemetry.io/contrib/instrumentation/github.com/Shopify/sarama/otelsarama"
 	"go.opentelemetry.io/otel"
 )
 
@@ -20,20 +19,21 @@ const (
 type Producer struct {
 	ctx      context.Context
 	cancelFn func()
-	conn     sarama.SyncProducer
+	producer *kafkapkg.Producer
 
 	queue  chan *ViewsCtx
 	wg     *sync.WaitGroup
 	logger domain.Logger
 }
 
-func New(ctx context.Context, conn sarama.SyncProducer, logger domain.Logger) *Producer {
+// New sends views encoded with the content type, empty one means JSON
+func New(ctx context.Context, conn sarama.SyncProducer, contentType string, logger domain.Logger) *Producer {
 	newCtx, cancelFn := context.WithCancel(ctx)
 
 	p := &Producer{
 		ctx:      newCtx,
 		cancelFn: cancelFn,
-		conn:     conn,
+		producer: kafkapkg.NewProducer(conn, "adshow", kafkapkg.WithContentType(contentType)),
 		queue:    make(chan *ViewsCtx, 10),
 		wg:       &sync.WaitGroup{},
 		logger:   logger,
@@ -75,22 +75,8 @@ func (p *Producer) addView(ctx context.Context, view *events.View) error {
 	spanCtx, span := otel.Tracer(tracerName).Start(ctx, "addView")
 	defer span.End()
 
-	out, err := json.Marshal(view)
-	if err != nil {
-		return fmt.Errorf("could not marshal msg: %w", err)
-	}
-
-	pitem := &sarama.ProducerMessage{
-		Topic: topicShow,
-		Key:   sarama.StringEncoder("1"), // TODO: use different keys
-		Value: sarama.ByteEncoder(out),
-	}
-
-	otel.GetTextMapPropagator().Inject(spanCtx, otelsarama.NewProducerMessageCarrier(pitem))
-
-	_, _, err = p.conn.SendMessage(pitem)
-	if err != nil {
-		return fmt.Errorf("could not send message: %w", err)
+	if err := p.producer.Publish(spanCtx, topicShow, view); err != nil {
+		return fmt.Errorf("could not publish event: %w", err)
 	}
 
 	return nil
@@ -2,8 +2,11 @@ package tarantool
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
+	"hash/fnv"
 	"math/rand"
+	"strings"
 	"time"
 
 	"github.com/crxfoz/teaserad/adshow/internal/domain"
@@ -27,6 +30,9 @@ type ShowRepo struct {
 
 const (
 	tracerName = "db-tarantool"
+
+	// ctrWindow is how many recent hours CTR of banners is calculated over
+	ctrWindow = 24
 )
 
 func New(cluster Cluster, logger domain.Logger) *ShowRepo {
@@ -38,9 +44,15 @@ func (r *ShowRepo) AddBanner(ctx context.Context, start *events.BannerStart, toP
 	_, span := otel.Tracer(tracerName).Start(ctx, "AddBanner")
 	defer span.End()
 
+	schedule, err := json.Marshal(start.Schedule)
+	if err != nil {
+		return fmt.Errorf("could not marshal schedule: %w", err)
+	}
+
+	// restarted banner replaces its previous tuple, e.g. when its creative has been edited
 	for _, platformID := range toPlatforms {
 		conn := r.cluster.Node(platformID)
-		_, err := conn.Insert("platforms", []interface{}{
+		_, err := conn.Replace("platforms", []interface{}{
 			platformID,
 			start.Device,
 			start.BannerID,
@@ -48,7 +60,16 @@ func (r *ShowRepo) AddBanner(ctx context.Context, start *events.BannerStart, toP
 			start.BannerText,
 			start.CategoryID,
 			start.ImgData,
-			start.UserID})
+			start.UserID,
+			start.StartAt,
+			start.EndAt,
+			string(schedule),
+			start.TimeZone,
+			strings.Join(start.Geo, ","),
+			start.FrequencyCap,
+			start.FrequencyPeriod,
+			start.BidType,
+			start.Bid})
 		if err != nil {
 			r.logger.Errorw("could not insert banner", "err", err, "platformID", platformID)
 		}
@@ -59,11 +80,12 @@ func (r *ShowRepo) AddBanner(ctx context.Context, start *events.BannerStart, toP
 }
 
 func (r *ShowRepo) scanTuple(tuple []interface{}) (*entity.Banner, error) {
-	if len(tuple) != 8 {
+	// banners added before flight, schedule, geo, frequency cap and bid were introduced have less fields
+	if len(tuple) != 8 && len(tuple) != 12 && len(tuple) != 13 && len(tuple) != 15 && len(tuple) != 17 {
 		return nil, fmt.Errorf("wrong len: %d", len(tuple))
 	}
 
-	banner := new(entity.Banner)
+	banner := &entity.Banner{BidType: entity.BidCPC, Bid: entity.DefaultBid}
 
 	pid, ok := tuple[0].(uint64)
 	if !ok {
@@ -113,9 +135,110 @@ func (r *ShowRepo) scanTuple(tuple []interface{}) (*entity.Banner, error) {
 	}
 	banner.UserID = int(uid)
 
+	if len(tuple) == 8 {
+		return banner, nil
+	}
+
+	startAt, ok := r.toInt64(tuple[8])
+	if !ok {
+		return nil, fmt.Errorf("could not parse StartAt")
+	}
+	banner.StartAt = startAt
+
+	endAt, ok := r.toInt64(tuple[9])
+	if !ok {
+		return nil, fmt.Errorf("could not parse EndAt")
+	}
+	banner.EndAt = endAt
+
+	schedule, ok := tuple[10].(string)
+	if !ok {
+		return nil, fmt.Errorf("could not parse Schedule")
+	}
+
+	if err := json.Unmarshal([]byte(schedule), &banner.Schedule); err != nil {
+		return nil, fmt.Errorf("could not parse Schedule: %w", err)
+	}
+
+	tz, ok := tuple[11].(string)
+	if !ok {
+		return nil, fmt.Errorf("could not parse TimeZone")
+	}
+	banner.TimeZone = tz
+
+	if len(tuple) == 12 {
+		return banner, nil
+	}
+
+	geo, ok := tuple[12].(string)
+	if !ok {
+		return nil, fmt.Errorf("could not parse Geo")
+	}
+
+	if geo != "" {
+		banner.Geo = strings.Split(geo, ",")
+	}
+
+	if len(tuple) == 13 {
+		return banner, nil
+	}
+
+	freqCap, ok := r.toInt64(tuple[13])
+	if !ok {
+		return nil, fmt.Errorf("could not parse FrequencyCap")
+	}
+	banner.FrequencyCap = int(freqCap)
+
+	freqPeriod, ok := r.toInt64(tuple[14])
+	if !ok {
+		return nil, fmt.Errorf("could not parse FrequencyPeriod")
+	}
+	banner.FrequencyPeriod = freqPeriod
+
+	if len(tuple) == 15 {
+		return banner, nil
+	}
+
+	bidType, ok := tuple[15].(string)
+	if !ok {
+		return nil, fmt.Errorf("could not parse BidType")
+	}
+	banner.BidType = bidType
+
+	bidValue, ok := r.toFloat64(tuple[16])
+	if !ok {
+		return nil, fmt.Errorf("could not parse Bid")
+	}
+	banner.Bid = bidValue
+
 	return banner, nil
 }
 
+// toInt64 handles msgpack decoding positive numbers as unsigned and negative ones as signed
+func (r *ShowRepo) toInt64(v interface{}) (int64, bool) {
+	switch n := v.(type) {
+	case uint64:
+		return int64(n), true
+	case int64:
+		return n, true
+	default:
+		return 0, false
+	}
+}
+
+// toFloat64 handles msgpack decoding whole numbers as integers
+func (r *ShowRepo) toFloat64(v interface{}) (float64, bool) {
+	switch n := v.(type) {
+	case float64:
+		return n, true
+	case float32:
+		return float64(n), true
+	default:
+		i, ok := r.toInt64(v)
+		return float64(i), ok
+	}
+}
+
 func (r *ShowRepo) bannerToPrimaryIndex(banner *entity.Banner) []interface{} {
 	return []interface{}{banner.PlatformID, banner.Device, banner.BannerID}
 }
@@ -169,13 +292,15 @@ func (r *ShowRepo) selectLen(actualLen int, desiredLen int) int {
 	return desiredLen
 }
 
-func (r *ShowRepo) BannersForPlatform(ctx context.Context, platformID int, deviceType string, limit int) ([]*entity.Banner, error) {
+func (r *ShowRepo) BannersForPlatform(ctx context.Context, platformID int, deviceType string, loc entity.Location, limit int) ([]*entity.Banner, error) {
 	_, span := otel.Tracer(tracerName).Start(ctx, "BannersForPlatform")
 	defer span.End()
 
 	conn := r.cluster.Node(platformID)
 	var data []*entity.Banner
 
+	now := time.Now()
+
 	for limit, offset := 1000, 0; ; limit, offset = limit+1000, offset+1000 {
 		resp, err := conn.Select("platforms", "primary", uint32(offset), uint32(limit), tarantool.IterEq, []interface{}{platformID, deviceType})
 		if err != nil {
@@ -187,7 +312,7 @@ func (r *ShowRepo) BannersForPlatform(ctx context.Context, platformID int, devic
 		}
 
 		for _, item := range resp.Tuples() {
-			if v, err := r.scanTuple(item); err == nil {
+			if v, err := r.scanTuple(item); err == nil && v.IsActiveAt(now) && v.MatchesGeo(loc) {
 				data = append(data, v)
 			}
 		}
@@ -199,3 +324,238 @@ func (r *ShowRepo) BannersForPlatform(ctx context.Context, platformID int, devic
 
 	return data[:r.selectLen(len(data), limit)], nil
 }
+
+func (r *ShowRepo) SetThrottle(ctx context.Context, bannerID int, probability float64) error {
+	_, span := otel.Tracer(tracerName).Start(ctx, "SetThrottle")
+	defer span.End()
+
+	conn := r.cluster.Node(bannerID)
+	if _, err := conn.Replace("throttles", []interface{}{bannerID, probability}); err != nil {
+		return fmt.Errorf("could not replace throttle: %w", err)
+	}
+
+	return nil
+}
+
+func (r *ShowRepo) DeleteThrottle(ctx context.Context, bannerID int) error {
+	_, span := otel.Tracer(tracerName).Start(ctx, "DeleteThrottle")
+	defer span.End()
+
+	conn := r.cluster.Node(bannerID)
+	if _, err := conn.Delete("throttles", "primary", []interface{}{bannerID}); err != nil {
+		return fmt.Errorf("could not delete throttle: %w", err)
+	}
+
+	return nil
+}
+
+// byNode groups banners by the node they are stored on, so each node is queried once
+func (r *ShowRepo) byNode(bannerIDs []int) map[*tarantool.Connection][]int {
+	out := make(map[*tarantool.Connection][]int)
+	for _, bannerID := range bannerIDs {
+		conn := r.cluster.Node(bannerID)
+		out[conn] = append(out[conn], bannerID)
+	}
+
+	return out
+}
+
+// callTuples calls the stored function which returns a table of tuples
+func (r *ShowRepo) callTuples(conn *tarantool.Connection, function string, args []interface{}) ([][]interface{}, error) {
+	resp, err := conn.Call17(function, args)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(resp.Data) == 0 {
+		return nil, nil
+	}
+
+	items, ok := resp.Data[0].([]interface{})
+	if !ok {
+		return nil, fmt.Errorf("unexpected result of %s: %T", function, resp.Data[0])
+	}
+
+	out := make([][]interface{}, 0, len(items))
+	for _, item := range items {
+		tuple, ok := item.([]interface{})
+		if !ok {
+			return nil, fmt.Errorf("unexpected tuple of %s: %T", function, item)
+		}
+
+		out = append(out, tuple)
+	}
+
+	return out, nil
+}
+
+// GetThrottles returns serving probability of throttled banners, banners which are not throttled are omitted
+func (r *ShowRepo) GetThrottles(ctx context.Context, bannerIDs []int) (map[int]float64, error) {
+	_, span := otel.Tracer(tracerName).Start(ctx, "GetThrottles")
+	defer span.End()
+
+	out := make(map[int]float64)
+
+	for conn, ids := range r.byNode(bannerIDs) {
+		tuples, err := r.callTuples(conn, "throttles_get", []interface{}{ids})
+		if err != nil {
+			return nil, fmt.Errorf("could not get throttles: %w", err)
+		}
+
+		for _, tuple := range tuples {
+			if len(tuple) != 2 {
+				continue
+			}
+
+			bannerID, okBanner := r.toInt64(tuple[0])
+			probability, okProbability := r.toFloat64(tuple[1])
+			if !okBanner || !okProbability {
+				r.logger.Errorw("could not parse throttle", "tuple", tuple)
+				continue
+			}
+
+			out[int(bannerID)] = probability
+		}
+	}
+
+	return out, nil
+}
+
+// visitorNode keeps all counters of the visitor on the same node
+func (r *ShowRepo) visitorNode(visitorID string) *tarantool.Connection {
+	h := fnv.New32a()
+	_, _ = h.Write([]byte(visitorID))
+
+	return r.cluster.Node(int(h.Sum32()))
+}
+
+// GetFrequency returns how many times the visitor has been shown each banner within its frequency period
+func (r *ShowRepo) GetFrequency(ctx context.Context, visitorID string) (map[int]int, error) {
+	_, span := otel.Tracer(tracerName).Start(ctx, "GetFrequency")
+	defer span.End()
+
+	conn := r.visitorNode(visitorID)
+
+	resp, err := conn.Select("frequency", "primary", 0, 1000, tarantool.IterEq, []interface{}{visitorID})
+	if err != nil {
+		return nil, fmt.Errorf("could not select frequency: %w", err)
+	}
+
+	now := time.Now().Unix()
+	out := make(map[int]int)
+
+	for _, tuple := range resp.Tuples() {
+		if len(tuple) != 4 {
+			continue
+		}
+
+		bannerID, okBanner := r.toInt64(tuple[1])
+		shows, okShows := r.toInt64(tuple[2])
+		expireAt, okExpire := r.toInt64(tuple[3])
+		if !okBanner || !okShows || !okExpire {
+			r.logger.Errorw("could not parse frequency", "visitorID", visitorID)
+			continue
+		}
+
+		// expired counters are removed by tarantool periodically
+		if expireAt <= now {
+			continue
+		}
+
+		out[int(bannerID)] = int(shows)
+	}
+
+	return out, nil
+}
+
+// AddFrequency counts shows of banners with frequency cap to the visitor
+func (r *ShowRepo) AddFrequency(ctx context.Context, visitorID string, banners []*entity.Banner) error {
+	_, span := otel.Tracer(tracerName).Start(ctx, "AddFrequency")
+	defer span.End()
+
+	conn := r.visitorNode(visitorID)
+	now := time.Now().Unix()
+
+	for _, banner := range banners {
+		if banner.FrequencyCap == 0 {
+			continue
+		}
+
+		if _, err := conn.Call17("frequency_hit", []interface{}{visitorID, banner.BannerID, banner.FrequencyPeriod, now}); err != nil {
+			return fmt.Errorf("could not count frequency: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// ctrBucket returns the hour views and clicks are counted in
+func (r *ShowRepo) ctrBucket(now time.Time) int64 {
+	return now.Unix() / int64(time.Hour/time.Second)
+}
+
+func (r *ShowRepo) addCTR(conn *tarantool.Connection, bannerID int, views int64, clicks int64) error {
+	_, err := conn.Upsert("ctr",
+		[]interface{}{bannerID, r.ctrBucket(time.Now()), views, clicks},
+		[]interface{}{[]interface{}{"+", 2, views}, []interface{}{"+", 3, clicks}})
+
+	return err
+}
+
+// GetCTRStats sums views and clicks of the banners within the last ctrWindow hours
+func (r *ShowRepo) GetCTRStats(ctx context.Context, bannerIDs []int) (map[int]entity.CTRStat, error) {
+	_, span := otel.Tracer(tracerName).Start(ctx, "GetCTRStats")
+	defer span.End()
+
+	since := r.ctrBucket(time.Now()) - ctrWindow
+	out := make(map[int]entity.CTRStat)
+
+	for conn, ids := range r.byNode(bannerIDs) {
+		tuples, err := r.callTuples(conn, "ctr_stats", []interface{}{ids, since})
+		if err != nil {
+			return nil, fmt.Errorf("could not get ctr: %w", err)
+		}
+
+		for _, tuple := range tuples {
+			if len(tuple) != 3 {
+				continue
+			}
+
+			bannerID, okBanner := r.toInt64(tuple[0])
+			views, okViews := r.toInt64(tuple[1])
+			clicks, okClicks := r.toInt64(tuple[2])
+			if !okBanner || !okViews || !okClicks {
+				r.logger.Errorw("could not parse ctr", "tuple", tuple)
+				continue
+			}
+
+			out[int(bannerID)] = entity.CTRStat{Views: views, Clicks: clicks}
+		}
+	}
+
+	return out, nil
+}
+
+func (r *ShowRepo) AddCTRViews(ctx context.Context, banners []*entity.Banner) error {
+	_, span := otel.Tracer(tracerName).Start(ctx, "AddCTRViews")
+	defer span.End()
+
+	for _, banner := range banners {
+		if err := r.addCTR(r.cluster.Node(banner.BannerID), banner.BannerID, 1, 0); err != nil {
+			return fmt.Errorf("could not count view: %w", err)
+		}
+	}
+
+	return nil
+}
+
+func (r *ShowRepo) AddCTRClick(ctx context.Context, bannerID int) error {
+	_, span := otel.Tracer(tracerName).Start(ctx, "AddCTRClick")
+	defer span.End()
+
+	if err := r.addCTR(r.cluster.Node(bannerID), bannerID, 0, 1); err != nil {
+		return fmt.Errorf("could not count click: %w", err)
+	}
+
+	return nil
+}
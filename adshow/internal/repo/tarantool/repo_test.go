@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"testing"
 
+	"github.com/crxfoz/teaserad/adshow/internal/domain/entity"
 	"github.com/crxfoz/teaserad/adshow/internal/domain/events"
 	clustertnt "github.com/crxfoz/teaserad/adshow/pkg/tarantool"
 	"github.com/stretchr/testify/assert"
@@ -72,7 +73,7 @@ func TestShowRepo_BannersForPlatform(t *testing.T) {
 
 	repo := New(cluster, logger)
 
-	banners, err := repo.BannersForPlatform(context.Background(), 2, "desktop", 10)
+	banners, err := repo.BannersForPlatform(context.Background(), 2, "desktop", entity.Location{}, 10)
 	assert.Nil(t, err)
 
 	for _, banner := range banners {
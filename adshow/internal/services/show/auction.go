@@ -0,0 +1,69 @@
+package show
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"sort"
+
+	"github.com/crxfoz/teaserad/adshow/internal/domain/entity"
+)
+
+// explorationShare is a share of slots given to banners without enough views to know their CTR
+const explorationShare = 0.1
+
+// rank orders banners by expected revenue of a show and sets prices of a click they win with,
+// some slots are given to random new banners so they gather views to get their CTR known
+func (s *ShowService) rank(ctx context.Context, banners []*entity.Banner) ([]*entity.Banner, error) {
+	ids := make([]int, 0, len(banners))
+	for _, item := range banners {
+		ids = append(ids, item.BannerID)
+	}
+
+	stats, err := s.repo.GetCTRStats(ctx, ids)
+	if err != nil {
+		return nil, fmt.Errorf("could not get ctr stats: %w", err)
+	}
+
+	revenue := make(map[int]float64, len(banners))
+	for _, item := range banners {
+		ctr := stats[item.BannerID].PredictCTR()
+		revenue[item.BannerID] = item.ExpectedRevenue(ctr)
+		item.Price = item.ClickPrice(ctr)
+	}
+
+	// candidates come shuffled so banners with the same revenue are shown in turn
+	sort.SliceStable(banners, func(i, j int) bool {
+		return revenue[banners[i].BannerID] > revenue[banners[j].BannerID]
+	})
+
+	var fresh []*entity.Banner
+	for _, item := range banners {
+		if stats[item.BannerID].IsNew() {
+			fresh = append(fresh, item)
+		}
+	}
+
+	out := make([]*entity.Banner, 0, len(banners))
+	taken := make(map[int]bool, len(banners))
+
+	for _, item := range banners {
+		if len(fresh) > 0 && rand.Float64() < explorationShare {
+			idx := rand.Intn(len(fresh))
+			explored := fresh[idx]
+			fresh = append(fresh[:idx], fresh[idx+1:]...)
+
+			if !taken[explored.BannerID] {
+				taken[explored.BannerID] = true
+				out = append(out, explored)
+			}
+		}
+
+		if !taken[item.BannerID] {
+			taken[item.BannerID] = true
+			out = append(out, item)
+		}
+	}
+
+	return out, nil
+}
@@ -0,0 +1,49 @@
+package show
+
+import (
+	"context"
+	"testing"
+
+	"github.com/crxfoz/teaserad/adshow/internal/domain/entity"
+	"github.com/stretchr/testify/assert"
+)
+
+type memoryShowRepo struct {
+	ShowRepo
+	frequency map[string]map[int]int
+}
+
+func (m *memoryShowRepo) GetFrequency(_ context.Context, visitorID string) (map[int]int, error) {
+	return m.frequency[visitorID], nil
+}
+
+func TestShowService_ApplyFrequency(t *testing.T) {
+	repo := &memoryShowRepo{frequency: map[string]map[int]int{"visitor": {1: 3, 2: 1}}}
+	service := &ShowService{repo: repo}
+	ctx := context.Background()
+
+	banners := []*entity.Banner{
+		{BannerID: 1, FrequencyCap: 3},
+		{BannerID: 2, FrequencyCap: 3},
+		{BannerID: 3, FrequencyCap: 1},
+		{BannerID: 4},
+	}
+
+	ids := func(banners []*entity.Banner) []int {
+		var out []int
+		for _, item := range banners {
+			out = append(out, item.BannerID)
+		}
+
+		return out
+	}
+
+	out, err := service.applyFrequency(ctx, banners, "visitor")
+	assert.NoError(t, err)
+	assert.Equal(t, []int{2, 3, 4}, ids(out))
+
+	// visitor without id can't be counted, so caps are not applied
+	out, err = service.applyFrequency(ctx, banners, "")
+	assert.NoError(t, err)
+	assert.Equal(t, []int{1, 2, 3, 4}, ids(out))
+}
@@ -4,17 +4,28 @@ import (
 	"context"
 	"fmt"
 	"math/rand"
+	"net/url"
 	"time"
 
+	"github.com/crxfoz/teaserad/adclick/pkg/clicksign"
 	"github.com/crxfoz/teaserad/adshow/internal/domain/entity"
 	"github.com/crxfoz/teaserad/adshow/internal/domain/events"
+	"github.com/google/uuid"
 	"go.opentelemetry.io/otel"
 )
 
 type ShowRepo interface {
 	AddBanner(ctx context.Context, start *events.BannerStart, toPlatforms []int) error
 	DeleteBannerAll(ctx context.Context, bannerID int) error
-	BannersForPlatform(ctx context.Context, platformID int, deviceType string, limit int) ([]*entity.Banner, error)
+	BannersForPlatform(ctx context.Context, platformID int, deviceType string, loc entity.Location, limit int) ([]*entity.Banner, error)
+	SetThrottle(ctx context.Context, bannerID int, probability float64) error
+	DeleteThrottle(ctx context.Context, bannerID int) error
+	GetThrottles(ctx context.Context, bannerIDs []int) (map[int]float64, error)
+	GetFrequency(ctx context.Context, visitorID string) (map[int]int, error)
+	AddFrequency(ctx context.Context, visitorID string, banners []*entity.Banner) error
+	GetCTRStats(ctx context.Context, bannerIDs []int) (map[int]entity.CTRStat, error)
+	AddCTRViews(ctx context.Context, banners []*entity.Banner) error
+	AddCTRClick(ctx context.Context, bannerID int) error
 }
 
 type PlatformRepo interface {
@@ -25,6 +36,16 @@ type PlatformRepo interface {
 	DeletePlatform(ctx context.Context, platformID int) error
 }
 
+// GeoLocator resolves country and region codes of IP address
+type GeoLocator interface {
+	Lookup(ip string) (string, string, error)
+}
+
+// ClickSigner signs click URLs so adclick can tell forged clicks
+type ClickSigner interface {
+	Query(click clicksign.Click) url.Values
+}
+
 type ShowNotifier interface {
 	AddViews(context.Context, []*events.View) error
 }
@@ -33,16 +54,24 @@ type ShowService struct {
 	repo         ShowRepo
 	platformRepo PlatformRepo
 	showNotifier ShowNotifier
+	geo          GeoLocator
+	clickURL     string
+	signer       ClickSigner
 }
 
 const (
 	tracerName = "usecase"
+
+	// auctionCandidates is how many random banners of the platform take part in the auction,
+	// their throttles and CTR are fetched within the request
+	auctionCandidates = 50
 )
 
-func New(repo ShowRepo, platformRepo PlatformRepo, showNotifier ShowNotifier) *ShowService {
+// New creates ShowService, clickURL is address of adclick the click URLs of shown banners point to
+func New(repo ShowRepo, platformRepo PlatformRepo, showNotifier ShowNotifier, geo GeoLocator, clickURL string, signer ClickSigner) *ShowService {
 	rand.Seed(time.Now().UnixNano())
 
-	return &ShowService{repo: repo, platformRepo: platformRepo, showNotifier: showNotifier}
+	return &ShowService{repo: repo, platformRepo: platformRepo, showNotifier: showNotifier, geo: geo, clickURL: clickURL, signer: signer}
 }
 
 func (s *ShowService) StartBanner(ctx context.Context, banner *events.BannerStart) error {
@@ -71,9 +100,98 @@ func (s *ShowService) StopBanner(ctx context.Context, bannerID int) error {
 		return fmt.Errorf("could not stop banner: %w", err)
 	}
 
+	if err := s.repo.DeleteThrottle(spanCtx, bannerID); err != nil {
+		return fmt.Errorf("could not delete throttle: %w", err)
+	}
+
 	return nil
 }
 
+func (s *ShowService) ThrottleBanner(ctx context.Context, bannerID int, probability float64) error {
+	spanCtx, span := otel.Tracer(tracerName).Start(ctx, "ThrottleBanner")
+	defer span.End()
+
+	if err := s.repo.SetThrottle(spanCtx, bannerID, probability); err != nil {
+		return fmt.Errorf("could not throttle banner: %w", err)
+	}
+
+	return nil
+}
+
+func (s *ShowService) UnthrottleBanner(ctx context.Context, bannerID int) error {
+	spanCtx, span := otel.Tracer(tracerName).Start(ctx, "UnthrottleBanner")
+	defer span.End()
+
+	if err := s.repo.DeleteThrottle(spanCtx, bannerID); err != nil {
+		return fmt.Errorf("could not unthrottle banner: %w", err)
+	}
+
+	return nil
+}
+
+// RegisterClick counts the click to CTR the banner is ranked with
+func (s *ShowService) RegisterClick(ctx context.Context, bannerID int) error {
+	spanCtx, span := otel.Tracer(tracerName).Start(ctx, "RegisterClick")
+	defer span.End()
+
+	if err := s.repo.AddCTRClick(spanCtx, bannerID); err != nil {
+		return fmt.Errorf("could not register click: %w", err)
+	}
+
+	return nil
+}
+
+// applyFrequency drops banners the visitor has been shown as many times as their frequency cap allows
+func (s *ShowService) applyFrequency(ctx context.Context, banners []*entity.Banner, visitorID string) ([]*entity.Banner, error) {
+	if visitorID == "" {
+		return banners, nil
+	}
+
+	shows, err := s.repo.GetFrequency(ctx, visitorID)
+	if err != nil {
+		return nil, fmt.Errorf("could not get frequency: %w", err)
+	}
+
+	out := make([]*entity.Banner, 0, len(banners))
+	for _, item := range banners {
+		if item.FrequencyCapped(shows[item.BannerID]) {
+			continue
+		}
+
+		out = append(out, item)
+	}
+
+	return out, nil
+}
+
+// applyThrottles drops throttled banners with respect to their serving probability
+func (s *ShowService) applyThrottles(ctx context.Context, banners []*entity.Banner, limit int) ([]*entity.Banner, error) {
+	ids := make([]int, 0, len(banners))
+	for _, item := range banners {
+		ids = append(ids, item.BannerID)
+	}
+
+	throttles, err := s.repo.GetThrottles(ctx, ids)
+	if err != nil {
+		return nil, fmt.Errorf("could not get throttles: %w", err)
+	}
+
+	out := make([]*entity.Banner, 0, limit)
+	for _, item := range banners {
+		if len(out) == limit {
+			break
+		}
+
+		if probability, ok := throttles[item.BannerID]; ok && rand.Float64() >= probability {
+			continue
+		}
+
+		out = append(out, item)
+	}
+
+	return out, nil
+}
+
 func (s *ShowService) AddPlatform(ctx context.Context, platform *entity.Platform) error {
 	spanCtx, span := otel.Tracer(tracerName).Start(ctx, "AddPlatform")
 	defer span.End()
@@ -85,11 +203,11 @@ func (s *ShowService) AddPlatform(ctx context.Context, platform *entity.Platform
 	return nil
 }
 
-func (s *ShowService) GetBannersForPlatform(ctx context.Context, platformID int, deviceType string, limit int) ([]*entity.Banner, error) {
+func (s *ShowService) GetBannersForPlatform(ctx context.Context, platformID int, deviceType string, loc entity.Location, limit int) ([]*entity.Banner, error) {
 	spanCtx, span := otel.Tracer(tracerName).Start(ctx, "GetBannersForPlatform")
 	defer span.End()
 
-	banners, err := s.repo.BannersForPlatform(spanCtx, platformID, deviceType, limit)
+	banners, err := s.repo.BannersForPlatform(spanCtx, platformID, deviceType, loc, limit)
 	if err != nil {
 		return nil, fmt.Errorf("could not get banners: %w", err)
 	}
@@ -101,21 +219,56 @@ func (s *ShowService) ShowBanners(ctx context.Context, limit int, hitCtx *entity
 	spanCtx, span := otel.Tracer(tracerName).Start(ctx, "ShowBanners")
 	defer span.End()
 
-	deviceType := hitCtx.DeviceType()
+	agent := hitCtx.Agent()
+	loc := s.locate(hitCtx.IP)
 
-	banners, err := s.repo.BannersForPlatform(spanCtx, hitCtx.PlatformID, deviceType, limit)
+	banners, err := s.repo.BannersForPlatform(spanCtx, hitCtx.PlatformID, agent.Device, loc, auctionCandidates)
 	if err != nil {
 		return nil, fmt.Errorf("could not get banners: %w", err)
 	}
 
+	banners, err = s.applyFrequency(spanCtx, banners, hitCtx.VisitorID)
+	if err != nil {
+		return nil, err
+	}
+
+	banners, err = s.rank(spanCtx, banners)
+	if err != nil {
+		return nil, err
+	}
+
+	banners, err = s.applyThrottles(spanCtx, banners, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.repo.AddCTRViews(spanCtx, banners); err != nil {
+		return nil, fmt.Errorf("could not count views: %w", err)
+	}
+
+	if hitCtx.VisitorID != "" {
+		if err := s.repo.AddFrequency(spanCtx, hitCtx.VisitorID, banners); err != nil {
+			return nil, fmt.Errorf("could not count frequency: %w", err)
+		}
+	}
+
+	now := time.Now()
 	views := make([]*events.View, 0, len(banners))
 	for _, item := range banners {
+		item.ViewID = uuid.NewString()
+		item.ClickURL = s.buildClickURL(item, hitCtx.PlatformID, now)
+
 		views = append(views, &events.View{
+			ViewID:     item.ViewID,
 			BannerID:   item.BannerID,
 			PlatformID: hitCtx.PlatformID,
 			UserAgent:  hitCtx.UserAgent,
-			Device:     deviceType,
-			CreatedAt:  time.Now().UTC().Unix(),
+			Device:     agent.Device,
+			OS:         agent.OS,
+			Browser:    agent.Browser,
+			Country:    loc.Country,
+			Region:     loc.Region,
+			CreatedAt:  now.UTC().Unix(),
 		})
 	}
 
@@ -125,3 +278,28 @@ func (s *ShowService) ShowBanners(ctx context.Context, limit int, hitCtx *entity
 
 	return banners, nil
 }
+
+// buildClickURL links the click to the show and carries the price the banner won the auction with,
+// the URL is signed so none of them can be forged
+func (s *ShowService) buildClickURL(banner *entity.Banner, platformID int, now time.Time) string {
+	query := s.signer.Query(clicksign.Click{
+		BannerID:   banner.BannerID,
+		PlatformID: platformID,
+		ViewID:     banner.ViewID,
+		Price:      banner.Price,
+		Timestamp:  now.Unix(),
+	})
+
+	return s.clickURL + "?" + query.Encode()
+}
+
+// locate treats clients which couldn't be resolved as ones from unknown location,
+// they're shown only banners without geo targeting
+func (s *ShowService) locate(ip string) entity.Location {
+	country, region, err := s.geo.Lookup(ip)
+	if err != nil {
+		return entity.Location{}
+	}
+
+	return entity.Location{Country: country, Region: region}
+}
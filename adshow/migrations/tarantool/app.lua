@@ -4,4 +4,86 @@ my_space = box.schema.create_space("platforms", { if_not_exists = true })
 my_space:format({{ name = 'platform_id', type = 'number' }, { name = 'device_type', type = 'string'}, { name = 'banner_id', type = 'number'}})
 
 my_space:create_index('primary', {type = 'tree', parts = {'platform_id', 'device_type', 'banner_id'}, if_not_exists = true })
-my_space:create_index('secondary', {type = 'tree', parts = {'banner_id'}, unique = false, if_not_exists = true })
\ No newline at end of file
+my_space:create_index('secondary', {type = 'tree', parts = {'banner_id'}, unique = false, if_not_exists = true })
+
+throttles = box.schema.create_space("throttles", { if_not_exists = true })
+throttles:format({{ name = 'banner_id', type = 'number' }, { name = 'probability', type = 'number' }})
+
+throttles:create_index('primary', {type = 'tree', parts = {'banner_id'}, if_not_exists = true })
+
+frequency = box.schema.create_space("frequency", { if_not_exists = true })
+frequency:format({{ name = 'visitor_id', type = 'string' }, { name = 'banner_id', type = 'number' }, { name = 'shows', type = 'number' }, { name = 'expire_at', type = 'number' }})
+
+frequency:create_index('primary', {type = 'tree', parts = {'visitor_id', 'banner_id'}, if_not_exists = true })
+frequency:create_index('expire_at', {type = 'tree', parts = {'expire_at'}, unique = false, if_not_exists = true })
+
+ctr = box.schema.create_space("ctr", { if_not_exists = true })
+ctr:format({{ name = 'banner_id', type = 'number' }, { name = 'bucket', type = 'number' }, { name = 'views', type = 'number' }, { name = 'clicks', type = 'number' }})
+
+ctr:create_index('primary', {type = 'tree', parts = {'banner_id', 'bucket'}, if_not_exists = true })
+ctr:create_index('bucket', {type = 'tree', parts = {'bucket'}, unique = false, if_not_exists = true })
+
+-- frequency_hit counts a show of the banner to the visitor, the counter starts over once its period is over
+function frequency_hit(visitor_id, banner_id, period, now)
+    local t = box.space.frequency:get{visitor_id, banner_id}
+    if t == nil or t[4] <= now then
+        box.space.frequency:replace{visitor_id, banner_id, 1, now + period}
+    else
+        box.space.frequency:update({visitor_id, banner_id}, {{'+', 3, 1}})
+    end
+end
+
+-- throttles_get returns {banner_id, probability} of the throttled banners among banner_ids
+function throttles_get(banner_ids)
+    local out = {}
+    for _, banner_id in ipairs(banner_ids) do
+        local t = box.space.throttles:get{banner_id}
+        if t ~= nil then
+            table.insert(out, {t[1], t[2]})
+        end
+    end
+    return out
+end
+
+-- ctr_stats returns {banner_id, views, clicks} of each of banner_ids summed over buckets after since
+function ctr_stats(banner_ids, since)
+    local out = {}
+    for _, banner_id in ipairs(banner_ids) do
+        local views, clicks = 0, 0
+        for _, t in box.space.ctr.index.primary:pairs({banner_id, since}, {iterator = 'GT'}) do
+            if t[1] ~= banner_id then
+                break
+            end
+            views = views + t[3]
+            clicks = clicks + t[4]
+        end
+        table.insert(out, {banner_id, views, clicks})
+    end
+    return out
+end
+
+-- expired counters and hourly buckets of ctr older than two days are removed in background
+local fiber = require('fiber')
+fiber.create(function()
+    while true do
+        fiber.sleep(60)
+
+        local expired = {}
+        for _, t in box.space.frequency.index.expire_at:pairs(os.time(), {iterator = 'LT'}) do
+            table.insert(expired, {t[1], t[2]})
+        end
+
+        for _, key in ipairs(expired) do
+            box.space.frequency:delete(key)
+        end
+
+        local outdated = {}
+        for _, t in box.space.ctr.index.bucket:pairs(math.floor(os.time() / 3600) - 48, {iterator = 'LT'}) do
+            table.insert(outdated, {t[1], t[2]})
+        end
+
+        for _, key in ipairs(outdated) do
+            box.space.ctr:delete(key)
+        end
+    end
+end)
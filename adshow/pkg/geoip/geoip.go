@@ -0,0 +1,70 @@
+package geoip
+
+import (
+	"fmt"
+	"net"
+
+	"github.com/oschwald/maxminddb-golang"
+)
+
+// record is a subset of MaxMind City and Country databases
+type record struct {
+	Country struct {
+		ISOCode string `maxminddb:"iso_code"`
+	} `maxminddb:"country"`
+	Subdivisions []struct {
+		ISOCode string `maxminddb:"iso_code"`
+	} `maxminddb:"subdivisions"`
+}
+
+// Reader resolves IP addresses through a local MaxMind-format database file
+type Reader struct {
+	db *maxminddb.Reader
+}
+
+func Open(path string) (*Reader, error) {
+	db, err := maxminddb.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("could not open geoip database: %w", err)
+	}
+
+	return &Reader{db: db}, nil
+}
+
+// Lookup returns ISO 3166-1 country code and ISO 3166-2 code of the region like RU-MOW,
+// both are empty if the address is unknown
+func (r *Reader) Lookup(ip string) (string, string, error) {
+	addr := net.ParseIP(ip)
+	if addr == nil {
+		return "", "", fmt.Errorf("wrong ip: %s", ip)
+	}
+
+	var rec record
+	if err := r.db.Lookup(addr, &rec); err != nil {
+		return "", "", fmt.Errorf("could not lookup: %w", err)
+	}
+
+	country := rec.Country.ISOCode
+
+	var region string
+	if country != "" && len(rec.Subdivisions) > 0 && rec.Subdivisions[0].ISOCode != "" {
+		region = country + "-" + rec.Subdivisions[0].ISOCode
+	}
+
+	return country, region, nil
+}
+
+func (r *Reader) Close() error {
+	return r.db.Close()
+}
+
+// Nop resolves no address, it's used when there is no database so every visitor is of unknown location
+type Nop struct{}
+
+func (Nop) Lookup(string) (string, string, error) {
+	return "", "", nil
+}
+
+func (Nop) Close() error {
+	return nil
+}
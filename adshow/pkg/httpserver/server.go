@@ -19,6 +19,9 @@ type Server struct {
 func New(userRouter *httpdelivery.Routes) *Server {
 	e := echo.New()
 	e.HideBanner = true
+	// service runs behind nginx so address of the client is taken from X-Forwarded-For
+	// which is trusted only when it comes from private networks
+	e.IPExtractor = echo.ExtractIPFromXFFHeader()
 
 	return &Server{
 		e:      e,
@@ -0,0 +1,113 @@
+package useragent
+
+import "strings"
+
+const (
+	DeviceDesktop = "desktop"
+	DeviceMobile  = "mobile"
+	DeviceTablet  = "tablet"
+
+	OSWindows      = "windows"
+	OSWindowsPhone = "windows phone"
+	OSMacOS        = "macos"
+	OSIOS          = "ios"
+	OSAndroid      = "android"
+	OSChromeOS     = "chromeos"
+	OSLinux        = "linux"
+
+	BrowserChrome  = "chrome"
+	BrowserFirefox = "firefox"
+	BrowserSafari  = "safari"
+	BrowserEdge    = "edge"
+	BrowserIE      = "ie"
+	BrowserOpera   = "opera"
+	BrowserYandex  = "yandex"
+	BrowserSamsung = "samsung"
+	BrowserUC      = "uc"
+
+	Other = "other"
+)
+
+// Agent is what is known about the client by its User-Agent
+type Agent struct {
+	Device  string `json:"device"`
+	OS      string `json:"os"`
+	Browser string `json:"browser"`
+}
+
+// rule matches User-Agent containing any of tokens and none of excluded ones
+type rule struct {
+	value    string
+	tokens   []string
+	excludes []string
+}
+
+func (r rule) match(ua string) bool {
+	for _, token := range r.excludes {
+		if strings.Contains(ua, token) {
+			return false
+		}
+	}
+
+	for _, token := range r.tokens {
+		if strings.Contains(ua, token) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// rules are checked in order and the first matched one wins, so more specific rules go first:
+// most of browsers mention Safari and Chrome, Windows Phone mentions Android and iPhone
+var (
+	deviceRules = []rule{
+		{value: DeviceMobile, tokens: []string{"Windows Phone", "IEMobile"}},
+		// old Internet Explorer mentions Tablet PC on desktops
+		{value: DeviceTablet, tokens: []string{"iPad", "Tablet", "Kindle", "Silk/", "PlayBook"}, excludes: []string{"Windows NT"}},
+		{value: DeviceMobile, tokens: []string{"Mobile", "iPhone", "iPod", "BlackBerry", "Opera Mini"}},
+		// Android tablets don't mention Mobile
+		{value: DeviceTablet, tokens: []string{"Android"}},
+	}
+
+	osRules = []rule{
+		{value: OSWindowsPhone, tokens: []string{"Windows Phone"}},
+		{value: OSWindows, tokens: []string{"Windows"}},
+		{value: OSIOS, tokens: []string{"iPhone", "iPad", "iPod"}},
+		{value: OSAndroid, tokens: []string{"Android"}},
+		{value: OSChromeOS, tokens: []string{"CrOS"}},
+		{value: OSMacOS, tokens: []string{"Macintosh", "Mac OS X"}},
+		{value: OSLinux, tokens: []string{"Linux", "X11"}},
+	}
+
+	browserRules = []rule{
+		{value: BrowserYandex, tokens: []string{"YaBrowser/"}},
+		{value: BrowserEdge, tokens: []string{"Edg/", "Edge/", "EdgA/", "EdgiOS/"}},
+		{value: BrowserOpera, tokens: []string{"OPR/", "Opera", "OPiOS/"}},
+		{value: BrowserSamsung, tokens: []string{"SamsungBrowser/"}},
+		{value: BrowserUC, tokens: []string{"UCBrowser/"}},
+		{value: BrowserFirefox, tokens: []string{"Firefox/", "FxiOS/"}},
+		{value: BrowserChrome, tokens: []string{"CriOS/", "Chrome/", "Chromium/"}},
+		{value: BrowserIE, tokens: []string{"MSIE", "Trident/"}},
+		{value: BrowserSafari, tokens: []string{"Safari/"}},
+	}
+)
+
+func apply(rules []rule, ua string, fallback string) string {
+	for _, r := range rules {
+		if r.match(ua) {
+			return r.value
+		}
+	}
+
+	return fallback
+}
+
+// Parse never fails, unknown clients are treated as desktop ones
+func Parse(ua string) Agent {
+	return Agent{
+		Device:  apply(deviceRules, ua, DeviceDesktop),
+		OS:      apply(osRules, ua, Other),
+		Browser: apply(browserRules, ua, Other),
+	}
+}
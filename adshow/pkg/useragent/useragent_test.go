@@ -0,0 +1,117 @@
+package useragent
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		ua   string
+		want Agent
+	}{
+		{
+			ua:   "Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/99.0.4844.51 Safari/537.36",
+			want: Agent{Device: DeviceDesktop, OS: OSWindows, Browser: BrowserChrome},
+		},
+		{
+			ua:   "Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/99.0.4844.51 Safari/537.36 Edg/99.0.1150.36",
+			want: Agent{Device: DeviceDesktop, OS: OSWindows, Browser: BrowserEdge},
+		},
+		{
+			ua:   "Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/98.0.4758.102 YaBrowser/22.3.0.2430 Yowser/2.5 Safari/537.36",
+			want: Agent{Device: DeviceDesktop, OS: OSWindows, Browser: BrowserYandex},
+		},
+		{
+			ua:   "Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/98.0.4758.102 Safari/537.36 OPR/84.0.4316.31",
+			want: Agent{Device: DeviceDesktop, OS: OSWindows, Browser: BrowserOpera},
+		},
+		{
+			ua:   "Mozilla/5.0 (Windows NT 6.1; WOW64; Trident/7.0; rv:11.0) like Gecko",
+			want: Agent{Device: DeviceDesktop, OS: OSWindows, Browser: BrowserIE},
+		},
+		{
+			ua:   "Mozilla/4.0 (compatible; MSIE 7.0; Windows NT 6.0; SLCC1; .NET CLR 2.0.50727; Tablet PC 2.0)",
+			want: Agent{Device: DeviceDesktop, OS: OSWindows, Browser: BrowserIE},
+		},
+		{
+			ua:   "Mozilla/5.0 (Macintosh; Intel Mac OS X 10_15_7) AppleWebKit/605.1.15 (KHTML, like Gecko) Version/15.3 Safari/605.1.15",
+			want: Agent{Device: DeviceDesktop, OS: OSMacOS, Browser: BrowserSafari},
+		},
+		{
+			ua:   "Mozilla/5.0 (Macintosh; Intel Mac OS X 10.15; rv:98.0) Gecko/20100101 Firefox/98.0",
+			want: Agent{Device: DeviceDesktop, OS: OSMacOS, Browser: BrowserFirefox},
+		},
+		{
+			ua:   "Mozilla/5.0 (X11; Ubuntu; Linux x86_64; rv:98.0) Gecko/20100101 Firefox/98.0",
+			want: Agent{Device: DeviceDesktop, OS: OSLinux, Browser: BrowserFirefox},
+		},
+		{
+			ua:   "Mozilla/5.0 (X11; CrOS x86_64 14388.61.0) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/93.0.4577.107 Safari/537.36",
+			want: Agent{Device: DeviceDesktop, OS: OSChromeOS, Browser: BrowserChrome},
+		},
+		{
+			ua:   "Mozilla/5.0 (iPhone; CPU iPhone OS 15_3_1 like Mac OS X) AppleWebKit/605.1.15 (KHTML, like Gecko) Version/15.3 Mobile/15E148 Safari/604.1",
+			want: Agent{Device: DeviceMobile, OS: OSIOS, Browser: BrowserSafari},
+		},
+		{
+			ua:   "Mozilla/5.0 (iPhone; CPU iPhone OS 15_3 like Mac OS X) AppleWebKit/605.1.15 (KHTML, like Gecko) CriOS/99.0.4844.47 Mobile/15E148 Safari/604.1",
+			want: Agent{Device: DeviceMobile, OS: OSIOS, Browser: BrowserChrome},
+		},
+		{
+			ua:   "Mozilla/5.0 (iPhone; CPU iPhone OS 15_3 like Mac OS X) AppleWebKit/605.1.15 (KHTML, like Gecko) FxiOS/98.0 Mobile/15E148 Safari/605.1.15",
+			want: Agent{Device: DeviceMobile, OS: OSIOS, Browser: BrowserFirefox},
+		},
+		{
+			ua:   "Mozilla/5.0 (iPad; CPU OS 15_3 like Mac OS X) AppleWebKit/605.1.15 (KHTML, like Gecko) Version/15.3 Mobile/15E148 Safari/604.1",
+			want: Agent{Device: DeviceTablet, OS: OSIOS, Browser: BrowserSafari},
+		},
+		{
+			ua:   "Mozilla/5.0 (Linux; Android 12; SM-G991B) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/99.0.4844.58 Mobile Safari/537.36",
+			want: Agent{Device: DeviceMobile, OS: OSAndroid, Browser: BrowserChrome},
+		},
+		{
+			ua:   "Mozilla/5.0 (Linux; Android 11; SAMSUNG SM-A515F) AppleWebKit/537.36 (KHTML, like Gecko) SamsungBrowser/16.2 Chrome/92.0.4515.166 Mobile Safari/537.36",
+			want: Agent{Device: DeviceMobile, OS: OSAndroid, Browser: BrowserSamsung},
+		},
+		{
+			ua:   "Mozilla/5.0 (Linux; U; Android 10; en-US; RMX1911 Build/QKQ1.200209.002) AppleWebKit/537.36 (KHTML, like Gecko) Version/4.0 Chrome/78.0.3904.108 UCBrowser/13.4.0.1306 Mobile Safari/537.36",
+			want: Agent{Device: DeviceMobile, OS: OSAndroid, Browser: BrowserUC},
+		},
+		{
+			ua:   "Mozilla/5.0 (Android 12; Mobile; rv:98.0) Gecko/98.0 Firefox/98.0",
+			want: Agent{Device: DeviceMobile, OS: OSAndroid, Browser: BrowserFirefox},
+		},
+		{
+			ua:   "Mozilla/5.0 (Linux; Android 11; SM-T870) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/99.0.4844.58 Safari/537.36",
+			want: Agent{Device: DeviceTablet, OS: OSAndroid, Browser: BrowserChrome},
+		},
+		{
+			ua:   "Mozilla/5.0 (Linux; Android 9; KFMAWI) AppleWebKit/537.36 (KHTML, like Gecko) Silk/98.4.19 like Chrome/98.0.4758.101 Safari/537.36",
+			want: Agent{Device: DeviceTablet, OS: OSAndroid, Browser: BrowserChrome},
+		},
+		{
+			ua:   "Mozilla/5.0 (Windows Phone 10.0; Android 6.0.1; Microsoft; Lumia 950) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/52.0.2743.116 Mobile Safari/537.36 Edge/15.14977",
+			want: Agent{Device: DeviceMobile, OS: OSWindowsPhone, Browser: BrowserEdge},
+		},
+		{
+			ua:   "Opera/9.80 (J2ME/MIDP; Opera Mini/9.80 (S60; SymbOS; Opera Mobi/23.348; U; en) Presto/2.5.25 Version/10.54",
+			want: Agent{Device: DeviceMobile, OS: Other, Browser: BrowserOpera},
+		},
+		{
+			ua:   "",
+			want: Agent{Device: DeviceDesktop, OS: Other, Browser: Other},
+		},
+		{
+			ua:   "curl/7.79.1",
+			want: Agent{Device: DeviceDesktop, OS: Other, Browser: Other},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.ua, func(t *testing.T) {
+			assert.Equal(t, tt.want, Parse(tt.ua))
+		})
+	}
+}
@@ -16,6 +16,9 @@ type StatService interface {
 	GetBannerStatToday(ctx context.Context, bannerID int) ([]*entity.BannerStat, error)
 	GetPlatformStat(ctx context.Context, platformID int, from time.Time) ([]*entity.PlatformStat, error)
 	GetPlatformStatToday(ctx context.Context, platformID int) ([]*entity.PlatformStat, error)
+	GetBannerGeoStat(ctx context.Context, bannerID int, from time.Time) ([]*entity.GeoStat, error)
+	GetBannerImpressionStat(ctx context.Context, bannerID int, from time.Time) ([]*entity.BannerStat, error)
+	GetBannerInvalidClickStat(ctx context.Context, bannerID int, from time.Time) ([]*entity.InvalidClickStat, error)
 }
 
 type Router struct {
@@ -81,3 +84,68 @@ func (r *Router) PlatformStat(c echo.Context) error {
 
 	return c.JSON(http.StatusOK, platforms)
 }
+
+func (r *Router) BannerGeoStat(c echo.Context) error {
+	bb := c.Param("id")
+	bannerID, err := strconv.Atoi(bb)
+	if err != nil {
+		return c.JSON(http.StatusUnprocessableEntity, HTTPError{"wrong data"})
+	}
+
+	from, err := time.Parse("2006-01-02", c.QueryParam("from"))
+	if err != nil {
+		from = time.Now()
+	}
+
+	stat, err := r.statSvc.GetBannerGeoStat(c.Request().Context(), bannerID, from)
+	if err != nil {
+		r.logger.Errorw("could not get geo stat", "err", err, "endpoint", "BannerGeoStat")
+		return c.JSON(http.StatusInternalServerError, HTTPError{"could not get stat"})
+	}
+
+	return c.JSON(http.StatusOK, stat)
+}
+
+// BannerImpressionStat returns CTR of the banner calculated over shows linked to clicks by view ID
+func (r *Router) BannerImpressionStat(c echo.Context) error {
+	bb := c.Param("id")
+	bannerID, err := strconv.Atoi(bb)
+	if err != nil {
+		return c.JSON(http.StatusUnprocessableEntity, HTTPError{"wrong data"})
+	}
+
+	from, err := time.Parse("2006-01-02", c.QueryParam("from"))
+	if err != nil {
+		from = time.Now()
+	}
+
+	stat, err := r.statSvc.GetBannerImpressionStat(c.Request().Context(), bannerID, from)
+	if err != nil {
+		r.logger.Errorw("could not get impression stat", "err", err, "endpoint", "BannerImpressionStat")
+		return c.JSON(http.StatusInternalServerError, HTTPError{"could not get stat"})
+	}
+
+	return c.JSON(http.StatusOK, stat)
+}
+
+// BannerInvalidClickStat returns clicks of the banner which weren't charged grouped by reason
+func (r *Router) BannerInvalidClickStat(c echo.Context) error {
+	bb := c.Param("id")
+	bannerID, err := strconv.Atoi(bb)
+	if err != nil {
+		return c.JSON(http.StatusUnprocessableEntity, HTTPError{"wrong data"})
+	}
+
+	from, err := time.Parse("2006-01-02", c.QueryParam("from"))
+	if err != nil {
+		from = time.Now()
+	}
+
+	stat, err := r.statSvc.GetBannerInvalidClickStat(c.Request().Context(), bannerID, from)
+	if err != nil {
+		r.logger.Errorw("could not get invalid click stat", "err", err, "endpoint", "BannerInvalidClickStat")
+		return c.JSON(http.StatusInternalServerError, HTTPError{"could not get stat"})
+	}
+
+	return c.JSON(http.StatusOK, stat)
+}
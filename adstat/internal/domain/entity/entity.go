@@ -44,6 +44,10 @@ type BannerStat struct {
 	Clicks   int     `json:"clicks" db:"clicks"`
 	CTR      float64 `json:"ctr" db:"ctr"`
 	Day      string  `json:"day" db:"day"`
+	// ConversionRate is a share of clicks converted in percents, CostPerConversion is spend divided by conversions
+	Conversions       int     `json:"conversions" db:"conversions"`
+	ConversionRate    float64 `json:"conversion_rate" db:"conversion_rate"`
+	CostPerConversion float64 `json:"cost_per_conversion" db:"cost_per_conversion"`
 }
 
 type PlatformStat struct {
@@ -53,3 +57,17 @@ type PlatformStat struct {
 	CTR        float64 `json:"ctr" db:"ctr"`
 	Day        string  `json:"day" db:"day"`
 }
+
+type GeoStat struct {
+	BannerID int    `json:"banner_id" db:"banner_id"`
+	Country  string `json:"country" db:"country"`
+	Views    int    `json:"views" db:"hits"`
+	Day      string `json:"day" db:"day"`
+}
+
+type InvalidClickStat struct {
+	BannerID int    `json:"banner_id" db:"banner_id"`
+	Reason   string `json:"reason" db:"reason"`
+	Clicks   int    `json:"clicks" db:"clicks"`
+	Day      string `json:"day" db:"day"`
+}
@@ -25,7 +25,11 @@ func (r *Repo) GetBannerStat(ctx context.Context, bannerID int, from time.Time)
 	tm := r.clickhouseDayFormat(from)
 	var stat []*entity.BannerStat
 
-	err := r.conn.SelectContext(ctx, &stat, "SELECT h.banner_id,sum(c.clicks)clicks,sum(h.hits)hits,h.day,(clicks/hits*100)AS ctr FROM hits_daily h LEFT JOIN(SELECT c.banner_id,sum(c.clicks)AS clicks,c.day FROM clicks_daily c GROUP BY c.day,c.banner_id)c ON h.banner_id=c.banner_id AND h.day=c.day WHERE h.banner_id=? AND h.day>= ? GROUP BY h.day,h.banner_id",
+	err := r.conn.SelectContext(ctx, &stat, "SELECT h.banner_id,c.clicks,h.hits,h.day,(clicks/hits*100)AS ctr,v.conversions,if(clicks>0,conversions/clicks*100,0)AS conversion_rate,if(conversions>0,c.price/conversions,0)AS cost_per_conversion FROM(SELECT banner_id,sum(hits)hits,day FROM hits_daily WHERE banner_id=? AND day>=? GROUP BY day,banner_id)h LEFT JOIN(SELECT banner_id,sum(clicks)AS clicks,sum(price)AS price,day FROM clicks_daily WHERE banner_id=? AND day>=? GROUP BY day,banner_id)c ON h.banner_id=c.banner_id AND h.day=c.day LEFT JOIN(SELECT banner_id,sum(conversions)AS conversions,day FROM conversions_daily WHERE banner_id=? AND day>=? GROUP BY day,banner_id)v ON h.banner_id=v.banner_id AND h.day=v.day ORDER BY h.day",
+		bannerID,
+		tm,
+		bannerID,
+		tm,
 		bannerID,
 		tm)
 	if err != nil {
@@ -48,3 +52,49 @@ func (r *Repo) GetPlatformStat(ctx context.Context, platformID int, from time.Ti
 
 	return stat, nil
 }
+
+func (r *Repo) GetBannerGeoStat(ctx context.Context, bannerID int, from time.Time) ([]*entity.GeoStat, error) {
+	tm := r.clickhouseDayFormat(from)
+	var stat []*entity.GeoStat
+
+	err := r.conn.SelectContext(ctx, &stat, "SELECT banner_id,country,sum(hits)hits,day FROM hits_geo_daily WHERE banner_id=? AND day>=? GROUP BY day,banner_id,country ORDER BY day,hits DESC",
+		bannerID,
+		tm)
+	if err != nil {
+		return nil, fmt.Errorf("could not select: %w", err)
+	}
+
+	return stat, nil
+}
+
+// GetBannerImpressionStat counts shows of the banner which were clicked, shows made before view IDs
+// were introduced can't be linked to clicks so they're skipped
+func (r *Repo) GetBannerImpressionStat(ctx context.Context, bannerID int, from time.Time) ([]*entity.BannerStat, error) {
+	tm := r.clickhouseDayFormat(from)
+	var stat []*entity.BannerStat
+
+	err := r.conn.SelectContext(ctx, &stat, "SELECT h.banner_id,countIf(c.view_id!='')clicks,count()hits,h.day,(clicks/hits*100)AS ctr FROM(SELECT banner_id,toString(view_id)AS view_id,day FROM hits WHERE banner_id=? AND day>=? AND view_id!=toUUID('00000000-0000-0000-0000-000000000000'))h LEFT JOIN(SELECT DISTINCT view_id FROM clicks WHERE banner_id=? AND day>=? AND view_id!='')c ON h.view_id=c.view_id GROUP BY h.day,h.banner_id ORDER BY h.day",
+		bannerID,
+		tm,
+		bannerID,
+		tm)
+	if err != nil {
+		return nil, fmt.Errorf("could not select: %w", err)
+	}
+
+	return stat, nil
+}
+
+func (r *Repo) GetBannerInvalidClickStat(ctx context.Context, bannerID int, from time.Time) ([]*entity.InvalidClickStat, error) {
+	tm := r.clickhouseDayFormat(from)
+	var stat []*entity.InvalidClickStat
+
+	err := r.conn.SelectContext(ctx, &stat, "SELECT banner_id,reason,count()clicks,day FROM invalid_clicks WHERE banner_id=? AND day>=? GROUP BY day,banner_id,reason ORDER BY day,clicks DESC",
+		bannerID,
+		tm)
+	if err != nil {
+		return nil, fmt.Errorf("could not select: %w", err)
+	}
+
+	return stat, nil
+}
@@ -12,6 +12,9 @@ import (
 type StatRepo interface {
 	GetBannerStat(ctx context.Context, bannerID int, from time.Time) ([]*entity.BannerStat, error)
 	GetPlatformStat(ctx context.Context, platformID int, from time.Time) ([]*entity.PlatformStat, error)
+	GetBannerGeoStat(ctx context.Context, bannerID int, from time.Time) ([]*entity.GeoStat, error)
+	GetBannerImpressionStat(ctx context.Context, bannerID int, from time.Time) ([]*entity.BannerStat, error)
+	GetBannerInvalidClickStat(ctx context.Context, bannerID int, from time.Time) ([]*entity.InvalidClickStat, error)
 }
 
 type StatService struct {
@@ -74,3 +77,42 @@ func (s *StatService) GetPlatformStatToday(ctx context.Context, platformID int)
 
 	return stat, nil
 }
+
+func (s *StatService) GetBannerGeoStat(ctx context.Context, bannerID int, from time.Time) ([]*entity.GeoStat, error) {
+	stat, err := s.repo.GetBannerGeoStat(ctx, bannerID, from)
+	if err != nil {
+		return nil, fmt.Errorf("repo failed: %w", err)
+	}
+
+	if len(stat) == 0 {
+		return []*entity.GeoStat{}, nil
+	}
+
+	return stat, nil
+}
+
+func (s *StatService) GetBannerImpressionStat(ctx context.Context, bannerID int, from time.Time) ([]*entity.BannerStat, error) {
+	stat, err := s.repo.GetBannerImpressionStat(ctx, bannerID, from)
+	if err != nil {
+		return nil, fmt.Errorf("repo failed: %w", err)
+	}
+
+	if len(stat) == 0 {
+		return []*entity.BannerStat{}, nil
+	}
+
+	return stat, nil
+}
+
+func (s *StatService) GetBannerInvalidClickStat(ctx context.Context, bannerID int, from time.Time) ([]*entity.InvalidClickStat, error) {
+	stat, err := s.repo.GetBannerInvalidClickStat(ctx, bannerID, from)
+	if err != nil {
+		return nil, fmt.Errorf("repo failed: %w", err)
+	}
+
+	if len(stat) == 0 {
+		return []*entity.InvalidClickStat{}, nil
+	}
+
+	return stat, nil
+}
@@ -0,0 +1,34 @@
+ALTER TABLE hits
+    ADD COLUMN os      String DEFAULT '',
+    ADD COLUMN browser String DEFAULT '';
+
+DROP TABLE consumer_hits;
+DROP TABLE kafka_hits;
+
+CREATE TABLE kafka_hits
+(
+    banner_id   UInt64,
+    platform_id UInt64,
+    user_agent  String,
+    device      String,
+    os          String,
+    browser     String,
+    created_at  UInt64
+) ENGINE = Kafka('kafka-1:9092,kafka-2:9092,kafka-3:9092',
+           'adshow.action.show',
+           'ch-stat-hits',
+           'JSONEachRow');
+
+CREATE MATERIALIZED VIEW consumer_hits TO hits AS
+SELECT banner_id,
+       platform_id,
+       user_agent,
+       device,
+       os,
+       browser,
+       created_at,
+       toDate(
+               toDateTime(created_at)) AS day,
+       toDateTime(
+               created_at)             AS dt
+FROM kafka_hits;
@@ -0,0 +1,63 @@
+ALTER TABLE hits
+    ADD COLUMN country String DEFAULT '',
+    ADD COLUMN region  String DEFAULT '';
+
+DROP TABLE consumer_hits;
+DROP TABLE kafka_hits;
+
+CREATE TABLE kafka_hits
+(
+    banner_id   UInt64,
+    platform_id UInt64,
+    user_agent  String,
+    device      String,
+    os          String,
+    browser     String,
+    country     String,
+    region      String,
+    created_at  UInt64
+) ENGINE = Kafka('kafka-1:9092,kafka-2:9092,kafka-3:9092',
+           'adshow.action.show',
+           'ch-stat-hits',
+           'JSONEachRow');
+
+CREATE MATERIALIZED VIEW consumer_hits TO hits AS
+SELECT banner_id,
+       platform_id,
+       user_agent,
+       device,
+       os,
+       browser,
+       country,
+       region,
+       created_at,
+       toDate(
+               toDateTime(created_at)) AS day,
+       toDateTime(
+               created_at)             AS dt
+FROM kafka_hits;
+
+CREATE TABLE hits_geo_daily
+(
+    banner_id UInt64,
+    country   String,
+    hits      UInt64,
+    day       Date
+) ENGINE = SummingMergeTree(day,
+           (day,
+            banner_id,
+            country),
+           8192);
+
+CREATE MATERIALIZED VIEW hits_geo_daily_view TO hits_geo_daily AS
+SELECT banner_id,
+       country,
+       count(
+           ) AS hits,
+       day
+FROM hits
+GROUP BY (
+          day,
+          banner_id,
+          country
+             );
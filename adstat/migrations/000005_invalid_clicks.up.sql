@@ -0,0 +1,36 @@
+CREATE TABLE invalid_clicks
+(
+    banner_id   UInt64,
+    platform_id UInt64,
+    view_id     String,
+    reason      String,
+    created_at  UInt64,
+    day         Date,
+    dt          DateTime
+) ENGINE = MergeTree() PARTITION BY toYYYYMM(dt)
+      ORDER BY
+          (banner_id, platform_id, dt);
+
+CREATE TABLE kafka_invalid_clicks
+(
+    banner_id   UInt64,
+    platform_id UInt64,
+    view_id     String,
+    reason      String,
+    created_at  UInt64
+) ENGINE = Kafka('kafka-1:9092,kafka-2:9092,kafka-3:9092',
+           'adclick.action.click.invalid',
+           'ch-stat-invalid-clicks',
+           'JSONEachRow');
+
+CREATE MATERIALIZED VIEW consumer_invalid_clicks TO invalid_clicks AS
+SELECT banner_id,
+       platform_id,
+       view_id,
+       reason,
+       created_at,
+       toDate(
+               toDateTime(created_at)) AS day,
+       toDateTime(
+               created_at)             AS dt
+FROM kafka_invalid_clicks;
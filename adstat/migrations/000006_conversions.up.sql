@@ -0,0 +1,99 @@
+ALTER TABLE clicks
+    ADD COLUMN click_id String DEFAULT '';
+
+DROP TABLE consumer_clicks;
+DROP TABLE kafka_clicks;
+
+CREATE TABLE kafka_clicks
+(
+    click_id    String,
+    banner_id   UInt64,
+    platform_id UInt64,
+    view_id     String,
+    price       Float64,
+    created_at  UInt64
+) ENGINE = Kafka('kafka-1:9092,kafka-2:9092,kafka-3:9092',
+           'adclick.action.click',
+           'ch-stat-clicks',
+           'JSONEachRow');
+
+CREATE MATERIALIZED VIEW consumer_clicks TO clicks AS
+SELECT click_id,
+       banner_id,
+       platform_id,
+       price,
+       view_id,
+       created_at,
+       toDate(
+               toDateTime(created_at)) AS day,
+       toDateTime(
+               created_at)             AS dt
+FROM kafka_clicks;
+
+CREATE TABLE conversions
+(
+    click_id    String,
+    banner_id   UInt64,
+    platform_id UInt64,
+    view_id     String,
+    value       Float64,
+    created_at  UInt64,
+    day         Date,
+    dt          DateTime
+) ENGINE = MergeTree() PARTITION BY toYYYYMM(dt)
+      ORDER BY
+          (banner_id, platform_id, dt);
+
+CREATE TABLE conversions_daily
+(
+    banner_id   UInt64,
+    platform_id UInt64,
+    conversions UInt64,
+    value       Float64,
+    day         Date
+) ENGINE = SummingMergeTree(day,
+           (day,
+            banner_id,
+            platform_id),
+           8192);
+
+CREATE TABLE kafka_conversions
+(
+    click_id    String,
+    banner_id   UInt64,
+    platform_id UInt64,
+    view_id     String,
+    value       Float64,
+    created_at  UInt64
+) ENGINE = Kafka('kafka-1:9092,kafka-2:9092,kafka-3:9092',
+           'adclick.action.conversion',
+           'ch-stat-conversions',
+           'JSONEachRow');
+
+CREATE MATERIALIZED VIEW consumer_conversions TO conversions AS
+SELECT click_id,
+       banner_id,
+       platform_id,
+       view_id,
+       value,
+       created_at,
+       toDate(
+               toDateTime(created_at)) AS day,
+       toDateTime(
+               created_at)             AS dt
+FROM kafka_conversions;
+
+CREATE MATERIALIZED VIEW conversions_daily_view TO conversions_daily AS
+SELECT banner_id,
+       platform_id,
+       count(
+           )          AS conversions,
+       sum(
+               value) AS value,
+       day
+FROM conversions
+GROUP BY (
+          day,
+          banner_id,
+          platform_id
+             );
@@ -0,0 +1,145 @@
+-- views and clicks are sent either as JSON or protobuf while producers migrate, the content-type header tells which one.
+-- Each format is read by its own kafka table, a message of the other format fails to parse and is streamed
+-- with _error instead of stopping the consumer, so views drop it. Schemas are in contracts/events/proto.
+
+DROP TABLE consumer_hits;
+DROP TABLE kafka_hits;
+
+CREATE TABLE kafka_hits
+(
+    view_id     String,
+    banner_id   UInt64,
+    platform_id UInt64,
+    user_agent  String,
+    device      String,
+    os          String,
+    browser     String,
+    country     String,
+    region      String,
+    created_at  UInt64
+) ENGINE = Kafka SETTINGS kafka_broker_list = 'kafka-1:9092,kafka-2:9092,kafka-3:9092',
+           kafka_topic_list = 'adshow.action.show',
+           kafka_group_name = 'ch-stat-hits',
+           kafka_format = 'JSONEachRow',
+           kafka_handle_error_mode = 'stream';
+
+CREATE MATERIALIZED VIEW consumer_hits TO hits AS
+SELECT toUUIDOrZero(
+               view_id)                AS view_id,
+       banner_id,
+       platform_id,
+       user_agent,
+       device,
+       os,
+       browser,
+       country,
+       region,
+       created_at,
+       toDate(
+               toDateTime(created_at)) AS day,
+       toDateTime(
+               created_at)             AS dt
+FROM kafka_hits
+WHERE length(_error) = 0
+  AND _headers.value[indexOf(_headers.name, 'content-type')] != 'application/x-protobuf';
+
+CREATE TABLE kafka_hits_protobuf
+(
+    view_id     String,
+    banner_id   UInt64,
+    platform_id UInt64,
+    user_agent  String,
+    device      String,
+    os          String,
+    browser     String,
+    country     String,
+    region      String,
+    created_at  UInt64
+) ENGINE = Kafka SETTINGS kafka_broker_list = 'kafka-1:9092,kafka-2:9092,kafka-3:9092',
+           kafka_topic_list = 'adshow.action.show',
+           kafka_group_name = 'ch-stat-hits-protobuf',
+           kafka_format = 'ProtobufSingle',
+           kafka_schema = 'actions.proto:View',
+           kafka_handle_error_mode = 'stream';
+
+CREATE MATERIALIZED VIEW consumer_hits_protobuf TO hits AS
+SELECT toUUIDOrZero(
+               view_id)                AS view_id,
+       banner_id,
+       platform_id,
+       user_agent,
+       device,
+       os,
+       browser,
+       country,
+       region,
+       created_at,
+       toDate(
+               toDateTime(created_at)) AS day,
+       toDateTime(
+               created_at)             AS dt
+FROM kafka_hits_protobuf
+WHERE length(_error) = 0
+  AND _headers.value[indexOf(_headers.name, 'content-type')] = 'application/x-protobuf';
+
+DROP TABLE consumer_clicks;
+DROP TABLE kafka_clicks;
+
+CREATE TABLE kafka_clicks
+(
+    click_id    String,
+    banner_id   UInt64,
+    platform_id UInt64,
+    view_id     String,
+    price       Float64,
+    created_at  UInt64
+) ENGINE = Kafka SETTINGS kafka_broker_list = 'kafka-1:9092,kafka-2:9092,kafka-3:9092',
+           kafka_topic_list = 'adclick.action.click',
+           kafka_group_name = 'ch-stat-clicks',
+           kafka_format = 'JSONEachRow',
+           kafka_handle_error_mode = 'stream';
+
+CREATE MATERIALIZED VIEW consumer_clicks TO clicks AS
+SELECT click_id,
+       banner_id,
+       platform_id,
+       price,
+       view_id,
+       created_at,
+       toDate(
+               toDateTime(created_at)) AS day,
+       toDateTime(
+               created_at)             AS dt
+FROM kafka_clicks
+WHERE length(_error) = 0
+  AND _headers.value[indexOf(_headers.name, 'content-type')] != 'application/x-protobuf';
+
+CREATE TABLE kafka_clicks_protobuf
+(
+    click_id    String,
+    banner_id   UInt64,
+    platform_id UInt64,
+    view_id     String,
+    price       Float64,
+    created_at  UInt64
+) ENGINE = Kafka SETTINGS kafka_broker_list = 'kafka-1:9092,kafka-2:9092,kafka-3:9092',
+           kafka_topic_list = 'adclick.action.click',
+           kafka_group_name = 'ch-stat-clicks-protobuf',
+           kafka_format = 'ProtobufSingle',
+           kafka_schema = 'actions.proto:Click',
+           kafka_handle_error_mode = 'stream';
+
+CREATE MATERIALIZED VIEW consumer_clicks_protobuf TO clicks AS
+SELECT click_id,
+       banner_id,
+       platform_id,
+       price,
+       view_id,
+       created_at,
+       toDate(
+               toDateTime(created_at)) AS day,
+       toDateTime(
+               created_at)             AS dt
+FROM kafka_clicks_protobuf
+WHERE length(_error) = 0
+  AND _headers.value[indexOf(_headers.name, 'content-type')] = 'application/x-protobuf';
@@ -34,6 +34,9 @@ func (s *Server) BuildRoutes() {
 
 	userAPIV1 := s.e.Group("/api/v1")
 	userAPIV1.GET("/banners/:id", s.router.BannerStat)
+	userAPIV1.GET("/banners/:id/geo", s.router.BannerGeoStat)
+	userAPIV1.GET("/banners/:id/impressions", s.router.BannerImpressionStat)
+	userAPIV1.GET("/banners/:id/invalid", s.router.BannerInvalidClickStat)
 	userAPIV1.GET("/platforms/:id", s.router.PlatformStat)
 
 }
@@ -0,0 +1,128 @@
+package events
+
+import "strconv"
+
+const (
+	TypeView         = "action.view"
+	TypeClick        = "action.click"
+	TypeInvalidClick = "action.invalid_click"
+	TypeConversion   = "action.conversion"
+)
+
+// View is sent by adshow for each banner shown, it's also stored by adstat
+type View struct {
+	ViewID     string `json:"view_id"`
+	BannerID   int    `json:"banner_id"`
+	PlatformID int    `json:"platform_id"`
+	UserAgent  string `json:"user_agent"`
+	Device     string `json:"device"`
+	OS         string `json:"os"`
+	Browser    string `json:"browser"`
+	Country    string `json:"country"`
+	Region     string `json:"region"`
+	CreatedAt  int64  `json:"created_at"`
+}
+
+func (View) EventType() string { return TypeView }
+func (View) EventVersion() int { return 1 }
+
+func (v View) PartitionKey() string {
+	return strconv.Itoa(v.BannerID)
+}
+
+func (v View) MarshalProto() ([]byte, error) {
+	return marshalProto(v.protoFields())
+}
+
+func (v *View) UnmarshalProto(payload []byte) error {
+	*v = View{}
+	return unmarshalProto(payload, v.protoFields())
+}
+
+// protoFields follow message View of proto/actions.proto
+func (v *View) protoFields() []protoField {
+	return []protoField{
+		{num: 1, value: &v.ViewID},
+		{num: 2, value: &v.BannerID},
+		{num: 3, value: &v.PlatformID},
+		{num: 4, value: &v.UserAgent},
+		{num: 5, value: &v.Device},
+		{num: 6, value: &v.OS},
+		{num: 7, value: &v.Browser},
+		{num: 8, value: &v.Country},
+		{num: 9, value: &v.Region},
+		{num: 10, value: &v.CreatedAt},
+	}
+}
+
+// Click is sent by adclick for each charged click
+type Click struct {
+	ClickID    string  `json:"click_id"`
+	BannerID   int     `json:"banner_id"`
+	PlatformID int     `json:"platform_id"`
+	ViewID     string  `json:"view_id"`
+	Price      float64 `json:"price"`
+	CreatedAt  int64   `json:"created_at"`
+}
+
+func (Click) EventType() string { return TypeClick }
+func (Click) EventVersion() int { return 1 }
+
+func (c Click) PartitionKey() string {
+	return strconv.Itoa(c.BannerID)
+}
+
+func (c Click) MarshalProto() ([]byte, error) {
+	return marshalProto(c.protoFields())
+}
+
+func (c *Click) UnmarshalProto(payload []byte) error {
+	*c = Click{}
+	return unmarshalProto(payload, c.protoFields())
+}
+
+// protoFields follow message Click of proto/actions.proto
+func (c *Click) protoFields() []protoField {
+	return []protoField{
+		{num: 1, value: &c.ClickID},
+		{num: 2, value: &c.BannerID},
+		{num: 3, value: &c.PlatformID},
+		{num: 4, value: &c.ViewID},
+		{num: 5, value: &c.Price},
+		{num: 6, value: &c.CreatedAt},
+	}
+}
+
+// InvalidClick is a click filtered out as forged or fraudulent, it's not charged
+type InvalidClick struct {
+	BannerID   int    `json:"banner_id"`
+	PlatformID int    `json:"platform_id"`
+	ViewID     string `json:"view_id"`
+	Reason     string `json:"reason"`
+	CreatedAt  int64  `json:"created_at"`
+}
+
+func (InvalidClick) EventType() string { return TypeInvalidClick }
+func (InvalidClick) EventVersion() int { return 1 }
+
+// PartitionKey spreads invalid clicks by platform since they are not counted per banner
+func (i InvalidClick) PartitionKey() string {
+	return strconv.Itoa(i.PlatformID)
+}
+
+// Conversion is reported by the advertiser for the click which led to it
+type Conversion struct {
+	ClickID    string  `json:"click_id"`
+	BannerID   int     `json:"banner_id"`
+	PlatformID int     `json:"platform_id"`
+	ViewID     string  `json:"view_id"`
+	Value      float64 `json:"value"`
+	CreatedAt  int64   `json:"created_at"`
+}
+
+func (Conversion) EventType() string { return TypeConversion }
+func (Conversion) EventVersion() int { return 1 }
+
+func (c Conversion) PartitionKey() string {
+	return strconv.Itoa(c.BannerID)
+}
@@ -0,0 +1,98 @@
+package events
+
+import (
+	"encoding/json"
+	"flag"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var update = flag.Bool("update", false, "update schemas of the events in testdata")
+
+const schemasFile = "schemas.json"
+
+type schema struct {
+	Version int               `json:"version"`
+	Fields  map[string]string `json:"fields"`
+}
+
+func schemaOf(event Event) schema {
+	out := schema{Version: event.EventVersion(), Fields: map[string]string{}}
+
+	t := reflect.TypeOf(event)
+	for i := 0; i < t.NumField(); i++ {
+		name := strings.Split(t.Field(i).Tag.Get("json"), ",")[0]
+		if name == "" || name == "-" {
+			continue
+		}
+
+		out.Fields[name] = t.Field(i).Type.String()
+	}
+
+	return out
+}
+
+// TestCompatibility fails when a field of an event is removed or retyped without bumping its version.
+// Added fields and new versions are locked by running the test with -update
+func TestCompatibility(t *testing.T) {
+	current := map[string]schema{}
+	for _, event := range contracts {
+		current[event.EventType()] = schemaOf(event)
+	}
+
+	path := filepath.Join("testdata", schemasFile)
+
+	if *update {
+		out, err := json.MarshalIndent(current, "", "  ")
+		assert.NoError(t, err)
+		assert.NoError(t, os.WriteFile(path, append(out, '\n'), 0o644))
+
+		return
+	}
+
+	data, err := os.ReadFile(path)
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	var locked map[string]schema
+	if !assert.NoError(t, json.Unmarshal(data, &locked)) {
+		return
+	}
+
+	for eventType, was := range locked {
+		now, ok := current[eventType]
+		if !assert.True(t, ok, "event %s has been removed", eventType) {
+			continue
+		}
+
+		if !assert.GreaterOrEqual(t, now.Version, was.Version, "version of %s has been decreased", eventType) {
+			continue
+		}
+
+		if now.Version > was.Version {
+			t.Errorf("version of %s has been bumped, register the upgrade and run the test with -update", eventType)
+			continue
+		}
+
+		for field, fieldType := range was.Fields {
+			assert.Equal(t, fieldType, now.Fields[field], "field %s of %s has been removed or retyped within version %d",
+				field, eventType, now.Version)
+		}
+
+		for field := range now.Fields {
+			_, ok := was.Fields[field]
+			assert.True(t, ok, "field %s of %s is not locked yet, run the test with -update", field, eventType)
+		}
+	}
+
+	for eventType := range current {
+		_, ok := locked[eventType]
+		assert.True(t, ok, "event %s is not locked yet, run the test with -update", eventType)
+	}
+}
@@ -0,0 +1,162 @@
+package events
+
+import "strconv"
+
+const (
+	TypeBannerStart         = "banner.start"
+	TypeBannerStop          = "banner.stop"
+	TypeBannerLimits        = "banner.limits"
+	TypeBannerCreative      = "banner.creative"
+	TypeCampaignLimits      = "campaign.limits"
+	TypeBannerReachedLimits = "banner.reached_limits"
+	TypeBannerPaused        = "banner.paused"
+	TypeBannerResumed       = "banner.resumed"
+)
+
+// BannerStart is sent by crmad to adeliver to deliver the banner within its limits
+type BannerStart struct {
+	BannerID    int     `json:"banner_id"`
+	UserID      int     `json:"user_id"`
+	CampaignID  int     `json:"campaign_id"`
+	ImgData     []byte  `json:"img_data"`
+	BannerText  string  `json:"banner_text"`
+	BannerURL   string  `json:"banner_url"`
+	LimitShows  int64   `json:"limit_shows"`
+	LimitClicks int64   `json:"limit_clicks"`
+	LimitBudget float64 `json:"limit_budget"`
+	Device      string  `json:"device"`
+	CategoryID  int     `json:"category_id"`
+	StartAt     int64   `json:"start_at"`
+	EndAt       int64   `json:"end_at"`
+	// daily and hourly caps, 0 means no cap
+	DailyShows   int64   `json:"daily_shows"`
+	DailyClicks  int64   `json:"daily_clicks"`
+	DailyBudget  float64 `json:"daily_budget"`
+	HourlyShows  int64   `json:"hourly_shows"`
+	HourlyClicks int64   `json:"hourly_clicks"`
+	HourlyBudget float64 `json:"hourly_budget"`
+	// active hours of each day of the week in TimeZone, empty schedule means always
+	Schedule [7][]int `json:"schedule"`
+	TimeZone string   `json:"timezone"`
+	// country and region codes, empty list means everywhere
+	Geo []string `json:"geo"`
+	// how many times the same visitor can be shown the banner within period in seconds, 0 means no cap
+	FrequencyCap    int   `json:"frequency_cap"`
+	FrequencyPeriod int64 `json:"frequency_period"`
+	// price of a click or thousand shows depending on bid type
+	BidType string  `json:"bid_type"`
+	Bid     float64 `json:"bid"`
+	// UTM parameters of the campaign appended to the landing URL
+	UTM map[string]string `json:"utm"`
+	// budgets of the campaign shared by all of its banners, 0 means no budget
+	CampaignBudget      float64 `json:"campaign_budget"`
+	CampaignDailyBudget float64 `json:"campaign_daily_budget"`
+}
+
+func (BannerStart) EventType() string { return TypeBannerStart }
+func (BannerStart) EventVersion() int { return 1 }
+
+func (b BannerStart) PartitionKey() string {
+	return strconv.Itoa(b.BannerID)
+}
+
+// BannerStop is sent by crmad to adeliver
+type BannerStop struct {
+	BannerID int `json:"banner_id"`
+}
+
+func (BannerStop) EventType() string { return TypeBannerStop }
+func (BannerStop) EventVersion() int { return 1 }
+
+func (b BannerStop) PartitionKey() string {
+	return strconv.Itoa(b.BannerID)
+}
+
+// BannerLimits carries limits and caps changed while the banner is delivered, 0 cap means no cap
+type BannerLimits struct {
+	BannerID     int     `json:"banner_id"`
+	LimitShows   int64   `json:"limit_shows"`
+	LimitClicks  int64   `json:"limit_clicks"`
+	LimitBudget  float64 `json:"limit_budget"`
+	DailyShows   int64   `json:"daily_shows"`
+	DailyClicks  int64   `json:"daily_clicks"`
+	DailyBudget  float64 `json:"daily_budget"`
+	HourlyShows  int64   `json:"hourly_shows"`
+	HourlyClicks int64   `json:"hourly_clicks"`
+	HourlyBudget float64 `json:"hourly_budget"`
+}
+
+func (BannerLimits) EventType() string { return TypeBannerLimits }
+func (BannerLimits) EventVersion() int { return 1 }
+
+func (b BannerLimits) PartitionKey() string {
+	return strconv.Itoa(b.BannerID)
+}
+
+// BannerCreative carries the creative approved while the banner is delivered, it's served from now on
+// without restarting the banner, so its caps and pause are kept
+type BannerCreative struct {
+	BannerID   int    `json:"banner_id"`
+	ImgData    []byte `json:"img_data"`
+	BannerText string `json:"banner_text"`
+	BannerURL  string `json:"banner_url"`
+}
+
+func (BannerCreative) EventType() string { return TypeBannerCreative }
+func (BannerCreative) EventVersion() int { return 1 }
+
+func (b BannerCreative) PartitionKey() string {
+	return strconv.Itoa(b.BannerID)
+}
+
+// CampaignLimits carries budgets of the campaign changed by advertiser, 0 means no budget
+type CampaignLimits struct {
+	CampaignID  int     `json:"campaign_id"`
+	TotalBudget float64 `json:"total_budget"`
+	DailyBudget float64 `json:"daily_budget"`
+}
+
+func (CampaignLimits) EventType() string { return TypeCampaignLimits }
+func (CampaignLimits) EventVersion() int { return 1 }
+
+func (c CampaignLimits) PartitionKey() string {
+	return strconv.Itoa(c.CampaignID)
+}
+
+// BannerReachedLimits is sent by adeliver when the banner has been stopped by one of its limits
+type BannerReachedLimits struct {
+	BannerID int    `json:"banner_id"`
+	Reason   string `json:"reason"`
+}
+
+func (BannerReachedLimits) EventType() string { return TypeBannerReachedLimits }
+func (BannerReachedLimits) EventVersion() int { return 1 }
+
+func (b BannerReachedLimits) PartitionKey() string {
+	return strconv.Itoa(b.BannerID)
+}
+
+// BannerPaused is sent by adeliver when banner reached one of its caps and won't be shown until the next period
+type BannerPaused struct {
+	BannerID int    `json:"banner_id"`
+	Reason   string `json:"reason"`
+	Until    int64  `json:"until"`
+}
+
+func (BannerPaused) EventType() string { return TypeBannerPaused }
+func (BannerPaused) EventVersion() int { return 1 }
+
+func (b BannerPaused) PartitionKey() string {
+	return strconv.Itoa(b.BannerID)
+}
+
+type BannerResumed struct {
+	BannerID int `json:"banner_id"`
+}
+
+func (BannerResumed) EventType() string { return TypeBannerResumed }
+func (BannerResumed) EventVersion() int { return 1 }
+
+func (b BannerResumed) PartitionKey() string {
+	return strconv.Itoa(b.BannerID)
+}
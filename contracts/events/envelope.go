@@ -0,0 +1,228 @@
+// Package events declares the events services exchange through kafka.
+//
+// Every event has a type and a schema version. A field must never be removed or retyped within a version,
+// such change bumps the version and registers an upgrade of the previous one, so messages produced
+// before are still decoded. The payload is plain JSON of the event by default, while the envelope
+// travels in the headers of the message, so consumers which don't know envelopes (e.g. clickhouse) still work.
+// Action events with the highest volume may be sent as protobuf instead, the content type header tells which one.
+package events
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Event is a contract between the producer and consumers of a topic
+type Event interface {
+	EventType() string
+	// EventVersion is the version of the schema the type declares
+	EventVersion() int
+	// PartitionKey keeps events with the same key in order they have been sent
+	PartitionKey() string
+}
+
+// headers of the message carrying the envelope
+const (
+	HeaderID         = "event-id"
+	HeaderType       = "event-type"
+	HeaderVersion    = "event-version"
+	HeaderOccurredAt = "event-occurred-at"
+	HeaderProducer   = "event-producer"
+	// HeaderContentType is the encoding of the payload
+	HeaderContentType = "content-type"
+)
+
+var (
+	ErrUnexpectedType = errors.New("unexpected event type")
+	ErrUnknownVersion = errors.New("unknown event version")
+	// ErrUnsupportedContentType is returned for the encoding the event doesn't have
+	ErrUnsupportedContentType = errors.New("unsupported content type")
+)
+
+// Envelope describes the event carried by the message
+type Envelope struct {
+	ID         string
+	Type       string
+	Version    int
+	OccurredAt int64
+	Producer   string
+	// ContentType is set by the producer to the encoding of the payload
+	ContentType string
+}
+
+// NewEnvelope describes the event which is just occurred
+func NewEnvelope(producer string, event Event) Envelope {
+	return Envelope{
+		ID:         uuid.NewString(),
+		Type:       event.EventType(),
+		Version:    event.EventVersion(),
+		OccurredAt: time.Now().UTC().Unix(),
+		Producer:   producer,
+	}
+}
+
+func (e Envelope) Headers() map[string]string {
+	return map[string]string{
+		HeaderID:          e.ID,
+		HeaderType:        e.Type,
+		HeaderVersion:     strconv.Itoa(e.Version),
+		HeaderOccurredAt:  strconv.FormatInt(e.OccurredAt, 10),
+		HeaderProducer:    e.Producer,
+		HeaderContentType: e.ContentType,
+	}
+}
+
+// ParseEnvelope reads the envelope from headers, messages sent before envelopes have been introduced
+// have no headers and carry the first version of the event encoded as JSON
+func ParseEnvelope(headers map[string]string) (Envelope, error) {
+	envelope := Envelope{
+		ID:          headers[HeaderID],
+		Type:        headers[HeaderType],
+		Version:     1,
+		Producer:    headers[HeaderProducer],
+		ContentType: headers[HeaderContentType],
+	}
+
+	if envelope.ContentType == "" {
+		envelope.ContentType = ContentTypeJSON
+	}
+
+	var err error
+
+	if value, ok := headers[HeaderVersion]; ok {
+		if envelope.Version, err = strconv.Atoi(value); err != nil {
+			return Envelope{}, fmt.Errorf("could not parse version: %w", err)
+		}
+	}
+
+	if value, ok := headers[HeaderOccurredAt]; ok {
+		if envelope.OccurredAt, err = strconv.ParseInt(value, 10, 64); err != nil {
+			return Envelope{}, fmt.Errorf("could not parse occurred at: %w", err)
+		}
+	}
+
+	return envelope, nil
+}
+
+// Encode encodes event with the preferred content type and reports the one it has been encoded with,
+// events without binary encoding fall back to JSON
+func Encode(event Event, contentType string) ([]byte, string, error) {
+	switch contentType {
+	case "", ContentTypeJSON:
+	case ContentTypeProtobuf:
+		if binary, ok := event.(protoMarshaler); ok {
+			out, err := binary.MarshalProto()
+			if err != nil {
+				return nil, "", fmt.Errorf("could not marshal event: %w", err)
+			}
+
+			return out, ContentTypeProtobuf, nil
+		}
+	default:
+		return nil, "", fmt.Errorf("%w: %s", ErrUnsupportedContentType, contentType)
+	}
+
+	out, err := json.Marshal(event)
+	if err != nil {
+		return nil, "", fmt.Errorf("could not marshal event: %w", err)
+	}
+
+	return out, ContentTypeJSON, nil
+}
+
+// Decode reads the payload of the given version into event upgrading it to the version event declares
+func Decode(envelope Envelope, payload []byte, event Event) error {
+	if envelope.Type != "" && envelope.Type != event.EventType() {
+		return fmt.Errorf("%w: %s instead of %s", ErrUnexpectedType, envelope.Type, event.EventType())
+	}
+
+	if envelope.Version < 1 || envelope.Version > event.EventVersion() {
+		return fmt.Errorf("%w: %s of version %d", ErrUnknownVersion, event.EventType(), envelope.Version)
+	}
+
+	switch envelope.ContentType {
+	case "", ContentTypeJSON:
+	case ContentTypeProtobuf:
+		return decodeProto(envelope, payload, event)
+	default:
+		return fmt.Errorf("%w: %s", ErrUnsupportedContentType, envelope.ContentType)
+	}
+
+	for version := envelope.Version; version < event.EventVersion(); version++ {
+		upgrade, ok := upgrades[upgradeKey{eventType: event.EventType(), from: version}]
+		if !ok {
+			continue
+		}
+
+		var err error
+		if payload, err = upgrade(payload); err != nil {
+			return fmt.Errorf("could not upgrade %s from version %d: %w", event.EventType(), version, err)
+		}
+	}
+
+	if err := json.Unmarshal(payload, event); err != nil {
+		return fmt.Errorf("could not unmarshal event: %w", err)
+	}
+
+	return nil
+}
+
+// decodeProto reads the binary payload, protobuf tolerates added fields by itself,
+// so only versions which need no upgrade are decoded
+func decodeProto(envelope Envelope, payload []byte, event Event) error {
+	binary, ok := event.(protoUnmarshaler)
+	if !ok {
+		return fmt.Errorf("%w: %s of %s", ErrUnsupportedContentType, envelope.ContentType, event.EventType())
+	}
+
+	for version := envelope.Version; version < event.EventVersion(); version++ {
+		if _, ok := upgrades[upgradeKey{eventType: event.EventType(), from: version}]; ok {
+			return fmt.Errorf("%w: %s of version %d is not upgraded from %s",
+				ErrUnknownVersion, event.EventType(), version, envelope.ContentType)
+		}
+	}
+
+	if err := binary.UnmarshalProto(payload); err != nil {
+		return fmt.Errorf("could not unmarshal event: %w", err)
+	}
+
+	return nil
+}
+
+type upgradeKey struct {
+	eventType string
+	from      int
+}
+
+// upgrades turn payload of the version into the next one, versions which only add fields need no upgrade
+var upgrades = map[upgradeKey]func(payload []byte) ([]byte, error){
+	{eventType: TypeBannerUpdated, from: 1}: upgradeBannerUpdatedV1,
+}
+
+// contracts lists every event exchanged by services, the compatibility test checks their schemas
+var contracts = []Event{
+	BannerCreated{},
+	BannerRevised{},
+	BannerUpdated{},
+	BannerStart{},
+	BannerStop{},
+	BannerLimits{},
+	BannerCreative{},
+	CampaignLimits{},
+	BannerReachedLimits{},
+	BannerPaused{},
+	BannerResumed{},
+	ServingStart{},
+	ServingStop{},
+	ServingThrottle{},
+	ServingUnthrottle{},
+	View{},
+	Click{},
+	InvalidClick{},
+	Conversion{},
+}
@@ -0,0 +1,80 @@
+package events
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDecode(t *testing.T) {
+	updated := BannerUpdated{BannerID: 1, Approved: true, Comment: "ok", ResolvedAt: 100}
+
+	payload, contentType, err := Encode(updated, ContentTypeProtobuf)
+	assert.NoError(t, err)
+	// updates are not sent often enough to have binary encoding
+	assert.Equal(t, ContentTypeJSON, contentType)
+
+	envelope, err := ParseEnvelope(NewEnvelope("crmadm", updated).Headers())
+	assert.NoError(t, err)
+	assert.Equal(t, "crmadm", envelope.Producer)
+	assert.NotEmpty(t, envelope.ID)
+	assert.Equal(t, ContentTypeJSON, envelope.ContentType)
+
+	var decoded BannerUpdated
+	assert.NoError(t, Decode(envelope, payload, &decoded))
+	assert.Equal(t, updated, decoded)
+
+	var wrongType BannerStop
+	assert.ErrorIs(t, Decode(envelope, payload, &wrongType), ErrUnexpectedType)
+
+	envelope.Version = 3
+	assert.ErrorIs(t, Decode(envelope, payload, &decoded), ErrUnknownVersion)
+}
+
+func TestDecode_Legacy(t *testing.T) {
+	// message sent before envelopes, it has no headers and carries the first version
+	envelope, err := ParseEnvelope(nil)
+	assert.NoError(t, err)
+
+	var decoded BannerUpdated
+	assert.NoError(t, Decode(envelope, []byte(`{"banner_id":1,"valide":true,"comment":"ok"}`), &decoded))
+	assert.Equal(t, BannerUpdated{BannerID: 1, Approved: true, Comment: "ok"}, decoded)
+
+	var created BannerCreated
+	assert.NoError(t, Decode(envelope, []byte(`{"banner_id":2,"user_id":3,"validated":false}`), &created))
+	assert.Equal(t, BannerCreated{BannerID: 2, UserID: 3}, created)
+}
+
+func TestPartitionKey(t *testing.T) {
+	tests := []struct {
+		name  string
+		event Event
+		key   string
+	}{
+		{name: "created", event: BannerCreated{BannerID: 1, UserID: 2}, key: "1"},
+		{name: "revised", event: BannerRevised{BannerID: 2, UserID: 2, CategoryID: 7}, key: "2"},
+		{name: "updated", event: BannerUpdated{BannerID: 3, Approved: true, ResolvedAt: 100}, key: "3"},
+		{name: "start", event: BannerStart{BannerID: 4, UserID: 2}, key: "4"},
+		{name: "stop", event: BannerStop{BannerID: 5}, key: "5"},
+		{name: "limits", event: BannerLimits{BannerID: 6, LimitShows: 100}, key: "6"},
+		{name: "creative", event: BannerCreative{BannerID: 6, BannerText: "new"}, key: "6"},
+		{name: "campaign limits", event: CampaignLimits{CampaignID: 3, TotalBudget: 100}, key: "3"},
+		{name: "reached limits", event: BannerReachedLimits{BannerID: 7}, key: "7"},
+		{name: "paused", event: BannerPaused{BannerID: 8, Until: 100}, key: "8"},
+		{name: "resumed", event: BannerResumed{BannerID: 9}, key: "9"},
+		{name: "serving start", event: ServingStart{BannerID: 10, UserID: 2}, key: "10"},
+		{name: "serving stop", event: ServingStop{BannerID: 11}, key: "11"},
+		{name: "throttle", event: ServingThrottle{BannerID: 12, Probability: 0.5}, key: "12"},
+		{name: "unthrottle", event: ServingUnthrottle{BannerID: 13}, key: "13"},
+		{name: "view", event: View{BannerID: 14, PlatformID: 2}, key: "14"},
+		{name: "click", event: Click{BannerID: 15, PlatformID: 2}, key: "15"},
+		{name: "invalid click", event: InvalidClick{BannerID: 16, PlatformID: 2}, key: "2"},
+		{name: "conversion", event: Conversion{BannerID: 17, PlatformID: 2}, key: "17"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.key, tt.event.PartitionKey())
+		})
+	}
+}
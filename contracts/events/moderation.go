@@ -0,0 +1,86 @@
+package events
+
+import (
+	"encoding/json"
+	"strconv"
+)
+
+const (
+	TypeBannerCreated = "banner.created"
+	TypeBannerRevised = "banner.revised"
+	TypeBannerUpdated = "banner.updated"
+)
+
+// BannerCreated is sent by crmad to moderation
+type BannerCreated struct {
+	BannerID   int    `json:"banner_id"`
+	UserID     int    `json:"user_id"`
+	Validated  bool   `json:"validated"`
+	Device     string `json:"device"`
+	CategoryID int    `json:"category_id"`
+	CreatedAt  int64  `json:"created_at"`
+}
+
+func (BannerCreated) EventType() string { return TypeBannerCreated }
+func (BannerCreated) EventVersion() int { return 1 }
+
+func (b BannerCreated) PartitionKey() string {
+	return strconv.Itoa(b.BannerID)
+}
+
+// ShouldBeAdded tells whether the banner needs moderation, banners of validated users don't
+func (b *BannerCreated) ShouldBeAdded() bool {
+	return !b.Validated
+}
+
+// BannerRevised is sent by crmad to moderation when advertiser changed the creative of already moderated banner
+type BannerRevised struct {
+	BannerID   int    `json:"banner_id"`
+	UserID     int    `json:"user_id"`
+	Device     string `json:"device"`
+	CategoryID int    `json:"category_id"`
+	CreatedAt  int64  `json:"created_at"`
+}
+
+func (BannerRevised) EventType() string { return TypeBannerRevised }
+func (BannerRevised) EventVersion() int { return 1 }
+
+func (b BannerRevised) PartitionKey() string {
+	return strconv.Itoa(b.BannerID)
+}
+
+// BannerUpdated is the resolution of moderator sent by crmadm.
+// Version 2 renamed misspelled `valide` to `approved`
+type BannerUpdated struct {
+	BannerID int    `json:"banner_id"`
+	Approved bool   `json:"approved"`
+	Comment  string `json:"comment"`
+	// ResolvedAt is the time moderator made the resolution, 0 for resolutions made before it was introduced
+	ResolvedAt int64 `json:"resolved_at"`
+}
+
+func (BannerUpdated) EventType() string { return TypeBannerUpdated }
+func (BannerUpdated) EventVersion() int { return 2 }
+
+func (b BannerUpdated) PartitionKey() string {
+	return strconv.Itoa(b.BannerID)
+}
+
+// IsStale tells whether a later resolution has already been applied to the banner
+func (b *BannerUpdated) IsStale(moderatedAt int64) bool {
+	return b.ResolvedAt < moderatedAt
+}
+
+func upgradeBannerUpdatedV1(payload []byte) ([]byte, error) {
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(payload, &fields); err != nil {
+		return nil, err
+	}
+
+	if valide, ok := fields["valide"]; ok {
+		fields["approved"] = valide
+		delete(fields, "valide")
+	}
+
+	return json.Marshal(fields)
+}
@@ -0,0 +1,131 @@
+package events
+
+import (
+	"fmt"
+	"math"
+
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+// content types of the payload, messages without the header are JSON
+const (
+	ContentTypeJSON     = "application/json"
+	ContentTypeProtobuf = "application/x-protobuf"
+)
+
+// protoField binds the field number declared in proto/actions.proto to the field of the event.
+// Value is a pointer to string, int, int64 or float64
+type protoField struct {
+	num   protowire.Number
+	value interface{}
+}
+
+// protoMarshaler is an event which also has the compact binary encoding, it's used by the topics with the highest volume
+type protoMarshaler interface {
+	MarshalProto() ([]byte, error)
+}
+
+type protoUnmarshaler interface {
+	UnmarshalProto(payload []byte) error
+}
+
+// marshalProto encodes fields the way proto3 does, so zero values are omitted
+func marshalProto(fields []protoField) ([]byte, error) {
+	var out []byte
+
+	for _, field := range fields {
+		switch value := field.value.(type) {
+		case *string:
+			if *value != "" {
+				out = protowire.AppendTag(out, field.num, protowire.BytesType)
+				out = protowire.AppendString(out, *value)
+			}
+		case *int:
+			if *value != 0 {
+				out = protowire.AppendTag(out, field.num, protowire.VarintType)
+				out = protowire.AppendVarint(out, uint64(*value))
+			}
+		case *int64:
+			if *value != 0 {
+				out = protowire.AppendTag(out, field.num, protowire.VarintType)
+				out = protowire.AppendVarint(out, uint64(*value))
+			}
+		case *float64:
+			if *value != 0 {
+				out = protowire.AppendTag(out, field.num, protowire.Fixed64Type)
+				out = protowire.AppendFixed64(out, math.Float64bits(*value))
+			}
+		default:
+			return nil, fmt.Errorf("field %d has unsupported type %T", field.num, field.value)
+		}
+	}
+
+	return out, nil
+}
+
+// unmarshalProto reads payload into fields, unknown fields are skipped so producers may add them first
+func unmarshalProto(payload []byte, fields []protoField) error {
+	byNum := make(map[protowire.Number]interface{}, len(fields))
+	for _, field := range fields {
+		byNum[field.num] = field.value
+	}
+
+	for len(payload) > 0 {
+		num, typ, n := protowire.ConsumeTag(payload)
+		if n < 0 {
+			return fmt.Errorf("could not read tag: %w", protowire.ParseError(n))
+		}
+
+		payload = payload[n:]
+
+		switch value := byNum[num].(type) {
+		case *string:
+			if typ == protowire.BytesType {
+				*value, n = protowire.ConsumeString(payload)
+				break
+			}
+
+			n = protowire.ConsumeFieldValue(num, typ, payload)
+		case *int:
+			if typ == protowire.VarintType {
+				var v uint64
+				v, n = protowire.ConsumeVarint(payload)
+				*value = int(int64(v))
+
+				break
+			}
+
+			n = protowire.ConsumeFieldValue(num, typ, payload)
+		case *int64:
+			if typ == protowire.VarintType {
+				var v uint64
+				v, n = protowire.ConsumeVarint(payload)
+				*value = int64(v)
+
+				break
+			}
+
+			n = protowire.ConsumeFieldValue(num, typ, payload)
+		case *float64:
+			if typ == protowire.Fixed64Type {
+				var v uint64
+				v, n = protowire.ConsumeFixed64(payload)
+				*value = math.Float64frombits(v)
+
+				break
+			}
+
+			n = protowire.ConsumeFieldValue(num, typ, payload)
+		default:
+			n = protowire.ConsumeFieldValue(num, typ, payload)
+		}
+
+		if n < 0 {
+			return fmt.Errorf("could not read field %d: %w", num, protowire.ParseError(n))
+		}
+
+		payload = payload[n:]
+	}
+
+	return nil
+}
@@ -0,0 +1,69 @@
+package events
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+func TestDecode_Protobuf(t *testing.T) {
+	tests := []struct {
+		name    string
+		event   Event
+		decoded Event
+	}{
+		{
+			name: "view",
+			event: View{ViewID: "5f0c", BannerID: 1, PlatformID: 2, UserAgent: "curl", Device: "desktop", OS: "linux",
+				Browser: "firefox", Country: "DE", Region: "BE", CreatedAt: 100},
+			decoded: &View{},
+		},
+		{
+			name:    "click",
+			event:   &Click{ClickID: "a1", BannerID: 3, PlatformID: 4, ViewID: "5f0c", Price: 0.25, CreatedAt: 200},
+			decoded: &Click{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			payload, contentType, err := Encode(tt.event, ContentTypeProtobuf)
+			assert.NoError(t, err)
+			assert.Equal(t, ContentTypeProtobuf, contentType)
+
+			envelope := NewEnvelope("adshow", tt.event)
+			envelope.ContentType = contentType
+
+			parsed, err := ParseEnvelope(envelope.Headers())
+			assert.NoError(t, err)
+			assert.NoError(t, Decode(parsed, payload, tt.decoded))
+
+			// every field of the event must have a number in proto/actions.proto
+			assert.Equal(t, reflect.Indirect(reflect.ValueOf(tt.event)).Interface(), reflect.ValueOf(tt.decoded).Elem().Interface())
+		})
+	}
+}
+
+func TestDecode_ProtobufUnknownFields(t *testing.T) {
+	view := View{ViewID: "5f0c", BannerID: 1, CreatedAt: 100}
+
+	payload, err := view.MarshalProto()
+	assert.NoError(t, err)
+
+	// field added by a newer producer
+	payload = protowire.AppendTag(payload, 99, protowire.BytesType)
+	payload = protowire.AppendString(payload, "new")
+
+	var decoded View
+	assert.NoError(t, Decode(Envelope{Type: TypeView, Version: 1, ContentType: ContentTypeProtobuf}, payload, &decoded))
+	assert.Equal(t, view, decoded)
+
+	var updated BannerUpdated
+	err = Decode(Envelope{Version: 1, ContentType: ContentTypeProtobuf}, payload, &updated)
+	assert.ErrorIs(t, err, ErrUnsupportedContentType)
+
+	_, _, err = Encode(view, "application/xml")
+	assert.ErrorIs(t, err, ErrUnsupportedContentType)
+}
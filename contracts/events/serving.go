@@ -0,0 +1,81 @@
+package events
+
+import "strconv"
+
+const (
+	TypeServingStart      = "serving.start"
+	TypeServingStop       = "serving.stop"
+	TypeServingThrottle   = "serving.throttle"
+	TypeServingUnthrottle = "serving.unthrottle"
+)
+
+// ServingStart is sent by adeliver to adshow and adclick to serve the banner
+type ServingStart struct {
+	BannerID    int     `json:"banner_id"`
+	UserID      int     `json:"user_id"`
+	ImgData     []byte  `json:"img_data"`
+	BannerText  string  `json:"banner_text"`
+	BannerURL   string  `json:"banner_url"`
+	LimitShows  int64   `json:"limit_shows"`
+	LimitClicks int64   `json:"limit_clicks"`
+	LimitBudget float64 `json:"limit_budget"`
+	Device      string  `json:"device"`
+	CategoryID  int     `json:"category_id"`
+	StartAt     int64   `json:"start_at"`
+	EndAt       int64   `json:"end_at"`
+	// active hours of each day of the week in TimeZone, empty schedule means always
+	Schedule [7][]int `json:"schedule"`
+	TimeZone string   `json:"timezone"`
+	// country and region codes, empty list means everywhere
+	Geo []string `json:"geo"`
+	// how many times the same visitor can be shown the banner within period in seconds, 0 means no cap
+	FrequencyCap    int   `json:"frequency_cap"`
+	FrequencyPeriod int64 `json:"frequency_period"`
+	// price of a click or thousand shows depending on bid type
+	BidType string  `json:"bid_type"`
+	Bid     float64 `json:"bid"`
+	// UTM parameters of the campaign appended to the landing URL
+	UTM map[string]string `json:"utm"`
+}
+
+func (ServingStart) EventType() string { return TypeServingStart }
+func (ServingStart) EventVersion() int { return 1 }
+
+func (s ServingStart) PartitionKey() string {
+	return strconv.Itoa(s.BannerID)
+}
+
+type ServingStop struct {
+	BannerID int `json:"banner_id"`
+}
+
+func (ServingStop) EventType() string { return TypeServingStop }
+func (ServingStop) EventVersion() int { return 1 }
+
+func (s ServingStop) PartitionKey() string {
+	return strconv.Itoa(s.BannerID)
+}
+
+// ServingThrottle makes adshow serve the banner with the probability to pace its budget
+type ServingThrottle struct {
+	BannerID    int     `json:"banner_id"`
+	Probability float64 `json:"probability"`
+}
+
+func (ServingThrottle) EventType() string { return TypeServingThrottle }
+func (ServingThrottle) EventVersion() int { return 1 }
+
+func (s ServingThrottle) PartitionKey() string {
+	return strconv.Itoa(s.BannerID)
+}
+
+type ServingUnthrottle struct {
+	BannerID int `json:"banner_id"`
+}
+
+func (ServingUnthrottle) EventType() string { return TypeServingUnthrottle }
+func (ServingUnthrottle) EventVersion() int { return 1 }
+
+func (s ServingUnthrottle) PartitionKey() string {
+	return strconv.Itoa(s.BannerID)
+}
@@ -12,6 +12,7 @@ import (
 	"github.com/crxfoz/teaserad/adeliver/pkg/kafka"
 	"github.com/crxfoz/teaserad/crmad/internal/delivery/http"
 	kafkaController "github.com/crxfoz/teaserad/crmad/internal/delivery/kafka"
+	"github.com/crxfoz/teaserad/crmad/internal/delivery/scheduler"
 	"github.com/crxfoz/teaserad/crmad/internal/domain/entity"
 	"github.com/crxfoz/teaserad/crmad/internal/repo/mysql"
 	"github.com/crxfoz/teaserad/crmad/internal/services/jwt"
@@ -19,6 +20,7 @@ import (
 	"github.com/crxfoz/teaserad/crmad/pkg/auth/middleware"
 	"github.com/crxfoz/teaserad/crmad/pkg/gateways/adeliver"
 	"github.com/crxfoz/teaserad/crmad/pkg/gateways/crmadm"
+	"github.com/crxfoz/teaserad/crmad/pkg/outbox"
 	"github.com/crxfoz/teaserad/crmad/pkg/tracer"
 	_ "github.com/go-sql-driver/mysql"
 	"github.com/jmoiron/sqlx"
@@ -59,16 +61,6 @@ func main() {
 	}
 
 	wrappedKafkaProducer := otelsarama.WrapSyncProducer(kafkaCfg, kafkaProducer)
-	crmAdmGateway := crmadm.New(wrappedKafkaProducer)
-
-	kafkaProducerAdeliver, err := sarama.NewSyncProducer(kafkaBrokers, kafkaCfg)
-	if err != nil {
-		cmdLogger.Fatalw("could not connect to kafka",
-			"err", err)
-	}
-	wrappedkafkaProducerAdeliver := otelsarama.WrapSyncProducer(kafkaCfg, kafkaProducerAdeliver)
-
-	adeliverGateway := adeliver.New(wrappedkafkaProducerAdeliver)
 
 	sqlConn, err := sqlx.Connect("mysql",
 		fmt.Sprintf("%s:%s@(%s:%s)/%s",
@@ -84,9 +76,24 @@ func main() {
 
 	authManager := jwt.NewJWTManager("123", time.Hour*24*7) // TODO: add token to envs
 	userRepo := mysql.New(sqlConn)
-	userSvc := user.New(userRepo, authManager, crmAdmGateway, adeliverGateway, userRepo)
+
+	// events are stored within transactions of the changes they are about and relayed to kafka after commit
+	eventOutbox := outbox.New(userRepo)
+	crmAdmGateway := crmadm.New(eventOutbox)
+	adeliverGateway := adeliver.New(eventOutbox)
+
+	outboxRelay := outbox.NewRelay(userRepo, wrappedKafkaProducer, time.Second, logger.Named("crmad-outbox-relay"))
+	go outboxRelay.Run()
+
+	userSvc := user.New(userRepo, authManager, crmAdmGateway, adeliverGateway, userRepo, userRepo)
 	authMiddleware := middleware.New[entity.User, entity.UserContext](authManager)
-	srv := http.New(context.Background(), authMiddleware, userSvc, logger.Named("crmad-delivery-http"))
+	paymentSecret := os.Getenv("PAYMENT_CALLBACK_SECRET")
+	if paymentSecret == "" {
+		cmdLogger.Fatalw("PAYMENT_CALLBACK_SECRET is not set")
+		return
+	}
+
+	srv := http.New(context.Background(), authMiddleware, userSvc, []byte(paymentSecret), logger.Named("crmad-delivery-http"))
 
 	go func() {
 		if err := srv.Run(8080); err != nil {
@@ -94,7 +101,11 @@ func main() {
 		}
 	}()
 
-	kafBuilder := kafka.New(logger)
+	// starts and stops banners on boundaries of their flight dates and schedule
+	bannerScheduler := scheduler.New(userSvc, time.Minute, logger.Named("crmad-delivery-scheduler"))
+	go bannerScheduler.Run()
+
+	kafBuilder := kafka.New(logger, kafkaProducer)
 	kfController := kafkaController.New(userSvc)
 
 	// consumer to listen events from crmadm service
@@ -128,6 +139,8 @@ func main() {
 
 	kafAdeliverConsumer, err := kafBuilder.NewConsumer("crmad-consumer", kafConsumerAdeliver, func(sess *kafka.Session) error {
 		sess.AddRoute("adeliver.banner.limits", kfController.OnBannerReachedLimits)
+		sess.AddRoute("adeliver.banner.paused", kfController.OnBannerPaused)
+		sess.AddRoute("adeliver.banner.resumed", kfController.OnBannerResumed)
 		return nil
 	})
 	if err != nil {
@@ -141,6 +154,28 @@ func main() {
 		}
 	}()
 
+	// consumer to charge advertisers for clicks from adclick service
+	kafConsumerBilling, err := sarama.NewConsumerGroup(kafkaBrokers, "crmad-billing", kafkaCfg)
+	if err != nil {
+		cmdLogger.Errorw("could not create consumer group", "err", err)
+		return
+	}
+
+	kafBillingConsumer, err := kafBuilder.NewConsumer("crmad-consumer", kafConsumerBilling, func(sess *kafka.Session) error {
+		sess.AddRoute("adclick.action.click", kfController.OnClick)
+		return nil
+	})
+	if err != nil {
+		cmdLogger.Fatalw("could not start consumer", "err", err, "kind", "billing")
+		return
+	}
+
+	go func() {
+		if err := kafBillingConsumer.Start(); err != nil {
+			cmdLogger.Errorw("kafka listener stopped", "err", err)
+		}
+	}()
+
 	interrupt := make(chan os.Signal, 1)
 	signal.Notify(interrupt, os.Interrupt, syscall.SIGTERM)
 
@@ -149,6 +184,12 @@ func main() {
 		cmdLogger.Info("app stopped - signal:", s.String())
 	}
 
+	bannerScheduler.Stop()
+
+	if err := kafBillingConsumer.Stop(); err != nil {
+		cmdLogger.Errorw("could not stop consumer gracefuly", "err", err, "kind", "billing")
+	}
+
 	if err := kafAdeliverConsumer.Stop(); err != nil {
 		cmdLogger.Errorw("could not stop consumer gracefuly", "err", err, "kind", "adeliver")
 	}
@@ -157,12 +198,10 @@ func main() {
 		cmdLogger.Errorw("could not stop consumer gracefuly", "err", err, "kind", "crmadm")
 	}
 
-	if err := kafkaProducerAdeliver.Close(); err != nil {
-		cmdLogger.Errorw("could not stop producer gracefuly", "err", err, "kind", "adeliver")
-	}
+	outboxRelay.Stop()
 
 	if err := kafkaProducer.Close(); err != nil {
-		cmdLogger.Errorw("could not stop producer gracefuly", "err", err, "kind", "crmadm")
+		cmdLogger.Errorw("could not stop producer gracefuly", "err", err, "kind", "outbox")
 	}
 
 	if err := tp.Shutdown(context.Background()); err != nil {
@@ -0,0 +1,128 @@
+package http
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"net/http"
+
+	"github.com/crxfoz/teaserad/crmad/internal/domain/entity"
+	"github.com/labstack/echo/v4"
+	"go.opentelemetry.io/otel"
+)
+
+func (s *Server) GetBalance(c echo.Context, userCtx entity.UserContext) error {
+	spanCtx, span := otel.Tracer(tracerName).Start(c.Request().Context(), "GetBalance")
+	defer span.End()
+
+	balance, err := s.userSvc.GetBalance(spanCtx, userCtx.ID)
+	if err != nil {
+		s.logger.Errorw("could not get balance",
+			"endpoint", "GetBalance",
+			"err", err)
+		return c.JSON(http.StatusInternalServerError, HTTPError{"could not get balance"})
+	}
+
+	return c.JSON(http.StatusOK, map[string]float64{
+		"balance": balance,
+	})
+}
+
+func (s *Server) GetInvoices(c echo.Context, userCtx entity.UserContext) error {
+	spanCtx, span := otel.Tracer(tracerName).Start(c.Request().Context(), "GetInvoices")
+	defer span.End()
+
+	invoices, err := s.userSvc.GetInvoices(spanCtx, userCtx.ID)
+	if err != nil {
+		s.logger.Errorw("could not get invoices",
+			"endpoint", "GetInvoices",
+			"err", err)
+		return c.JSON(http.StatusInternalServerError, HTTPError{"could not get invoices"})
+	}
+
+	return c.JSON(http.StatusOK, invoices)
+}
+
+func (s *Server) AddInvoice(c echo.Context, userCtx entity.UserContext) error {
+	spanCtx, span := otel.Tracer(tracerName).Start(c.Request().Context(), "AddInvoice")
+	defer span.End()
+
+	var invoiceData NewInvoice
+
+	if err := c.Bind(&invoiceData); err != nil {
+		s.logger.Errorw("wrong request",
+			"endpoint", "AddInvoice",
+			"err", err)
+		return c.JSON(http.StatusUnprocessableEntity, HTTPError{"wrong data"})
+	}
+
+	invoice, err := s.userSvc.CreateInvoice(spanCtx, userCtx.ID, invoiceData.Amount)
+	if err != nil {
+		s.logger.Errorw("could not create invoice",
+			"endpoint", "AddInvoice",
+			"err", err)
+		return c.JSON(http.StatusInternalServerError, HTTPError{"could not create invoice"})
+	}
+
+	return c.JSON(http.StatusCreated, invoice)
+}
+
+func (s *Server) PaymentCallback(c echo.Context) error {
+	spanCtx, span := otel.Tracer(tracerName).Start(c.Request().Context(), "PaymentCallback")
+	defer span.End()
+
+	body, err := io.ReadAll(c.Request().Body)
+	if err != nil {
+		s.logger.Errorw("could not read request",
+			"endpoint", "PaymentCallback",
+			"err", err)
+		return c.JSON(http.StatusBadRequest, HTTPError{"wrong data"})
+	}
+
+	if !s.validPaymentSignature(body, c.Request().Header.Get(PaymentSignatureHeader)) {
+		s.logger.Errorw("wrong signature",
+			"endpoint", "PaymentCallback")
+		return c.JSON(http.StatusUnauthorized, HTTPError{"wrong signature"})
+	}
+
+	var callback PaymentCallback
+
+	if err := json.Unmarshal(body, &callback); err != nil {
+		s.logger.Errorw("wrong request",
+			"endpoint", "PaymentCallback",
+			"err", err)
+		return c.JSON(http.StatusUnprocessableEntity, HTTPError{"wrong data"})
+	}
+
+	if err := s.userSvc.InvoicePaid(spanCtx, callback.Key); err != nil {
+		s.logger.Errorw("could not accept payment",
+			"endpoint", "PaymentCallback",
+			"err", err)
+		return c.JSON(http.StatusInternalServerError, HTTPError{"could not accept payment"})
+	}
+
+	return c.JSON(http.StatusOK, map[string]string{
+		"status": "ok",
+	})
+}
+
+// PaymentSignatureHeader carries hex encoded HMAC-SHA256 of the callback body signed with the shared secret
+const PaymentSignatureHeader = "X-Payment-Signature"
+
+func (s *Server) validPaymentSignature(body []byte, signature string) bool {
+	if len(s.paymentSecret) == 0 {
+		return false
+	}
+
+	sig, err := hex.DecodeString(signature)
+	if err != nil {
+		return false
+	}
+
+	mac := hmac.New(sha256.New, s.paymentSecret)
+	mac.Write(body)
+
+	return hmac.Equal(sig, mac.Sum(nil))
+}
@@ -0,0 +1,197 @@
+package http
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/crxfoz/teaserad/crmad/internal/domain/entity"
+	"github.com/labstack/echo/v4"
+	"go.opentelemetry.io/otel"
+)
+
+type CampaignStart struct {
+	CampaignID int `json:"campaign_id"`
+}
+
+type CampaignStop struct {
+	CampaignID int `json:"campaign_id"`
+}
+
+func (s *Server) GetCampaigns(c echo.Context, userCtx entity.UserContext) error {
+	spanCtx, span := otel.Tracer(tracerName).Start(c.Request().Context(), "GetCampaigns")
+	defer span.End()
+
+	campaigns, err := s.userSvc.GetCampaigns(spanCtx, userCtx.ID)
+	if err != nil {
+		s.logger.Errorw("could not get campaigns",
+			"endpoint", "GetCampaigns",
+			"err", err)
+		return c.JSON(http.StatusInternalServerError, HTTPError{"could not get campaigns"})
+	}
+
+	return c.JSON(http.StatusOK, campaigns)
+}
+
+func (s *Server) GetCampaign(c echo.Context, userCtx entity.UserContext) error {
+	spanCtx, span := otel.Tracer(tracerName).Start(c.Request().Context(), "GetCampaign")
+	defer span.End()
+
+	campaignID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusNotFound, HTTPError{"wrong campaign id"})
+	}
+
+	campaign, err := s.userSvc.GetCampaign(spanCtx, campaignID, userCtx.ID)
+	if err != nil {
+		s.logger.Errorw("could not get campaign",
+			"endpoint", "GetCampaign",
+			"err", err)
+		return c.JSON(http.StatusNotFound, HTTPError{"could not get campaign"})
+	}
+
+	return c.JSON(http.StatusOK, campaign)
+}
+
+func (s *Server) AddCampaign(c echo.Context, userCtx entity.UserContext) error {
+	spanCtx, span := otel.Tracer(tracerName).Start(c.Request().Context(), "AddCampaign")
+	defer span.End()
+
+	var campaignData NewCampaign
+
+	if err := c.Bind(&campaignData); err != nil {
+		s.logger.Errorw("wrong request",
+			"endpoint", "AddCampaign",
+			"err", err)
+		return c.JSON(http.StatusUnprocessableEntity, HTTPError{"wrong data"})
+	}
+
+	campaignID, err := s.userSvc.CreateCampaign(spanCtx, campaignData.toEntity(0, userCtx.ID))
+	if err != nil {
+		s.logger.Errorw("could not create campaign",
+			"endpoint", "AddCampaign",
+			"err", err)
+		return c.JSON(http.StatusInternalServerError, HTTPError{"could not create campaign"})
+	}
+
+	return c.JSON(http.StatusOK, map[string]int{
+		"campaign_id": campaignID,
+	})
+}
+
+func (s *Server) UpdateCampaign(c echo.Context, userCtx entity.UserContext) error {
+	spanCtx, span := otel.Tracer(tracerName).Start(c.Request().Context(), "UpdateCampaign")
+	defer span.End()
+
+	campaignID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusNotFound, HTTPError{"wrong campaign id"})
+	}
+
+	var campaignData NewCampaign
+
+	if err := c.Bind(&campaignData); err != nil {
+		s.logger.Errorw("wrong request",
+			"endpoint", "UpdateCampaign",
+			"err", err)
+		return c.JSON(http.StatusUnprocessableEntity, HTTPError{"wrong data"})
+	}
+
+	if err := s.userSvc.UpdateCampaign(spanCtx, campaignData.toEntity(campaignID, userCtx.ID)); err != nil {
+		s.logger.Errorw("could not update campaign",
+			"endpoint", "UpdateCampaign",
+			"err", err)
+		return c.JSON(http.StatusInternalServerError, HTTPError{"could not update campaign"})
+	}
+
+	return c.JSON(http.StatusOK, map[string]string{
+		"status": "ok",
+	})
+}
+
+func (s *Server) DeleteCampaign(c echo.Context, userCtx entity.UserContext) error {
+	spanCtx, span := otel.Tracer(tracerName).Start(c.Request().Context(), "DeleteCampaign")
+	defer span.End()
+
+	campaignID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusNotFound, HTTPError{"wrong campaign id"})
+	}
+
+	if err := s.userSvc.DeleteCampaign(spanCtx, campaignID, userCtx.ID); err != nil {
+		s.logger.Errorw("could not delete campaign",
+			"endpoint", "DeleteCampaign",
+			"err", err)
+		return c.JSON(http.StatusInternalServerError, HTTPError{"could not delete campaign"})
+	}
+
+	return c.JSON(http.StatusOK, map[string]string{
+		"status": "ok",
+	})
+}
+
+func (s *Server) CampaignStart(c echo.Context, userCtx entity.UserContext) error {
+	spanCtx, span := otel.Tracer(tracerName).Start(c.Request().Context(), "CampaignStart")
+	defer span.End()
+
+	var start CampaignStart
+
+	if err := c.Bind(&start); err != nil {
+		s.logger.Errorw("wrong data",
+			"endpoint", "CampaignStart",
+			"err", err)
+		return c.JSON(http.StatusUnprocessableEntity, HTTPError{"wrong entity"})
+	}
+
+	if err := s.userSvc.CampaignStart(spanCtx, start.CampaignID, userCtx.ID); err != nil {
+		s.logger.Errorw("could not start campaign",
+			"endpoint", "CampaignStart",
+			"err", err)
+		return c.JSON(http.StatusInternalServerError, HTTPError{"could not start campaign"})
+	}
+
+	return c.JSON(http.StatusOK, map[string]string{
+		"status": "ok",
+	})
+}
+
+func (s *Server) CampaignStop(c echo.Context, userCtx entity.UserContext) error {
+	spanCtx, span := otel.Tracer(tracerName).Start(c.Request().Context(), "CampaignStop")
+	defer span.End()
+
+	var stop CampaignStop
+
+	if err := c.Bind(&stop); err != nil {
+		s.logger.Errorw("wrong data",
+			"endpoint", "CampaignStop",
+			"err", err)
+		return c.JSON(http.StatusUnprocessableEntity, HTTPError{"wrong entity"})
+	}
+
+	if err := s.userSvc.CampaignStop(spanCtx, stop.CampaignID, userCtx.ID); err != nil {
+		s.logger.Errorw("could not stop campaign",
+			"endpoint", "CampaignStop",
+			"err", err)
+		return c.JSON(http.StatusInternalServerError, HTTPError{"could not stop campaign"})
+	}
+
+	return c.JSON(http.StatusOK, map[string]string{
+		"status": "ok",
+	})
+}
+
+func (nc NewCampaign) toEntity(campaignID int, userID int) *entity.Campaign {
+	return &entity.Campaign{
+		ID:          campaignID,
+		UserID:      userID,
+		Name:        nc.Name,
+		TotalBudget: nc.TotalBudget,
+		DailyBudget: nc.DailyBudget,
+		StartAt:     nc.StartAt,
+		EndAt:       nc.EndAt,
+		Device:      nc.Device,
+		CategoryID:  nc.CategoryID,
+		UTMSource:   nc.UTMSource,
+		UTMMedium:   nc.UTMMedium,
+		UTMCampaign: nc.UTMCampaign,
+	}
+}
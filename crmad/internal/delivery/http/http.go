@@ -20,9 +20,22 @@ type UserService interface {
 	GetBanners(ctx context.Context, userID int) ([]*entity.Banner, error)
 	CreateBanner(ctx context.Context, isUserValidated bool, banner *entity.Banner) (int, error)
 	GetBanner(ctx context.Context, bannerID int) (*entity.Banner, error)
+	UpdateBanner(ctx context.Context, isUserValidated bool, bannerID int, userID int, patch *entity.BannerPatch) (bool, error)
+	GetRevision(ctx context.Context, bannerID int) (*entity.BannerRevision, error)
 	BannerStart(ctx context.Context, bannerID int, userID int) error
 	BannerStop(ctx context.Context, bannerID int, userID int) error
 	GetCategories(ctx context.Context) ([]*entity.WebsiteCategory, error)
+	CreateCampaign(ctx context.Context, campaign *entity.Campaign) (int, error)
+	GetCampaigns(ctx context.Context, userID int) ([]*entity.Campaign, error)
+	GetCampaign(ctx context.Context, campaignID int, userID int) (*entity.Campaign, error)
+	UpdateCampaign(ctx context.Context, campaign *entity.Campaign) error
+	DeleteCampaign(ctx context.Context, campaignID int, userID int) error
+	CampaignStart(ctx context.Context, campaignID int, userID int) error
+	CampaignStop(ctx context.Context, campaignID int, userID int) error
+	CreateInvoice(ctx context.Context, userID int, amount float64) (*entity.Invoice, error)
+	GetInvoices(ctx context.Context, userID int) ([]*entity.Invoice, error)
+	GetBalance(ctx context.Context, userID int) (float64, error)
+	InvoicePaid(ctx context.Context, key string) error
 }
 
 type Server struct {
@@ -31,9 +44,11 @@ type Server struct {
 	userSvc        UserService
 	e              *echo.Echo
 	logger         domain.Logger
+	paymentSecret  []byte
 }
 
-func New(ctx context.Context, authMiddleware *middleware.AuthMiddleware[entity.User, entity.UserContext], userSvc UserService, logger domain.Logger) *Server {
+// New creates the server, paymentSecret is shared with the payment provider to sign its callbacks
+func New(ctx context.Context, authMiddleware *middleware.AuthMiddleware[entity.User, entity.UserContext], userSvc UserService, paymentSecret []byte, logger domain.Logger) *Server {
 	e := echo.New()
 	e.HideBanner = true
 
@@ -43,6 +58,7 @@ func New(ctx context.Context, authMiddleware *middleware.AuthMiddleware[entity.U
 		userSvc:        userSvc,
 		e:              e,
 		logger:         logger,
+		paymentSecret:  paymentSecret,
 	}
 }
 
@@ -51,6 +67,7 @@ func (s *Server) Run(port int) error {
 	p.Use(s.e)
 
 	s.e.GET("/static/banner/:id", s.ShowBannerImg)
+	s.e.GET("/static/banner/:id/revision", s.ShowRevisionImg)
 
 	apiV1 := s.e.Group("/api/v1")
 
@@ -58,10 +75,24 @@ func (s *Server) Run(port int) error {
 	apiV1.POST("/login", s.UserLogin)
 	apiV1.GET("/banners", s.authMiddleware.Do(s.GetBanners))
 	apiV1.POST("/banners", s.authMiddleware.Do(s.AddBanner))
+	apiV1.PATCH("/banners/:id", s.authMiddleware.Do(s.UpdateBanner))
 	apiV1.POST("/banners/start", s.authMiddleware.Do(s.BannerStart))
 	apiV1.POST("/banners/stop", s.authMiddleware.Do(s.BannerStop))
 	apiV1.POST("/categories", s.authMiddleware.Do(s.AddCategory))
 	apiV1.GET("/categories", s.authMiddleware.Do(s.GetCategories))
+	apiV1.GET("/campaigns", s.authMiddleware.Do(s.GetCampaigns))
+	apiV1.POST("/campaigns", s.authMiddleware.Do(s.AddCampaign))
+	apiV1.POST("/campaigns/start", s.authMiddleware.Do(s.CampaignStart))
+	apiV1.POST("/campaigns/stop", s.authMiddleware.Do(s.CampaignStop))
+	apiV1.GET("/campaigns/:id", s.authMiddleware.Do(s.GetCampaign))
+	apiV1.PUT("/campaigns/:id", s.authMiddleware.Do(s.UpdateCampaign))
+	apiV1.DELETE("/campaigns/:id", s.authMiddleware.Do(s.DeleteCampaign))
+	apiV1.GET("/balance", s.authMiddleware.Do(s.GetBalance))
+	apiV1.GET("/invoices", s.authMiddleware.Do(s.GetInvoices))
+	apiV1.POST("/invoices", s.authMiddleware.Do(s.AddInvoice))
+
+	// TODO: fake payment provider, replace with a real one. Callbacks are signed with the shared secret
+	apiV1.POST("/payments/callback", s.PaymentCallback)
 
 	return s.e.Start(fmt.Sprintf(":%d", port))
 }
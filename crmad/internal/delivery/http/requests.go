@@ -19,4 +19,65 @@ type NewBanner struct {
 	LimitBudget float64 `json:"limit_budget"`
 	Device      string  `json:"device"`
 	CategoryID  int     `json:"category_id"`
+	CampaignID  int     `json:"campaign_id"`
+
+	DailyShows   int64   `json:"daily_shows"`
+	DailyClicks  int64   `json:"daily_clicks"`
+	DailyBudget  float64 `json:"daily_budget"`
+	HourlyShows  int64   `json:"hourly_shows"`
+	HourlyClicks int64   `json:"hourly_clicks"`
+	HourlyBudget float64 `json:"hourly_budget"`
+
+	StartAt  int64    `json:"start_at"`
+	EndAt    int64    `json:"end_at"`
+	Schedule [7][]int `json:"schedule"`
+	TimeZone string   `json:"timezone"`
+	Geo      []string `json:"geo"`
+
+	FrequencyCap    int   `json:"frequency_cap"`
+	FrequencyPeriod int64 `json:"frequency_period"`
+
+	BidType string  `json:"bid_type"`
+	Bid     float64 `json:"bid"`
+
+	StartOnApproval bool `json:"start_on_approval"`
+}
+
+// BannerPatch contains only the fields advertiser wants to change
+type BannerPatch struct {
+	ImgData    *string `json:"img_data"`
+	BannerText *string `json:"banner_text"`
+	BannerURL  *string `json:"banner_url"`
+
+	LimitShows   *int64   `json:"limit_shows"`
+	LimitClicks  *int64   `json:"limit_clicks"`
+	LimitBudget  *float64 `json:"limit_budget"`
+	DailyShows   *int64   `json:"daily_shows"`
+	DailyClicks  *int64   `json:"daily_clicks"`
+	DailyBudget  *float64 `json:"daily_budget"`
+	HourlyShows  *int64   `json:"hourly_shows"`
+	HourlyClicks *int64   `json:"hourly_clicks"`
+	HourlyBudget *float64 `json:"hourly_budget"`
+}
+
+type NewCampaign struct {
+	Name        string  `json:"name"`
+	TotalBudget float64 `json:"total_budget"`
+	DailyBudget float64 `json:"daily_budget"`
+	StartAt     int64   `json:"start_at"`
+	EndAt       int64   `json:"end_at"`
+	Device      string  `json:"device"`
+	CategoryID  int     `json:"category_id"`
+
+	UTMSource   string `json:"utm_source"`
+	UTMMedium   string `json:"utm_medium"`
+	UTMCampaign string `json:"utm_campaign"`
+}
+
+type NewInvoice struct {
+	Amount float64 `json:"amount"`
+}
+
+type PaymentCallback struct {
+	Key string `json:"key"`
 }
@@ -0,0 +1,92 @@
+package http
+
+import (
+	"encoding/base64"
+	"net/http"
+	"strconv"
+
+	"github.com/crxfoz/teaserad/crmad/internal/domain/entity"
+	"github.com/labstack/echo/v4"
+	"go.opentelemetry.io/otel"
+)
+
+func (s *Server) UpdateBanner(c echo.Context, userCtx entity.UserContext) error {
+	spanCtx, span := otel.Tracer(tracerName).Start(c.Request().Context(), "UpdateBanner")
+	defer span.End()
+
+	bannerID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusNotFound, HTTPError{"wrong banner id"})
+	}
+
+	var patchData BannerPatch
+
+	if err := c.Bind(&patchData); err != nil {
+		s.logger.Errorw("wrong request",
+			"endpoint", "UpdateBanner",
+			"err", err)
+		return c.JSON(http.StatusUnprocessableEntity, HTTPError{"wrong data"})
+	}
+
+	patch := &entity.BannerPatch{
+		BannerText: patchData.BannerText,
+		BannerURL:  patchData.BannerURL,
+
+		LimitShows:   patchData.LimitShows,
+		LimitClicks:  patchData.LimitClicks,
+		LimitBudget:  patchData.LimitBudget,
+		DailyShows:   patchData.DailyShows,
+		DailyClicks:  patchData.DailyClicks,
+		DailyBudget:  patchData.DailyBudget,
+		HourlyShows:  patchData.HourlyShows,
+		HourlyClicks: patchData.HourlyClicks,
+		HourlyBudget: patchData.HourlyBudget,
+	}
+
+	if patchData.ImgData != nil {
+		imgData, err := base64.StdEncoding.DecodeString(*patchData.ImgData)
+		if err != nil || len(imgData) == 0 {
+			return c.JSON(http.StatusUnsupportedMediaType, HTTPError{"wrong img"})
+		}
+
+		patch.ImgData = imgData
+	}
+
+	pending, err := s.userSvc.UpdateBanner(spanCtx, userCtx.Validated, bannerID, userCtx.ID, patch)
+	if err != nil {
+		s.logger.Errorw("could not update banner",
+			"endpoint", "UpdateBanner",
+			"err", err)
+		return c.JSON(http.StatusInternalServerError, HTTPError{"could not update banner"})
+	}
+
+	status := "applied"
+	if pending {
+		status = "pending"
+	}
+
+	return c.JSON(http.StatusOK, map[string]string{
+		"status": status,
+	})
+}
+
+// ShowRevisionImg shows the creative waiting for moderation
+func (s *Server) ShowRevisionImg(c echo.Context) error {
+	spanCtx, span := otel.Tracer(tracerName).Start(c.Request().Context(), "ShowRevisionImg")
+	defer span.End()
+
+	bannerID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusNotFound, HTTPError{"wrong img id"})
+	}
+
+	revision, err := s.userSvc.GetRevision(spanCtx, bannerID)
+	if err != nil {
+		s.logger.Errorw("could not get revision", "endpoint", "ShowRevisionImg", "err", err)
+		return c.NoContent(http.StatusNotFound)
+	}
+
+	contentType := http.DetectContentType(revision.ImgData)
+
+	return c.Blob(http.StatusOK, contentType, revision.ImgData)
+}
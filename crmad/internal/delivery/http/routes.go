@@ -138,6 +138,28 @@ func (s *Server) AddBanner(c echo.Context, userCtx entity.UserContext) error {
 		LimitBudget: bannerData.LimitBudget,
 		CategoryID:  bannerData.CategoryID,
 		Device:      bannerData.Device,
+		CampaignID:  bannerData.CampaignID,
+
+		DailyShows:   bannerData.DailyShows,
+		DailyClicks:  bannerData.DailyClicks,
+		DailyBudget:  bannerData.DailyBudget,
+		HourlyShows:  bannerData.HourlyShows,
+		HourlyClicks: bannerData.HourlyClicks,
+		HourlyBudget: bannerData.HourlyBudget,
+
+		StartAt:  bannerData.StartAt,
+		EndAt:    bannerData.EndAt,
+		Schedule: entity.Schedule(bannerData.Schedule),
+		TimeZone: bannerData.TimeZone,
+		Geo:      entity.Geo(bannerData.Geo),
+
+		FrequencyCap:    bannerData.FrequencyCap,
+		FrequencyPeriod: bannerData.FrequencyPeriod,
+
+		BidType: bannerData.BidType,
+		Bid:     bannerData.Bid,
+
+		StartOnApproval: bannerData.StartOnApproval,
 	})
 
 	if err != nil {
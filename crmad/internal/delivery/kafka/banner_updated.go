@@ -2,10 +2,10 @@ package kafka
 
 import (
 	"context"
-	"encoding/json"
 	"fmt"
 
 	"github.com/Shopify/sarama"
+	kafkapkg "github.com/crxfoz/teaserad/adeliver/pkg/kafka"
 	"github.com/crxfoz/teaserad/crmad/internal/domain/events"
 	"go.opentelemetry.io/otel"
 )
@@ -13,6 +13,9 @@ import (
 type BannerService interface {
 	BannerUpdated(ctx context.Context, updated events.BannerUpdated) error
 	BannerReachedLimits(ctx context.Context, item events.BannerReachedLimits) error
+	BannerPaused(ctx context.Context, item events.BannerPaused) error
+	BannerResumed(ctx context.Context, item events.BannerResumed) error
+	ChargeClick(ctx context.Context, click events.Click) error
 }
 
 type BannerStatus struct {
@@ -32,7 +35,7 @@ func (bs *BannerStatus) OnBannerUpdated(ctx context.Context, msg *sarama.Consume
 	defer span.End()
 
 	var updated events.BannerUpdated
-	if err := json.Unmarshal(msg.Value, &updated); err != nil {
+	if err := kafkapkg.Decode(msg, &updated); err != nil {
 		return fmt.Errorf("could not parse message: %w", err)
 	}
 
@@ -44,9 +47,45 @@ func (bs *BannerStatus) OnBannerReachedLimits(ctx context.Context, msg *sarama.C
 	defer span.End()
 
 	var reached events.BannerReachedLimits
-	if err := json.Unmarshal(msg.Value, &reached); err != nil {
+	if err := kafkapkg.Decode(msg, &reached); err != nil {
 		return fmt.Errorf("could not parse message: %w", err)
 	}
 
 	return bs.bannerSvc.BannerReachedLimits(spanCtx, reached)
 }
+
+func (bs *BannerStatus) OnBannerPaused(ctx context.Context, msg *sarama.ConsumerMessage) error {
+	spanCtx, span := otel.Tracer(tracerName).Start(ctx, "OnBannerPaused")
+	defer span.End()
+
+	var paused events.BannerPaused
+	if err := kafkapkg.Decode(msg, &paused); err != nil {
+		return fmt.Errorf("could not parse message: %w", err)
+	}
+
+	return bs.bannerSvc.BannerPaused(spanCtx, paused)
+}
+
+func (bs *BannerStatus) OnBannerResumed(ctx context.Context, msg *sarama.ConsumerMessage) error {
+	spanCtx, span := otel.Tracer(tracerName).Start(ctx, "OnBannerResumed")
+	defer span.End()
+
+	var resumed events.BannerResumed
+	if err := kafkapkg.Decode(msg, &resumed); err != nil {
+		return fmt.Errorf("could not parse message: %w", err)
+	}
+
+	return bs.bannerSvc.BannerResumed(spanCtx, resumed)
+}
+
+func (bs *BannerStatus) OnClick(ctx context.Context, msg *sarama.ConsumerMessage) error {
+	spanCtx, span := otel.Tracer(tracerName).Start(ctx, "OnClick")
+	defer span.End()
+
+	var click events.Click
+	if err := kafkapkg.Decode(msg, &click); err != nil {
+		return fmt.Errorf("could not parse message: %w", err)
+	}
+
+	return bs.bannerSvc.ChargeClick(spanCtx, click)
+}
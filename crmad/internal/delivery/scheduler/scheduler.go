@@ -0,0 +1,57 @@
+package scheduler
+
+import (
+	"context"
+	"time"
+
+	"github.com/crxfoz/teaserad/crmad/internal/domain"
+	"go.opentelemetry.io/otel"
+)
+
+type BannerService interface {
+	ApplySchedule(ctx context.Context) error
+}
+
+// Scheduler periodically starts and stops banners on boundaries of their flight and schedule
+type Scheduler struct {
+	bannerSvc BannerService
+	interval  time.Duration
+	logger    domain.Logger
+	done      chan struct{}
+}
+
+func New(bannerSvc BannerService, interval time.Duration, logger domain.Logger) *Scheduler {
+	return &Scheduler{bannerSvc: bannerSvc, interval: interval, logger: logger, done: make(chan struct{})}
+}
+
+const (
+	tracerName = "scheduler-delivery"
+)
+
+// Run blocks until Stop is called
+func (s *Scheduler) Run() {
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			s.tick()
+		case <-s.done:
+			return
+		}
+	}
+}
+
+func (s *Scheduler) Stop() {
+	close(s.done)
+}
+
+func (s *Scheduler) tick() {
+	spanCtx, span := otel.Tracer(tracerName).Start(context.Background(), "ApplySchedule")
+	defer span.End()
+
+	if err := s.bannerSvc.ApplySchedule(spanCtx); err != nil {
+		s.logger.Errorw("could not apply schedule", "err", err)
+	}
+}
@@ -0,0 +1,74 @@
+package entity
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+)
+
+const (
+	// AccountWallet is a prepaid balance of an advertiser
+	AccountWallet = "wallet"
+	// AccountPayments is where money comes from when an invoice is paid
+	AccountPayments = "payments"
+	// AccountRevenue is where money goes to when an advertiser is charged
+	AccountRevenue = "revenue"
+)
+
+// ErrDuplicateReference is returned when the ledger already has entries with the reference
+var ErrDuplicateReference = errors.New("ledger already has the reference")
+
+// Invoice is paid by the payment provider, Key is known only to the provider which sends it back in the callback
+type Invoice struct {
+	ID        int     `json:"id" db:"id"`
+	UserID    int     `json:"user_id" db:"user_id"`
+	Amount    float64 `json:"amount" db:"amount"`
+	Key       string  `json:"-" db:"key"`
+	IsPaid    bool    `json:"is_paid" db:"is_paid"`
+	CreatedAt int64   `json:"created_at" db:"created_at"`
+}
+
+func (i *Invoice) GetAmount() float64 {
+	if !i.IsPaid {
+		return 0
+	}
+
+	return i.Amount
+}
+
+type LedgerEntry struct {
+	ID        int     `json:"id" db:"id"`
+	TxnID     string  `json:"txn_id" db:"txn_id"`
+	Account   string  `json:"account" db:"account"`
+	UserID    int     `json:"user_id" db:"user_id"`
+	Amount    float64 `json:"amount" db:"amount"`
+	Reference string  `json:"reference" db:"reference"`
+	CreatedAt int64   `json:"created_at" db:"created_at"`
+}
+
+// NewTransfer builds a balanced pair of ledger entries moving amount from one account to another
+func NewTransfer(from string, to string, userID int, amount float64, reference string, createdAt int64) ([]*LedgerEntry, error) {
+	if amount <= 0 {
+		return nil, fmt.Errorf("wrong amount: %f", amount)
+	}
+
+	txnID, err := RandomKey(16)
+	if err != nil {
+		return nil, fmt.Errorf("could not generate txn id: %w", err)
+	}
+
+	return []*LedgerEntry{
+		{TxnID: txnID, Account: from, UserID: userID, Amount: -amount, Reference: reference, CreatedAt: createdAt},
+		{TxnID: txnID, Account: to, UserID: userID, Amount: amount, Reference: reference, CreatedAt: createdAt},
+	}, nil
+}
+
+func RandomKey(size int) (string, error) {
+	buf := make([]byte, size)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+
+	return hex.EncodeToString(buf), nil
+}
@@ -0,0 +1,38 @@
+package entity
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewTransfer(t *testing.T) {
+	entries, err := NewTransfer(AccountWallet, AccountRevenue, 7, 0.25, "click:a1", 100)
+	assert.NoError(t, err)
+	assert.Len(t, entries, 2)
+
+	var sum float64
+	for _, entry := range entries {
+		sum += entry.Amount
+
+		assert.Equal(t, entries[0].TxnID, entry.TxnID)
+		assert.Equal(t, 7, entry.UserID)
+		assert.Equal(t, "click:a1", entry.Reference)
+	}
+
+	assert.Zero(t, sum)
+	assert.Equal(t, AccountWallet, entries[0].Account)
+	assert.Equal(t, -0.25, entries[0].Amount)
+	assert.Equal(t, AccountRevenue, entries[1].Account)
+
+	_, err = NewTransfer(AccountWallet, AccountRevenue, 7, 0, "click:a1", 100)
+	assert.Error(t, err)
+}
+
+func TestInvoice_GetAmount(t *testing.T) {
+	invoice := &Invoice{Amount: 10}
+	assert.Zero(t, invoice.GetAmount())
+
+	invoice.IsPaid = true
+	assert.Equal(t, 10.0, invoice.GetAmount())
+}
@@ -0,0 +1,145 @@
+package entity
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/crxfoz/teaserad/adclick/pkg/landing"
+)
+
+type Campaign struct {
+	ID          int     `json:"id" db:"id"`
+	UserID      int     `json:"user_id" db:"user_id"`
+	Name        string  `json:"name" db:"name"`
+	TotalBudget float64 `json:"total_budget" db:"total_budget"`
+	DailyBudget float64 `json:"daily_budget" db:"daily_budget"`
+	StartAt     int64   `json:"start_at" db:"start_at"`
+	EndAt       int64   `json:"end_at" db:"end_at"`
+	Device      string  `json:"device" db:"device"`
+	CategoryID  int     `json:"category_id" db:"category_id"`
+	IsActive    bool    `json:"is_active" db:"is_active"`
+	CreatedAt   int64   `json:"created_at" db:"created_at"`
+	// UTM parameters appended to landing URLs of banners, they may have macros
+	UTMSource   string `json:"utm_source" db:"utm_source"`
+	UTMMedium   string `json:"utm_medium" db:"utm_medium"`
+	UTMCampaign string `json:"utm_campaign" db:"utm_campaign"`
+}
+
+func (c *Campaign) Validate() error {
+	if c.Name == "" {
+		return fmt.Errorf("empty name")
+	}
+
+	if c.TotalBudget < 0 || c.DailyBudget < 0 {
+		return fmt.Errorf("negative budget")
+	}
+
+	if c.TotalBudget > 0 && c.DailyBudget > c.TotalBudget {
+		return fmt.Errorf("daily budget is greater than total: %f > %f", c.DailyBudget, c.TotalBudget)
+	}
+
+	if c.EndAt != 0 && c.EndAt <= c.StartAt {
+		return fmt.Errorf("campaign ends before it starts")
+	}
+
+	switch c.Device {
+	case "", DeviceDesktop, DeviceTablet, DeviceMobile:
+	default:
+		return fmt.Errorf("wrong device: %s", c.Device)
+	}
+
+	for _, value := range c.UTM() {
+		if err := landing.ValidateMacros(value); err != nil {
+			return fmt.Errorf("wrong utm: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// UTM returns UTM parameters of the campaign which are set
+func (c *Campaign) UTM() map[string]string {
+	out := make(map[string]string)
+
+	for name, value := range map[string]string{
+		"utm_source":   c.UTMSource,
+		"utm_medium":   c.UTMMedium,
+		"utm_campaign": c.UTMCampaign,
+	} {
+		if value != "" {
+			out[name] = value
+		}
+	}
+
+	return out
+}
+
+func (c *Campaign) IsRunning(now time.Time) bool {
+	ts := now.UTC().Unix()
+
+	if c.StartAt != 0 && ts < c.StartAt {
+		return false
+	}
+
+	if c.EndAt != 0 && ts >= c.EndAt {
+		return false
+	}
+
+	return true
+}
+
+// IsFinished tells whether flight of the campaign is over
+func (c *Campaign) IsFinished(now time.Time) bool {
+	return c.EndAt != 0 && now.UTC().Unix() >= c.EndAt
+}
+
+// CanBeStarted allows to start the campaign before its flight begins, its banners are delivered once it does
+func (c *Campaign) CanBeStarted(now time.Time) bool {
+	return !c.IsActive && !c.IsFinished(now)
+}
+
+func (c *Campaign) CanBeStopped() bool {
+	return c.IsActive
+}
+
+// Window returns flight of the banner narrowed to the flight of the campaign,
+// banners are not delivered out of the campaign's flight whatever their own one is
+func (c *Campaign) Window(banner *Banner) (int64, int64) {
+	startAt, endAt := banner.StartAt, banner.EndAt
+
+	if c.StartAt > startAt {
+		startAt = c.StartAt
+	}
+
+	if c.EndAt != 0 && (endAt == 0 || c.EndAt < endAt) {
+		endAt = c.EndAt
+	}
+
+	return startAt, endAt
+}
+
+// Restrict narrows flight of the banner to the flight of the campaign
+func (c *Campaign) Restrict(banner *Banner) {
+	banner.StartAt, banner.EndAt = c.Window(banner)
+}
+
+// ApplyDefaults fills targeting and budget of the banner that were not set explicitly.
+// Budgets of the campaign are shared by its banners and are checked by delivery against their total spend,
+// so a banner without its own budget may spend at most all of the campaign's one
+func (c *Campaign) ApplyDefaults(banner *Banner) {
+	if banner.Device == "" {
+		banner.Device = c.Device
+	}
+
+	if banner.CategoryID == 0 {
+		banner.CategoryID = c.CategoryID
+	}
+
+	if banner.LimitBudget == 0 {
+		banner.LimitBudget = c.TotalBudget
+	}
+
+	if banner.DailyBudget == 0 {
+		banner.DailyBudget = c.DailyBudget
+	}
+}
@@ -0,0 +1,36 @@
+package entity
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCampaign_Restrict(t *testing.T) {
+	march := time.Date(2022, 3, 1, 0, 0, 0, 0, time.UTC)
+	april := time.Date(2022, 4, 1, 0, 0, 0, 0, time.UTC)
+
+	campaign := &Campaign{StartAt: march.Unix(), EndAt: april.Unix()}
+
+	// banner without its own flight is delivered within the flight of the campaign
+	banner := &Banner{}
+	campaign.Restrict(banner)
+	assert.Equal(t, march.Unix(), banner.StartAt)
+	assert.Equal(t, april.Unix(), banner.EndAt)
+	assert.False(t, banner.InWindow(april.AddDate(0, 0, 1)))
+
+	// own flight of the banner can only be narrower
+	banner = &Banner{StartAt: march.AddDate(0, 0, 10).Unix(), EndAt: april.AddDate(0, 1, 0).Unix()}
+	campaign.Restrict(banner)
+	assert.Equal(t, march.AddDate(0, 0, 10).Unix(), banner.StartAt)
+	assert.Equal(t, april.Unix(), banner.EndAt)
+}
+
+func TestCampaign_CanBeStarted(t *testing.T) {
+	now := time.Date(2022, 3, 14, 0, 0, 0, 0, time.UTC)
+
+	assert.True(t, (&Campaign{StartAt: now.AddDate(0, 0, 1).Unix()}).CanBeStarted(now))
+	assert.False(t, (&Campaign{EndAt: now.Unix()}).CanBeStarted(now))
+	assert.False(t, (&Campaign{IsActive: true}).CanBeStarted(now))
+}
@@ -0,0 +1,52 @@
+package entity
+
+import (
+	"database/sql/driver"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+var geoCodeRe = regexp.MustCompile(`^[A-Z]{2}(-[A-Z0-9]{1,3})?$`)
+
+// Geo is a list of ISO 3166-1 country codes like RU and ISO 3166-2 region codes like RU-MOW
+// the banner is targeted at, empty list means everywhere
+type Geo []string
+
+func (g Geo) Validate() error {
+	for _, code := range g {
+		if !geoCodeRe.MatchString(code) {
+			return fmt.Errorf("wrong geo code: %s", code)
+		}
+	}
+
+	return nil
+}
+
+func (g Geo) Value() (driver.Value, error) {
+	return strings.Join(g, ","), nil
+}
+
+func (g *Geo) Scan(src interface{}) error {
+	var raw string
+
+	switch v := src.(type) {
+	case nil:
+		return nil
+	case []byte:
+		raw = string(v)
+	case string:
+		raw = v
+	default:
+		return fmt.Errorf("unexpected type of geo: %T", src)
+	}
+
+	if raw == "" {
+		*g = nil
+		return nil
+	}
+
+	*g = strings.Split(raw, ",")
+
+	return nil
+}
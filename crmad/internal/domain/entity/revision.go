@@ -0,0 +1,101 @@
+package entity
+
+import (
+	"errors"
+)
+
+var ErrNoRevision = errors.New("banner has no pending revision")
+
+// BannerPatch is a change of the banner requested by advertiser, nil fields are left as they are
+type BannerPatch struct {
+	ImgData    []byte
+	BannerText *string
+	BannerURL  *string
+
+	LimitShows   *int64
+	LimitClicks  *int64
+	LimitBudget  *float64
+	DailyShows   *int64
+	DailyClicks  *int64
+	DailyBudget  *float64
+	HourlyShows  *int64
+	HourlyClicks *int64
+	HourlyBudget *float64
+}
+
+// HasContent tells whether the patch changes the creative, such change has to pass moderation
+func (p *BannerPatch) HasContent() bool {
+	return p.ImgData != nil || p.BannerText != nil || p.BannerURL != nil
+}
+
+// HasLimits tells whether the patch changes limits or caps, such change is applied immediately
+func (p *BannerPatch) HasLimits() bool {
+	return p.LimitShows != nil || p.LimitClicks != nil || p.LimitBudget != nil ||
+		p.DailyShows != nil || p.DailyClicks != nil || p.DailyBudget != nil ||
+		p.HourlyShows != nil || p.HourlyClicks != nil || p.HourlyBudget != nil
+}
+
+func (p *BannerPatch) ApplyLimits(b *Banner) {
+	setInt64(&b.LimitShows, p.LimitShows)
+	setInt64(&b.LimitClicks, p.LimitClicks)
+	setFloat64(&b.LimitBudget, p.LimitBudget)
+	setInt64(&b.DailyShows, p.DailyShows)
+	setInt64(&b.DailyClicks, p.DailyClicks)
+	setFloat64(&b.DailyBudget, p.DailyBudget)
+	setInt64(&b.HourlyShows, p.HourlyShows)
+	setInt64(&b.HourlyClicks, p.HourlyClicks)
+	setFloat64(&b.HourlyBudget, p.HourlyBudget)
+}
+
+// Revision builds the new creative of the banner, fields which are not patched are copied from the current one
+func (p *BannerPatch) Revision(b *Banner, createdAt int64) *BannerRevision {
+	revision := &BannerRevision{
+		BannerID:   b.ID,
+		ImgData:    b.ImgData,
+		BannerText: b.BannerText,
+		BannerURL:  b.BannerURL,
+		CreatedAt:  createdAt,
+	}
+
+	if p.ImgData != nil {
+		revision.ImgData = p.ImgData
+	}
+
+	if p.BannerText != nil {
+		revision.BannerText = *p.BannerText
+	}
+
+	if p.BannerURL != nil {
+		revision.BannerURL = *p.BannerURL
+	}
+
+	return revision
+}
+
+// BannerRevision is a creative waiting for moderation, the banner keeps its current one until it's approved
+type BannerRevision struct {
+	ID         int    `json:"id" db:"id"`
+	BannerID   int    `json:"banner_id" db:"banner_id"`
+	ImgData    []byte `json:"-" db:"img_data"`
+	BannerText string `json:"banner_text" db:"banner_text"`
+	BannerURL  string `json:"banner_url" db:"banner_url"`
+	CreatedAt  int64  `json:"created_at" db:"created_at"`
+}
+
+func (r *BannerRevision) Apply(b *Banner) {
+	b.ImgData = r.ImgData
+	b.BannerText = r.BannerText
+	b.BannerURL = r.BannerURL
+}
+
+func setInt64(dst *int64, src *int64) {
+	if src != nil {
+		*dst = *src
+	}
+}
+
+func setFloat64(dst *float64, src *float64) {
+	if src != nil {
+		*dst = *src
+	}
+}
@@ -0,0 +1,48 @@
+package entity
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBannerPatch(t *testing.T) {
+	banner := &Banner{
+		ID:          7,
+		ImgData:     []byte("old"),
+		BannerText:  "old text",
+		BannerURL:   "https://example.com/old",
+		LimitShows:  100,
+		LimitBudget: 10,
+		DailyShows:  20,
+	}
+
+	text := "new text"
+	shows := int64(500)
+	budget := float64(0)
+
+	limitsOnly := &BannerPatch{LimitShows: &shows, DailyBudget: &budget}
+	assert.True(t, limitsOnly.HasLimits())
+	assert.False(t, limitsOnly.HasContent())
+
+	limitsOnly.ApplyLimits(banner)
+	assert.Equal(t, int64(500), banner.LimitShows)
+	assert.Equal(t, float64(10), banner.LimitBudget)
+	assert.Equal(t, int64(20), banner.DailyShows)
+
+	content := &BannerPatch{BannerText: &text}
+	assert.True(t, content.HasContent())
+	assert.False(t, content.HasLimits())
+
+	revision := content.Revision(banner, 1000)
+	assert.Equal(t, 7, revision.BannerID)
+	assert.Equal(t, "new text", revision.BannerText)
+	assert.Equal(t, "https://example.com/old", revision.BannerURL)
+	assert.Equal(t, []byte("old"), revision.ImgData)
+
+	// live creative is kept until the revision is applied
+	assert.Equal(t, "old text", banner.BannerText)
+
+	revision.Apply(banner)
+	assert.Equal(t, "new text", banner.BannerText)
+}
@@ -0,0 +1,84 @@
+package entity
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// Schedule holds active hours of the day for each day of the week indexed by time.Weekday,
+// empty schedule means the banner is active all the time
+type Schedule [7][]int
+
+func (s Schedule) IsEmpty() bool {
+	for _, hours := range s {
+		if len(hours) != 0 {
+			return false
+		}
+	}
+
+	return true
+}
+
+func (s Schedule) Validate() error {
+	for day, hours := range s {
+		for _, hour := range hours {
+			if hour < 0 || hour > 23 {
+				return fmt.Errorf("wrong hour of %s: %d", time.Weekday(day), hour)
+			}
+		}
+	}
+
+	return nil
+}
+
+// IsActive tells whether the moment falls into active hours, the moment is expected to be in advertiser's time zone
+func (s Schedule) IsActive(t time.Time) bool {
+	if s.IsEmpty() {
+		return true
+	}
+
+	for _, hour := range s[t.Weekday()] {
+		if hour == t.Hour() {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (s Schedule) Value() (driver.Value, error) {
+	if s.IsEmpty() {
+		return "", nil
+	}
+
+	raw, err := json.Marshal(s)
+	if err != nil {
+		return nil, fmt.Errorf("could not marshal schedule: %w", err)
+	}
+
+	return string(raw), nil
+}
+
+func (s *Schedule) Scan(src interface{}) error {
+	var raw []byte
+
+	switch v := src.(type) {
+	case nil:
+		return nil
+	case []byte:
+		raw = v
+	case string:
+		raw = []byte(v)
+	default:
+		return fmt.Errorf("unexpected type of schedule: %T", src)
+	}
+
+	if len(raw) == 0 {
+		*s = Schedule{}
+		return nil
+	}
+
+	return json.Unmarshal(raw, s)
+}
@@ -0,0 +1,65 @@
+package entity
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSchedule_IsActive(t *testing.T) {
+	var schedule Schedule
+	schedule[time.Monday] = []int{9, 10, 11}
+
+	monday := time.Date(2022, 3, 14, 10, 30, 0, 0, time.UTC)
+
+	assert.True(t, schedule.IsActive(monday))
+	assert.False(t, schedule.IsActive(monday.Add(2*time.Hour)))
+	assert.False(t, schedule.IsActive(monday.AddDate(0, 0, 1)))
+	assert.True(t, Schedule{}.IsActive(monday))
+}
+
+func TestBanner_InWindow(t *testing.T) {
+	banner := &Banner{
+		StartAt:  time.Date(2022, 3, 1, 0, 0, 0, 0, time.UTC).Unix(),
+		EndAt:    time.Date(2022, 4, 1, 0, 0, 0, 0, time.UTC).Unix(),
+		TimeZone: "Europe/Moscow",
+	}
+	banner.Schedule[time.Monday] = []int{9}
+
+	// 06:30 UTC is 09:30 in Moscow
+	assert.True(t, banner.InWindow(time.Date(2022, 3, 14, 6, 30, 0, 0, time.UTC)))
+	assert.False(t, banner.InWindow(time.Date(2022, 3, 14, 9, 30, 0, 0, time.UTC)))
+	assert.False(t, banner.InWindow(time.Date(2022, 2, 28, 6, 30, 0, 0, time.UTC)))
+	assert.False(t, banner.InWindow(time.Date(2022, 4, 4, 6, 30, 0, 0, time.UTC)))
+}
+
+func TestBanner_Validate(t *testing.T) {
+	categories := []*WebsiteCategory{{ID: 1}}
+
+	banner := &Banner{Device: DeviceDesktop, CategoryID: 1, TimeZone: "Mars/Olympus"}
+	assert.NotNil(t, banner.Validate(categories))
+
+	banner = &Banner{Device: DeviceDesktop, CategoryID: 1, StartAt: 200, EndAt: 100}
+	assert.NotNil(t, banner.Validate(categories))
+
+	banner = &Banner{Device: DeviceDesktop, CategoryID: 1}
+	banner.Schedule[time.Friday] = []int{24}
+	assert.NotNil(t, banner.Validate(categories))
+
+	banner = &Banner{Device: DeviceDesktop, CategoryID: 1, Geo: Geo{"RU", "moscow"}}
+	assert.NotNil(t, banner.Validate(categories))
+
+	banner = &Banner{Device: DeviceDesktop, CategoryID: 1, BidType: "cpa", Bid: 1}
+	assert.NotNil(t, banner.Validate(categories))
+
+	banner = &Banner{Device: DeviceDesktop, CategoryID: 1, BidType: BidCPM, Bid: -1}
+	assert.NotNil(t, banner.Validate(categories))
+
+	banner = &Banner{Device: DeviceDesktop, CategoryID: 1, BidType: BidCPC, Bid: 0.5, BannerURL: "https://shop.example/?b={banner}"}
+	assert.NotNil(t, banner.Validate(categories))
+
+	banner = &Banner{Device: DeviceDesktop, CategoryID: 1, TimeZone: "Europe/Moscow", Geo: Geo{"RU-MOW", "BY"},
+		BidType: BidCPC, Bid: 0.5, BannerURL: "https://shop.example/?b={banner_id}&c={click_id}"}
+	assert.Nil(t, banner.Validate(categories))
+}
@@ -2,10 +2,24 @@ package entity
 
 import (
 	"fmt"
+	"time"
 
+	"github.com/crxfoz/teaserad/adclick/pkg/landing"
 	"golang.org/x/crypto/bcrypt"
 )
 
+// DefaultFrequencyPeriod is used when frequency cap is set without period
+const DefaultFrequencyPeriod = 24 * 60 * 60
+
+const (
+	// BidCPC is a price of a click, BidCPM is a price of thousand shows
+	BidCPC = "cpc"
+	BidCPM = "cpm"
+
+	// DefaultBid is a price of a click banners had before bids were introduced
+	DefaultBid = 1
+)
+
 const (
 	DeviceDesktop = "desktop"
 	DeviceMobile  = "mobile"
@@ -48,17 +62,119 @@ type Banner struct {
 	Comment     string  `json:"comment" db:"comment"`
 	Device      string  `json:"device" db:"device"`
 	CategoryID  int     `json:"category_id" db:"category_id"`
+	CampaignID  int     `json:"campaign_id" db:"campaign_id"`
+	// daily and hourly caps, 0 means no cap
+	DailyShows   int64   `json:"daily_shows" db:"daily_shows"`
+	DailyClicks  int64   `json:"daily_clicks" db:"daily_clicks"`
+	DailyBudget  float64 `json:"daily_budget" db:"daily_budget"`
+	HourlyShows  int64   `json:"hourly_shows" db:"hourly_shows"`
+	HourlyClicks int64   `json:"hourly_clicks" db:"hourly_clicks"`
+	HourlyBudget float64 `json:"hourly_budget" db:"hourly_budget"`
+	// PausedUntil is set when active banner reached one of its caps and waits for the next period
+	PausedUntil int64  `json:"paused_until" db:"paused_until"`
+	PauseReason string `json:"pause_reason" db:"pause_reason"`
+	// StartAt and EndAt bound the flight of the banner, Schedule is set in advertiser's TimeZone
+	StartAt  int64    `json:"start_at" db:"start_at"`
+	EndAt    int64    `json:"end_at" db:"end_at"`
+	Schedule Schedule `json:"schedule" db:"schedule"`
+	TimeZone string   `json:"timezone" db:"timezone"`
+	// IsLive is set while active banner is delivered, it's not while the banner is out of its schedule
+	IsLive bool `json:"is_live" db:"is_live"`
+	Geo    Geo  `json:"geo" db:"geo"`
+	// FrequencyCap is how many times the same visitor can be shown the banner within FrequencyPeriod seconds
+	FrequencyCap    int   `json:"frequency_cap" db:"frequency_cap"`
+	FrequencyPeriod int64 `json:"frequency_period" db:"frequency_period"`
+	// BidType tells whether Bid is paid for a click or for thousand shows
+	BidType string  `json:"bid_type" db:"bid_type"`
+	Bid     float64 `json:"bid" db:"bid"`
+	// StartOnApproval is set when advertiser asked to start the banner as soon as moderator approves it
+	StartOnApproval bool `json:"start_on_approval" db:"start_on_approval"`
+	// ModeratedAt is the time of the last resolution of moderator applied to the banner
+	ModeratedAt int64 `json:"moderated_at" db:"moderated_at"`
+}
+
+func (b *Banner) HasSchedule() bool {
+	return b.StartAt != 0 || b.EndAt != 0 || !b.Schedule.IsEmpty()
+}
+
+// IsFinished tells whether flight of the banner is over
+func (b *Banner) IsFinished(now time.Time) bool {
+	return b.EndAt != 0 && now.Unix() >= b.EndAt
+}
+
+// InWindow tells whether the banner should be delivered at the moment according to its flight and schedule
+func (b *Banner) InWindow(now time.Time) bool {
+	if b.StartAt != 0 && now.Unix() < b.StartAt {
+		return false
+	}
+
+	if b.IsFinished(now) {
+		return false
+	}
+
+	loc, err := time.LoadLocation(b.TimeZone)
+	if err != nil {
+		loc = time.UTC
+	}
+
+	return b.Schedule.IsActive(now.In(loc))
+}
+
+func (b *Banner) IsPaused(now time.Time) bool {
+	return b.IsActive && b.PausedUntil > now.Unix()
 }
 
 func (b *Banner) CanBeStarted() bool {
 	return !b.IsActive && b.IsValidated
 }
 
+// CanBeQueued tells whether the banner can be started only after moderator approves it
+func (b *Banner) CanBeQueued() bool {
+	return !b.IsActive && !b.IsValidated
+}
+
 func (b *Banner) CanBeStopped() bool {
 	return b.IsActive
 }
 
 func (b *Banner) Validate(categories []*WebsiteCategory) error {
+	if b.DailyShows < 0 || b.DailyClicks < 0 || b.DailyBudget < 0 ||
+		b.HourlyShows < 0 || b.HourlyClicks < 0 || b.HourlyBudget < 0 {
+		return fmt.Errorf("negative cap")
+	}
+
+	if b.FrequencyCap < 0 || b.FrequencyPeriod < 0 {
+		return fmt.Errorf("negative frequency cap")
+	}
+
+	if err := landing.Validate(b.BannerURL); err != nil {
+		return fmt.Errorf("wrong banner url: %w", err)
+	}
+
+	if b.BidType != BidCPC && b.BidType != BidCPM {
+		return fmt.Errorf("wrong bid type: %s", b.BidType)
+	}
+
+	if b.Bid <= 0 {
+		return fmt.Errorf("bid must be positive")
+	}
+
+	if b.EndAt != 0 && b.EndAt <= b.StartAt {
+		return fmt.Errorf("banner ends before it starts")
+	}
+
+	if err := b.Geo.Validate(); err != nil {
+		return fmt.Errorf("wrong geo: %w", err)
+	}
+
+	if err := b.Schedule.Validate(); err != nil {
+		return fmt.Errorf("wrong schedule: %w", err)
+	}
+
+	if _, err := time.LoadLocation(b.TimeZone); err != nil {
+		return fmt.Errorf("wrong timezone: %s", b.TimeZone)
+	}
+
 	switch b.Device {
 	case DeviceDesktop, DeviceTablet, DeviceMobile:
 	default:
@@ -73,19 +189,3 @@ func (b *Banner) Validate(categories []*WebsiteCategory) error {
 
 	return fmt.Errorf("invalide category: %d", b.CategoryID)
 }
-
-type Invoice struct {
-	ID        int     `json:"id" db:"id"`
-	Amount    float64 `json:"amount" db:"amount"`
-	Key       string  `json:"key" db:"key"`
-	IsPaid    bool    `json:"is_paid" db:"is_paid"`
-	CreatedAt int64   `json:"created_at" db:"created_at"`
-}
-
-func (i *Invoice) GetAmount() float64 {
-	if !i.IsPaid {
-		return 0
-	}
-
-	return i.Amount
-}
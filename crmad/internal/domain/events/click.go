@@ -0,0 +1,5 @@
+package events
+
+import contracts "github.com/crxfoz/teaserad/contracts/events"
+
+type Click = contracts.Click
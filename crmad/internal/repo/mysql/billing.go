@@ -0,0 +1,135 @@
+package mysql
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/crxfoz/teaserad/crmad/internal/domain/entity"
+	"github.com/go-sql-driver/mysql"
+	"go.opentelemetry.io/otel"
+)
+
+func (ur *UserRepo) CreateInvoice(ctx context.Context, invoice *entity.Invoice) (int, error) {
+	spanCtx, span := otel.Tracer(tracerName).Start(ctx, "CreateInvoice")
+	defer span.End()
+
+	res, err := ur.executor(spanCtx).ExecContext(spanCtx,
+		"INSERT INTO invoices (user_id, amount, `key`, is_paid, created_at) VALUES (?,?,?,?,?)",
+		invoice.UserID,
+		invoice.Amount,
+		invoice.Key,
+		invoice.IsPaid,
+		invoice.CreatedAt,
+	)
+	if err != nil {
+		return 0, fmt.Errorf("could not insert invoice: %w", err)
+	}
+
+	id, err := res.LastInsertId()
+	if err != nil {
+		return 0, fmt.Errorf("could not get invoice id: %w", err)
+	}
+
+	return int(id), nil
+}
+
+func (ur *UserRepo) GetInvoices(ctx context.Context, userID int) ([]*entity.Invoice, error) {
+	spanCtx, span := otel.Tracer(tracerName).Start(ctx, "GetInvoices")
+	defer span.End()
+
+	var invoices []*entity.Invoice
+
+	err := ur.executor(spanCtx).SelectContext(spanCtx, &invoices,
+		"SELECT id, user_id, amount, `key`, is_paid, created_at FROM invoices WHERE user_id=? ORDER BY id DESC", userID)
+	if err != nil {
+		return nil, fmt.Errorf("could not get invoices: %w", err)
+	}
+
+	return invoices, nil
+}
+
+// GetInvoiceForUpdate locks the invoice until the end of transaction
+func (ur *UserRepo) GetInvoiceForUpdate(ctx context.Context, key string) (*entity.Invoice, error) {
+	spanCtx, span := otel.Tracer(tracerName).Start(ctx, "GetInvoiceForUpdate")
+	defer span.End()
+
+	var invoice entity.Invoice
+
+	err := ur.executor(spanCtx).GetContext(spanCtx, &invoice,
+		"SELECT id, user_id, amount, `key`, is_paid, created_at FROM invoices WHERE `key`=? FOR UPDATE", key)
+	if err != nil {
+		return nil, fmt.Errorf("could not get invoice: %w", err)
+	}
+
+	return &invoice, nil
+}
+
+func (ur *UserRepo) InvoicePaid(ctx context.Context, invoiceID int) error {
+	spanCtx, span := otel.Tracer(tracerName).Start(ctx, "InvoicePaid")
+	defer span.End()
+
+	res, err := ur.executor(spanCtx).ExecContext(spanCtx, "UPDATE invoices SET is_paid=? WHERE id=? AND is_paid=?", true, invoiceID, false)
+	if err != nil {
+		return fmt.Errorf("could not update invoice: %w", err)
+	}
+
+	n, _ := res.RowsAffected()
+	if n == 0 {
+		return fmt.Errorf("update took no effect")
+	}
+
+	return nil
+}
+
+func (ur *UserRepo) AddLedgerEntries(ctx context.Context, entries []*entity.LedgerEntry) error {
+	spanCtx, span := otel.Tracer(tracerName).Start(ctx, "AddLedgerEntries")
+	defer span.End()
+
+	conn := ur.executor(spanCtx)
+
+	for _, item := range entries {
+		_, err := conn.ExecContext(spanCtx,
+			"INSERT INTO ledger (txn_id, account, user_id, amount, reference, created_at) VALUES (?,?,?,?,?,?)",
+			item.TxnID,
+			item.Account,
+			item.UserID,
+			item.Amount,
+			item.Reference,
+			item.CreatedAt,
+		)
+		if isDuplicate(err) {
+			return fmt.Errorf("could not insert ledger entry %s: %w", item.Reference, entity.ErrDuplicateReference)
+		}
+
+		if err != nil {
+			return fmt.Errorf("could not insert ledger entry: %w", err)
+		}
+	}
+
+	return nil
+}
+
+func (ur *UserRepo) GetBalance(ctx context.Context, userID int) (float64, error) {
+	spanCtx, span := otel.Tracer(tracerName).Start(ctx, "GetBalance")
+	defer span.End()
+
+	var balance float64
+
+	err := ur.executor(spanCtx).GetContext(spanCtx, &balance,
+		"SELECT COALESCE(SUM(amount), 0) FROM ledger WHERE account=? AND user_id=?", entity.AccountWallet, userID)
+	if err != nil {
+		return 0, fmt.Errorf("could not get balance: %w", err)
+	}
+
+	return balance, nil
+}
+
+// mysqlErrDupEntry is ER_DUP_ENTRY
+const mysqlErrDupEntry = 1062
+
+func isDuplicate(err error) bool {
+	var mysqlErr *mysql.MySQLError
+
+	return errors.As(err, &mysqlErr) && mysqlErr.Number == mysqlErrDupEntry
+}
@@ -0,0 +1,197 @@
+package mysql
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/crxfoz/teaserad/crmad/internal/domain/entity"
+	"go.opentelemetry.io/otel"
+)
+
+func (ur *UserRepo) CreateCampaign(ctx context.Context, campaign *entity.Campaign) (int, error) {
+	spanCtx, span := otel.Tracer(tracerName).Start(ctx, "CreateCampaign")
+	defer span.End()
+
+	conn := ur.executor(spanCtx)
+
+	res, err := conn.ExecContext(spanCtx, `INSERT INTO campaigns (
+                       user_id, name, total_budget, daily_budget, start_at, end_at, device, category_id, is_active, created_at,
+                       utm_source, utm_medium, utm_campaign)
+					VALUES (?,?,?,?,?,?,?,?,?,?,?,?,?)`,
+		campaign.UserID,
+		campaign.Name,
+		campaign.TotalBudget,
+		campaign.DailyBudget,
+		campaign.StartAt,
+		campaign.EndAt,
+		campaign.Device,
+		campaign.CategoryID,
+		campaign.IsActive,
+		campaign.CreatedAt,
+		campaign.UTMSource,
+		campaign.UTMMedium,
+		campaign.UTMCampaign,
+	)
+	if err != nil {
+		return 0, fmt.Errorf("could not insert campaign to mysql: %w", err)
+	}
+
+	id, err := res.LastInsertId()
+	if err != nil {
+		return 0, fmt.Errorf("could not get campaign id: %w", err)
+	}
+
+	return int(id), nil
+}
+
+func (ur *UserRepo) GetCampaigns(ctx context.Context, userID int) ([]*entity.Campaign, error) {
+	spanCtx, span := otel.Tracer(tracerName).Start(ctx, "GetCampaigns")
+	defer span.End()
+
+	var campaigns []*entity.Campaign
+
+	err := ur.executor(spanCtx).SelectContext(spanCtx, &campaigns,
+		`SELECT id, user_id, name, total_budget, daily_budget, start_at, end_at, device, category_id, is_active, created_at,
+		       utm_source, utm_medium, utm_campaign
+		FROM campaigns WHERE user_id=?`, userID)
+	if err != nil {
+		return nil, fmt.Errorf("could not get campaigns: %w", err)
+	}
+
+	return campaigns, nil
+}
+
+func (ur *UserRepo) GetCampaign(ctx context.Context, campaignID int) (*entity.Campaign, error) {
+	spanCtx, span := otel.Tracer(tracerName).Start(ctx, "GetCampaign")
+	defer span.End()
+
+	var campaign entity.Campaign
+
+	err := ur.executor(spanCtx).GetContext(spanCtx, &campaign,
+		`SELECT id, user_id, name, total_budget, daily_budget, start_at, end_at, device, category_id, is_active, created_at,
+		       utm_source, utm_medium, utm_campaign
+		FROM campaigns WHERE id=?`, campaignID)
+	if err != nil {
+		return nil, fmt.Errorf("could not get campaign: %w", err)
+	}
+
+	return &campaign, nil
+}
+
+func (ur *UserRepo) UpdateCampaign(ctx context.Context, campaign *entity.Campaign) error {
+	spanCtx, span := otel.Tracer(tracerName).Start(ctx, "UpdateCampaign")
+	defer span.End()
+
+	res, err := ur.executor(spanCtx).ExecContext(spanCtx,
+		`UPDATE campaigns SET name=?, total_budget=?, daily_budget=?, start_at=?, end_at=?, device=?, category_id=?,
+		utm_source=?, utm_medium=?, utm_campaign=?
+		WHERE id=? AND user_id=?`,
+		campaign.Name,
+		campaign.TotalBudget,
+		campaign.DailyBudget,
+		campaign.StartAt,
+		campaign.EndAt,
+		campaign.Device,
+		campaign.CategoryID,
+		campaign.UTMSource,
+		campaign.UTMMedium,
+		campaign.UTMCampaign,
+		campaign.ID,
+		campaign.UserID,
+	)
+	if err != nil {
+		return fmt.Errorf("could not update campaign: %w", err)
+	}
+
+	n, _ := res.RowsAffected()
+	if n == 0 {
+		return ur.checkCampaignExists(spanCtx, campaign.ID, campaign.UserID)
+	}
+
+	return nil
+}
+
+func (ur *UserRepo) DeleteCampaign(ctx context.Context, campaignID int, userID int) error {
+	spanCtx, span := otel.Tracer(tracerName).Start(ctx, "DeleteCampaign")
+	defer span.End()
+
+	conn := ur.executor(spanCtx)
+
+	res, err := conn.ExecContext(spanCtx, "DELETE FROM campaigns WHERE id=? AND user_id=? AND is_active=?", campaignID, userID, false)
+	if err != nil {
+		return fmt.Errorf("could not delete campaign: %w", err)
+	}
+
+	n, _ := res.RowsAffected()
+	if n == 0 {
+		return fmt.Errorf("there is nothing to delete")
+	}
+
+	if _, err := conn.ExecContext(spanCtx, "UPDATE banners SET campaign_id=0 WHERE campaign_id=?", campaignID); err != nil {
+		return fmt.Errorf("could not detach banners: %w", err)
+	}
+
+	return nil
+}
+
+func (ur *UserRepo) GetCampaignBanners(ctx context.Context, campaignID int) ([]*entity.Banner, error) {
+	spanCtx, span := otel.Tracer(tracerName).Start(ctx, "GetCampaignBanners")
+	defer span.End()
+
+	var banners []*entity.Banner
+
+	err := ur.executor(spanCtx).SelectContext(spanCtx, &banners,
+		`SELECT id, img_data, banner_text, banner_url, is_active, limit_shows,
+       		limit_clicks, limit_budget, user_id, created_at, is_validated, comment, device, category_id, campaign_id,
+       		daily_shows, daily_clicks, daily_budget, hourly_shows, hourly_clicks, hourly_budget, paused_until, pause_reason,
+       		start_at, end_at, schedule, timezone, is_live, geo, frequency_cap, frequency_period, bid_type, bid,
+       		start_on_approval, moderated_at
+		FROM banners WHERE campaign_id=?`, campaignID)
+	if err != nil {
+		return nil, fmt.Errorf("could not get campaign banners: %w", err)
+	}
+
+	return banners, nil
+}
+
+func (ur *UserRepo) CampaignActivate(ctx context.Context, campaignID int, userID int) error {
+	return ur.campaignChangeActive(ctx, campaignID, userID, true)
+}
+
+func (ur *UserRepo) CampaignDeactivate(ctx context.Context, campaignID int, userID int) error {
+	return ur.campaignChangeActive(ctx, campaignID, userID, false)
+}
+
+func (ur *UserRepo) campaignChangeActive(ctx context.Context, campaignID int, userID int, active bool) error {
+	spanCtx, span := otel.Tracer(tracerName).Start(ctx, "campaignChangeActive")
+	defer span.End()
+
+	res, err := ur.executor(spanCtx).ExecContext(spanCtx, "UPDATE campaigns SET is_active=? WHERE id=? AND user_id=?", active, campaignID, userID)
+	if err != nil {
+		return fmt.Errorf("could not update: %w", err)
+	}
+
+	n, _ := res.RowsAffected()
+	if n == 0 {
+		return ur.checkCampaignExists(spanCtx, campaignID, userID)
+	}
+
+	return nil
+}
+
+// checkCampaignExists tells an update of the campaign which changed nothing from an update of a missing one,
+// MySQL doesn't count rows left unchanged as affected
+func (ur *UserRepo) checkCampaignExists(ctx context.Context, campaignID int, userID int) error {
+	var found int
+
+	err := ur.executor(ctx).GetContext(ctx, &found, "SELECT COUNT(*) FROM campaigns WHERE id=? AND user_id=?", campaignID, userID)
+	if err != nil {
+		return fmt.Errorf("could not check campaign: %w", err)
+	}
+
+	if found == 0 {
+		return fmt.Errorf("campaign not found")
+	}
+
+	return nil
+}
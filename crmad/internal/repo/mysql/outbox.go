@@ -0,0 +1,108 @@
+package mysql
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/crxfoz/teaserad/crmad/pkg/outbox"
+	"github.com/jmoiron/sqlx"
+	"go.opentelemetry.io/otel"
+)
+
+// maxOutboxError is the size of last_error column
+const maxOutboxError = 1024
+
+func (ur *UserRepo) AddOutbox(ctx context.Context, msg *outbox.Message) error {
+	spanCtx, span := otel.Tracer(tracerName).Start(ctx, "AddOutbox")
+	defer span.End()
+
+	_, err := ur.executor(spanCtx).ExecContext(spanCtx,
+		"INSERT INTO outbox (topic, msg_key, payload, headers, created_at) VALUES (?,?,?,?,?)",
+		msg.Topic,
+		msg.Key,
+		msg.Payload,
+		msg.Headers,
+		msg.CreatedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("could not insert outbox message: %w", err)
+	}
+
+	return nil
+}
+
+// GetOutbox locks pending messages so another replica waits until they are claimed
+func (ur *UserRepo) GetOutbox(ctx context.Context, limit int) ([]*outbox.Message, error) {
+	spanCtx, span := otel.Tracer(tracerName).Start(ctx, "GetOutbox")
+	defer span.End()
+
+	var messages []*outbox.Message
+
+	err := ur.executor(spanCtx).SelectContext(spanCtx, &messages,
+		`SELECT id, topic, msg_key, payload, headers, created_at, attempts, claimed_until
+		FROM outbox WHERE sent_at=0 ORDER BY id LIMIT ? FOR UPDATE`, limit)
+	if err != nil {
+		return nil, fmt.Errorf("could not get outbox: %w", err)
+	}
+
+	return messages, nil
+}
+
+func (ur *UserRepo) ClaimOutbox(ctx context.Context, msgIDs []int, until int64) error {
+	spanCtx, span := otel.Tracer(tracerName).Start(ctx, "ClaimOutbox")
+	defer span.End()
+
+	return ur.setOutboxClaim(spanCtx, msgIDs, until)
+}
+
+func (ur *UserRepo) ReleaseOutbox(ctx context.Context, msgIDs []int) error {
+	spanCtx, span := otel.Tracer(tracerName).Start(ctx, "ReleaseOutbox")
+	defer span.End()
+
+	return ur.setOutboxClaim(spanCtx, msgIDs, 0)
+}
+
+func (ur *UserRepo) setOutboxClaim(ctx context.Context, msgIDs []int, until int64) error {
+	if len(msgIDs) == 0 {
+		return nil
+	}
+
+	query, args, err := sqlx.In("UPDATE outbox SET claimed_until=? WHERE id IN (?)", until, msgIDs)
+	if err != nil {
+		return fmt.Errorf("could not build query: %w", err)
+	}
+
+	if _, err := ur.executor(ctx).ExecContext(ctx, query, args...); err != nil {
+		return fmt.Errorf("could not update outbox: %w", err)
+	}
+
+	return nil
+}
+
+func (ur *UserRepo) OutboxSent(ctx context.Context, msgID int, sentAt int64) error {
+	spanCtx, span := otel.Tracer(tracerName).Start(ctx, "OutboxSent")
+	defer span.End()
+
+	if _, err := ur.executor(spanCtx).ExecContext(spanCtx, "UPDATE outbox SET sent_at=? WHERE id=?", sentAt, msgID); err != nil {
+		return fmt.Errorf("could not update outbox: %w", err)
+	}
+
+	return nil
+}
+
+func (ur *UserRepo) OutboxFailed(ctx context.Context, msgID int, reason string) error {
+	spanCtx, span := otel.Tracer(tracerName).Start(ctx, "OutboxFailed")
+	defer span.End()
+
+	if len(reason) > maxOutboxError {
+		reason = reason[:maxOutboxError]
+	}
+
+	_, err := ur.executor(spanCtx).ExecContext(spanCtx,
+		"UPDATE outbox SET attempts=attempts+1, last_error=? WHERE id=?", reason, msgID)
+	if err != nil {
+		return fmt.Errorf("could not update outbox: %w", err)
+	}
+
+	return nil
+}
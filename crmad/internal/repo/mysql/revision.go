@@ -0,0 +1,125 @@
+package mysql
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/crxfoz/teaserad/crmad/internal/domain/entity"
+	"go.opentelemetry.io/otel"
+)
+
+// SaveRevision stores the revision replacing the one which is still pending for the banner
+func (ur *UserRepo) SaveRevision(ctx context.Context, revision *entity.BannerRevision) error {
+	spanCtx, span := otel.Tracer(tracerName).Start(ctx, "SaveRevision")
+	defer span.End()
+
+	_, err := ur.executor(spanCtx).ExecContext(spanCtx,
+		"REPLACE INTO banner_revisions (banner_id, img_data, banner_text, banner_url, created_at) VALUES (?,?,?,?,?)",
+		revision.BannerID,
+		revision.ImgData,
+		revision.BannerText,
+		revision.BannerURL,
+		revision.CreatedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("could not insert revision: %w", err)
+	}
+
+	return nil
+}
+
+func (ur *UserRepo) GetRevision(ctx context.Context, bannerID int) (*entity.BannerRevision, error) {
+	spanCtx, span := otel.Tracer(tracerName).Start(ctx, "GetRevision")
+	defer span.End()
+
+	var revision entity.BannerRevision
+
+	err := ur.executor(spanCtx).GetContext(spanCtx, &revision,
+		"SELECT id, banner_id, img_data, banner_text, banner_url, created_at FROM banner_revisions WHERE banner_id=?", bannerID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, entity.ErrNoRevision
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("could not get revision: %w", err)
+	}
+
+	return &revision, nil
+}
+
+func (ur *UserRepo) DeleteRevision(ctx context.Context, revisionID int) error {
+	spanCtx, span := otel.Tracer(tracerName).Start(ctx, "DeleteRevision")
+	defer span.End()
+
+	if _, err := ur.executor(spanCtx).ExecContext(spanCtx, "DELETE FROM banner_revisions WHERE id=?", revisionID); err != nil {
+		return fmt.Errorf("could not delete revision: %w", err)
+	}
+
+	return nil
+}
+
+// BannerApplyRevision replaces the creative of the banner with the approved revision
+func (ur *UserRepo) BannerApplyRevision(ctx context.Context, revision *entity.BannerRevision, comment string, moderatedAt int64) error {
+	spanCtx, span := otel.Tracer(tracerName).Start(ctx, "BannerApplyRevision")
+	defer span.End()
+
+	_, err := ur.executor(spanCtx).ExecContext(spanCtx,
+		`UPDATE banners SET img_data=?, banner_text=?, banner_url=?, is_validated=?, comment=?,
+			moderated_at=GREATEST(moderated_at, ?) WHERE id=?`,
+		revision.ImgData,
+		revision.BannerText,
+		revision.BannerURL,
+		true,
+		comment,
+		moderatedAt,
+		revision.BannerID,
+	)
+	if err != nil {
+		return fmt.Errorf("could not update banner: %w", err)
+	}
+
+	return nil
+}
+
+// BannerComment leaves moderator's comment without changing the status of the banner
+func (ur *UserRepo) BannerComment(ctx context.Context, bannerID int, comment string, moderatedAt int64) error {
+	spanCtx, span := otel.Tracer(tracerName).Start(ctx, "BannerComment")
+	defer span.End()
+
+	_, err := ur.executor(spanCtx).ExecContext(spanCtx,
+		"UPDATE banners SET comment=?, moderated_at=GREATEST(moderated_at, ?) WHERE id=?", comment, moderatedAt, bannerID)
+	if err != nil {
+		return fmt.Errorf("could not update banner: %w", err)
+	}
+
+	return nil
+}
+
+func (ur *UserRepo) BannerUpdateLimits(ctx context.Context, banner *entity.Banner) error {
+	spanCtx, span := otel.Tracer(tracerName).Start(ctx, "BannerUpdateLimits")
+	defer span.End()
+
+	_, err := ur.executor(spanCtx).ExecContext(spanCtx,
+		`UPDATE banners SET limit_shows=?, limit_clicks=?, limit_budget=?,
+			daily_shows=?, daily_clicks=?, daily_budget=?, hourly_shows=?, hourly_clicks=?, hourly_budget=?
+		WHERE id=? AND user_id=?`,
+		banner.LimitShows,
+		banner.LimitClicks,
+		banner.LimitBudget,
+		banner.DailyShows,
+		banner.DailyClicks,
+		banner.DailyBudget,
+		banner.HourlyShows,
+		banner.HourlyClicks,
+		banner.HourlyBudget,
+		banner.ID,
+		banner.UserID,
+	)
+	if err != nil {
+		return fmt.Errorf("could not update banner: %w", err)
+	}
+
+	return nil
+}
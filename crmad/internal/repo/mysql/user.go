@@ -103,7 +103,10 @@ func (ur *UserRepo) GetBanners(ctx context.Context, userID int) ([]*entity.Banne
 
 	err := ur.db.SelectContext(spanCtx, &banners,
 		`SELECT id, img_data, banner_text, banner_url, is_active, limit_shows,
-       		limit_clicks, limit_budget, user_id, created_at, is_validated, comment, device, category_id
+       		limit_clicks, limit_budget, user_id, created_at, is_validated, comment, device, category_id, campaign_id,
+       		daily_shows, daily_clicks, daily_budget, hourly_shows, hourly_clicks, hourly_budget, paused_until, pause_reason,
+       		start_at, end_at, schedule, timezone, is_live, geo, frequency_cap, frequency_period, bid_type, bid,
+       		start_on_approval, moderated_at
 		FROM banners WHERE user_id=?`, userID)
 	if err != nil {
 		return nil, fmt.Errorf("could not get banners: %w", err)
@@ -120,7 +123,10 @@ func (ur *UserRepo) GetBanner(ctx context.Context, bannerID int) (*entity.Banner
 
 	err := ur.db.GetContext(spanCtx, &banner,
 		`SELECT id, img_data, banner_text, banner_url, is_active, limit_shows,
-       		limit_clicks, limit_budget, user_id, created_at, is_validated, comment, device, category_id
+       		limit_clicks, limit_budget, user_id, created_at, is_validated, comment, device, category_id, campaign_id,
+       		daily_shows, daily_clicks, daily_budget, hourly_shows, hourly_clicks, hourly_budget, paused_until, pause_reason,
+       		start_at, end_at, schedule, timezone, is_live, geo, frequency_cap, frequency_period, bid_type, bid,
+       		start_on_approval, moderated_at
 		FROM banners WHERE id=?`, bannerID)
 	if err != nil {
 		return nil, fmt.Errorf("could not get banner: %w", err)
@@ -137,15 +143,12 @@ func (ur *UserRepo) CreateBanner(ctx context.Context, banner *entity.Banner) (in
 	spanCtx, span := otel.Tracer(tracerName).Start(ctx, "CreateBanner")
 	defer span.End()
 
-	tx, err := ur.db.Beginx()
-	if err != nil {
-		return 0, fmt.Errorf("could not start tx: %w", err)
-	}
-
-	_, err = tx.ExecContext(spanCtx, `INSERT INTO banners (
+	res, err := ur.executor(spanCtx).ExecContext(spanCtx, `INSERT INTO banners (
                      	img_data, banner_text, banner_url, is_active, limit_shows, 
-                     	limit_clicks, limit_budget, user_id, created_at, is_validated, comment, device, category_id)
-					VALUES (?,?,?,?,?,?,?,?,?, ?, ?, ?, ?)`,
+                     	limit_clicks, limit_budget, user_id, created_at, is_validated, comment, device, category_id, campaign_id,
+                     	daily_shows, daily_clicks, daily_budget, hourly_shows, hourly_clicks, hourly_budget,
+                     	start_at, end_at, schedule, timezone, geo, frequency_cap, frequency_period, bid_type, bid, start_on_approval)
+					VALUES (?,?,?,?,?,?,?,?,?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
 		banner.ImgData,
 		banner.BannerText,
 		banner.BannerURL,
@@ -159,25 +162,35 @@ func (ur *UserRepo) CreateBanner(ctx context.Context, banner *entity.Banner) (in
 		"",
 		banner.Device,
 		banner.CategoryID,
+		banner.CampaignID,
+		banner.DailyShows,
+		banner.DailyClicks,
+		banner.DailyBudget,
+		banner.HourlyShows,
+		banner.HourlyClicks,
+		banner.HourlyBudget,
+		banner.StartAt,
+		banner.EndAt,
+		banner.Schedule,
+		banner.TimeZone,
+		banner.Geo,
+		banner.FrequencyCap,
+		banner.FrequencyPeriod,
+		banner.BidType,
+		banner.Bid,
+		banner.StartOnApproval,
 	)
 
 	if err != nil {
 		return 0, fmt.Errorf("could not insert banner to mysql: %w", err)
 	}
 
-	var userID int
-	if err := tx.GetContext(spanCtx, &userID, "SELECT LAST_INSERT_ID()"); err != nil {
-		if err := tx.Rollback(); err != nil {
-			return 0, fmt.Errorf("could not rollback tx: %w", err)
-		}
+	bannerID, err := res.LastInsertId()
+	if err != nil {
 		return 0, fmt.Errorf("could not get banner id: %w", err)
 	}
 
-	if err := tx.Commit(); err != nil {
-		return 0, fmt.Errorf("could not commit: %w", err)
-	}
-
-	return userID, nil
+	return int(bannerID), nil
 }
 
 func (ur *UserRepo) GetCategories(ctx context.Context) ([]*entity.WebsiteCategory, error) {
@@ -235,7 +248,7 @@ func (ur *UserRepo) BannerActivate(ctx context.Context, bannerID int, userID int
 
 	conn := ur.executor(spanCtx)
 
-	res, err := conn.ExecContext(spanCtx, "UPDATE banners SET is_active=? WHERE id=? AND user_id=?", true, bannerID, userID)
+	res, err := conn.ExecContext(spanCtx, "UPDATE banners SET is_active=?, start_on_approval=0 WHERE id=? AND user_id=?", true, bannerID, userID)
 	if err != nil {
 		return fmt.Errorf("could not update: %w", err)
 	}
@@ -254,7 +267,7 @@ func (ur *UserRepo) BannerDeactivate(ctx context.Context, bannerID int, userID i
 
 	conn := ur.executor(spanCtx)
 
-	res, err := conn.ExecContext(spanCtx, "UPDATE banners SET is_active=? WHERE id=? AND user_id=?", false, bannerID, userID)
+	res, err := conn.ExecContext(spanCtx, "UPDATE banners SET is_active=?, is_live=0, paused_until=0, pause_reason='' WHERE id=? AND user_id=?", false, bannerID, userID)
 	if err != nil {
 		return fmt.Errorf("could not update: %w", err)
 	}
@@ -267,50 +280,123 @@ func (ur *UserRepo) BannerDeactivate(ctx context.Context, bannerID int, userID i
 	return nil
 }
 
-func (ur *UserRepo) BannerChangeStatus(ctx context.Context, bannerID int, status bool, comment string) error {
+// GetScheduledBanners returns active banners which have flight dates or schedule, or whose campaign has flight dates
+func (ur *UserRepo) GetScheduledBanners(ctx context.Context) ([]*entity.Banner, error) {
+	spanCtx, span := otel.Tracer(tracerName).Start(ctx, "GetScheduledBanners")
+	defer span.End()
+
+	var banners []*entity.Banner
+
+	err := ur.db.SelectContext(spanCtx, &banners,
+		`SELECT id, img_data, banner_text, banner_url, is_active, limit_shows,
+       		limit_clicks, limit_budget, user_id, created_at, is_validated, comment, device, category_id, campaign_id,
+       		daily_shows, daily_clicks, daily_budget, hourly_shows, hourly_clicks, hourly_budget, paused_until, pause_reason,
+       		start_at, end_at, schedule, timezone, is_live, geo, frequency_cap, frequency_period, bid_type, bid,
+       		start_on_approval, moderated_at
+		FROM banners WHERE is_active=? AND (start_at<>0 OR end_at<>0 OR schedule<>''
+			OR campaign_id IN (SELECT id FROM campaigns WHERE start_at<>0 OR end_at<>0))`, true)
+	if err != nil {
+		return nil, fmt.Errorf("could not get banners: %w", err)
+	}
+
+	return banners, nil
+}
+
+// BannerSetLive changes whether the banner is delivered, reports false if the banner already was in that state
+func (ur *UserRepo) BannerSetLive(ctx context.Context, bannerID int, live bool) (bool, error) {
+	spanCtx, span := otel.Tracer(tracerName).Start(ctx, "BannerSetLive")
+	defer span.End()
+
+	conn := ur.executor(spanCtx)
+
+	res, err := conn.ExecContext(spanCtx, "UPDATE banners SET is_live=? WHERE id=? AND is_active=? AND is_live=?",
+		live, bannerID, true, !live)
+	if err != nil {
+		return false, fmt.Errorf("could not update: %w", err)
+	}
+
+	id, _ := res.RowsAffected()
+
+	return id == 1, nil
+}
+
+func (ur *UserRepo) BannerPause(ctx context.Context, bannerID int, until int64, reason string) error {
+	spanCtx, span := otel.Tracer(tracerName).Start(ctx, "BannerPause")
+	defer span.End()
+
+	conn := ur.executor(spanCtx)
+
+	_, err := conn.ExecContext(spanCtx, "UPDATE banners SET paused_until=?, pause_reason=? WHERE id=? AND is_active=?",
+		until, reason, bannerID, true)
+	if err != nil {
+		return fmt.Errorf("could not update: %w", err)
+	}
+
+	return nil
+}
+
+func (ur *UserRepo) BannerResume(ctx context.Context, bannerID int) error {
+	spanCtx, span := otel.Tracer(tracerName).Start(ctx, "BannerResume")
+	defer span.End()
+
+	conn := ur.executor(spanCtx)
+
+	_, err := conn.ExecContext(spanCtx, "UPDATE banners SET paused_until=0, pause_reason='' WHERE id=?", bannerID)
+	if err != nil {
+		return fmt.Errorf("could not update: %w", err)
+	}
+
+	return nil
+}
+
+func (ur *UserRepo) BannerChangeStatus(ctx context.Context, bannerID int, status bool, comment string, moderatedAt int64) error {
 	spanCtx, span := otel.Tracer(tracerName).Start(ctx, "BannerChangeStatus")
 	defer span.End()
 
-	// TODO: there are some business-logic that should be moved to services level
-	// TODO: i gived up that logic for now, think about it later
-	// If Admin rejects user's banner during its live then the banner needs to be stopped
-
-	// type bannerForUpdate struct {
-	// 	IsActive    bool `db:"is_active"`
-	// 	IsValidated bool `db:"is_validated"`
-	// }
-	//
-	// tx, err := ur.db.Beginx()
-	// if err != nil {
-	// 	return false, fmt.Errorf("could not start tx: %w", err)
-	// }
-	//
-	// var banner bannerForUpdate
-	// if err := tx.GetContext(ctx, &banner, "SELECT is_active, is_validated FROM banners WHERE id=? FOR UPDATE", bannerID); err != nil {
-	// 	if err := tx.Rollback(); err != nil {
-	// 		return false, fmt.Errorf("could not rollback tx: %w", err)
-	// 	}
-	//
-	// 	return false, fmt.Errorf("could not get banner for update by ID: %w", err)
-	// }
-	//
-	// var isStopped bool
-	//
-	// if banner.IsActive && !status {
-	// 	isStopped = true
-	// }
-	//
-	// _, err := ur.db.ExecContext(ctx, "UPDATE banners SET is_active=? WHERE id = ?", status, bannerID)
-	// if err != nil {
-	// 	return false, fmt.Errorf("could not update banner: %w", err)
-	// }
-	//
-	// return nil
-
-	_, err := ur.db.ExecContext(spanCtx, "UPDATE banners SET is_validated=?, comment=? WHERE id = ?", status, comment, bannerID)
+	conn := ur.executor(spanCtx)
+
+	_, err := conn.ExecContext(spanCtx, "UPDATE banners SET is_validated=?, comment=?, moderated_at=GREATEST(moderated_at, ?) WHERE id = ?",
+		status, comment, moderatedAt, bannerID)
 	if err != nil {
 		return fmt.Errorf("could not update banner: %w", err)
 	}
 
 	return nil
 }
+
+// GetBannerForUpdate locks the banner until the end of transaction
+func (ur *UserRepo) GetBannerForUpdate(ctx context.Context, bannerID int) (*entity.Banner, error) {
+	spanCtx, span := otel.Tracer(tracerName).Start(ctx, "GetBannerForUpdate")
+	defer span.End()
+
+	var banner entity.Banner
+
+	err := ur.executor(spanCtx).GetContext(spanCtx, &banner,
+		`SELECT id, img_data, banner_text, banner_url, is_active, limit_shows,
+       		limit_clicks, limit_budget, user_id, created_at, is_validated, comment, device, category_id, campaign_id,
+       		daily_shows, daily_clicks, daily_budget, hourly_shows, hourly_clicks, hourly_budget, paused_until, pause_reason,
+       		start_at, end_at, schedule, timezone, is_live, geo, frequency_cap, frequency_period, bid_type, bid,
+       		start_on_approval, moderated_at
+		FROM banners WHERE id=? FOR UPDATE`, bannerID)
+	if err != nil {
+		return nil, fmt.Errorf("could not get banner: %w", err)
+	}
+
+	return &banner, nil
+}
+
+// BannerQueueStart asks to start the banner when it's approved
+func (ur *UserRepo) BannerQueueStart(ctx context.Context, bannerID int, userID int) error {
+	spanCtx, span := otel.Tracer(tracerName).Start(ctx, "BannerQueueStart")
+	defer span.End()
+
+	conn := ur.executor(spanCtx)
+
+	_, err := conn.ExecContext(spanCtx, "UPDATE banners SET start_on_approval=? WHERE id=? AND user_id=? AND is_active=?",
+		true, bannerID, userID, false)
+	if err != nil {
+		return fmt.Errorf("could not update: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,171 @@
+package user
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/crxfoz/teaserad/crmad/internal/domain/entity"
+	"github.com/crxfoz/teaserad/crmad/internal/domain/events"
+	"go.opentelemetry.io/otel"
+)
+
+type BillingRepo interface {
+	CreateInvoice(ctx context.Context, invoice *entity.Invoice) (int, error)
+	GetInvoices(ctx context.Context, userID int) ([]*entity.Invoice, error)
+	GetInvoiceForUpdate(ctx context.Context, key string) (*entity.Invoice, error)
+	InvoicePaid(ctx context.Context, invoiceID int) error
+	AddLedgerEntries(ctx context.Context, entries []*entity.LedgerEntry) error
+	GetBalance(ctx context.Context, userID int) (float64, error)
+}
+
+func (u *User) CreateInvoice(ctx context.Context, userID int, amount float64) (*entity.Invoice, error) {
+	spanCtx, span := otel.Tracer(tracerName).Start(ctx, "CreateInvoice")
+	defer span.End()
+
+	if amount <= 0 {
+		return nil, fmt.Errorf("wrong amount: %f", amount)
+	}
+
+	key, err := entity.RandomKey(16)
+	if err != nil {
+		return nil, fmt.Errorf("could not generate invoice key: %w", err)
+	}
+
+	invoice := &entity.Invoice{
+		UserID:    userID,
+		Amount:    amount,
+		Key:       key,
+		IsPaid:    false,
+		CreatedAt: time.Now().UTC().Unix(),
+	}
+
+	invoiceID, err := u.billingRepo.CreateInvoice(spanCtx, invoice)
+	if err != nil {
+		return nil, fmt.Errorf("repo failed: %w", err)
+	}
+
+	invoice.ID = invoiceID
+
+	return invoice, nil
+}
+
+func (u *User) GetInvoices(ctx context.Context, userID int) ([]*entity.Invoice, error) {
+	spanCtx, span := otel.Tracer(tracerName).Start(ctx, "GetInvoices")
+	defer span.End()
+
+	invoices, err := u.billingRepo.GetInvoices(spanCtx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("repo failed: %w", err)
+	}
+
+	if len(invoices) == 0 {
+		return []*entity.Invoice{}, nil
+	}
+
+	return invoices, nil
+}
+
+func (u *User) GetBalance(ctx context.Context, userID int) (float64, error) {
+	spanCtx, span := otel.Tracer(tracerName).Start(ctx, "GetBalance")
+	defer span.End()
+
+	balance, err := u.billingRepo.GetBalance(spanCtx, userID)
+	if err != nil {
+		return 0, fmt.Errorf("repo failed: %w", err)
+	}
+
+	return balance, nil
+}
+
+// InvoicePaid is called by payment provider, it marks the invoice as paid and tops up the wallet
+func (u *User) InvoicePaid(ctx context.Context, key string) error {
+	spanCtx, span := otel.Tracer(tracerName).Start(ctx, "InvoicePaid")
+	defer span.End()
+
+	err := u.transactor.WithTransaction(spanCtx, func(txCtx context.Context) error {
+		invoice, err := u.billingRepo.GetInvoiceForUpdate(txCtx, key)
+		if err != nil {
+			return fmt.Errorf("could not get invoice: %w", err)
+		}
+
+		if invoice.IsPaid {
+			return fmt.Errorf("invoice already paid: %d", invoice.ID)
+		}
+
+		if err := u.billingRepo.InvoicePaid(txCtx, invoice.ID); err != nil {
+			return fmt.Errorf("could not mark invoice as paid: %w", err)
+		}
+
+		entries, err := entity.NewTransfer(entity.AccountPayments, entity.AccountWallet, invoice.UserID, invoice.Amount,
+			fmt.Sprintf("invoice:%d", invoice.ID), time.Now().UTC().Unix())
+		if err != nil {
+			return fmt.Errorf("could not build transfer: %w", err)
+		}
+
+		if err := u.billingRepo.AddLedgerEntries(txCtx, entries); err != nil {
+			return fmt.Errorf("could not write ledger: %w", err)
+		}
+
+		return nil
+	})
+
+	if err != nil {
+		return fmt.Errorf("could not execute tx: %w", err)
+	}
+
+	return nil
+}
+
+// ChargeClick debits the advertiser's wallet by the price of the click.
+// The click id is the reference of the debit, so a redelivered click is charged once
+func (u *User) ChargeClick(ctx context.Context, click events.Click) error {
+	spanCtx, span := otel.Tracer(tracerName).Start(ctx, "ChargeClick")
+	defer span.End()
+
+	if click.Price <= 0 {
+		return nil
+	}
+
+	if click.ClickID == "" {
+		return fmt.Errorf("click has no id: banner %d", click.BannerID)
+	}
+
+	bannerInfo, err := u.GetBanner(spanCtx, click.BannerID)
+	if err != nil {
+		return fmt.Errorf("could not get banner info: %w", err)
+	}
+
+	entries, err := entity.NewTransfer(entity.AccountWallet, entity.AccountRevenue, bannerInfo.UserID, click.Price,
+		fmt.Sprintf("click:%s", click.ClickID), click.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("could not build transfer: %w", err)
+	}
+
+	err = u.transactor.WithTransaction(spanCtx, func(txCtx context.Context) error {
+		return u.billingRepo.AddLedgerEntries(txCtx, entries)
+	})
+	if errors.Is(err, entity.ErrDuplicateReference) {
+		return nil
+	}
+
+	if err != nil {
+		return fmt.Errorf("could not write ledger: %w", err)
+	}
+
+	return nil
+}
+
+func (u *User) checkBalance(ctx context.Context, userID int, required float64) error {
+	balance, err := u.GetBalance(ctx, userID)
+	if err != nil {
+		return fmt.Errorf("could not get balance: %w", err)
+	}
+
+	if balance < required {
+		return fmt.Errorf("insufficient balance: %f < %f", balance, required)
+	}
+
+	return nil
+}
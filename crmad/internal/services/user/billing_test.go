@@ -0,0 +1,132 @@
+package user
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/crxfoz/teaserad/crmad/internal/domain/entity"
+	"github.com/crxfoz/teaserad/crmad/internal/domain/events"
+	"github.com/stretchr/testify/assert"
+)
+
+type memoryRepo struct {
+	Repo
+	banners map[int]*entity.Banner
+}
+
+func (m *memoryRepo) GetBanner(_ context.Context, bannerID int) (*entity.Banner, error) {
+	banner, ok := m.banners[bannerID]
+	if !ok {
+		return nil, fmt.Errorf("banner not found: %d", bannerID)
+	}
+
+	return banner, nil
+}
+
+// memoryBillingRepo keeps the ledger unique by account, user and reference like the mysql one
+type memoryBillingRepo struct {
+	BillingRepo
+	invoices map[string]*entity.Invoice
+	ledger   []*entity.LedgerEntry
+}
+
+func (m *memoryBillingRepo) GetInvoiceForUpdate(_ context.Context, key string) (*entity.Invoice, error) {
+	invoice, ok := m.invoices[key]
+	if !ok {
+		return nil, fmt.Errorf("invoice not found: %s", key)
+	}
+
+	return invoice, nil
+}
+
+func (m *memoryBillingRepo) InvoicePaid(_ context.Context, invoiceID int) error {
+	for _, invoice := range m.invoices {
+		if invoice.ID == invoiceID {
+			invoice.IsPaid = true
+			return nil
+		}
+	}
+
+	return fmt.Errorf("invoice not found: %d", invoiceID)
+}
+
+func (m *memoryBillingRepo) AddLedgerEntries(_ context.Context, entries []*entity.LedgerEntry) error {
+	for _, entry := range entries {
+		for _, item := range m.ledger {
+			if item.Account == entry.Account && item.UserID == entry.UserID && item.Reference == entry.Reference {
+				return entity.ErrDuplicateReference
+			}
+		}
+	}
+
+	m.ledger = append(m.ledger, entries...)
+
+	return nil
+}
+
+func (m *memoryBillingRepo) GetBalance(_ context.Context, userID int) (float64, error) {
+	var balance float64
+
+	for _, item := range m.ledger {
+		if item.Account == entity.AccountWallet && item.UserID == userID {
+			balance += item.Amount
+		}
+	}
+
+	return balance, nil
+}
+
+type noTransactor struct{}
+
+func (noTransactor) WithTransaction(ctx context.Context, fn func(ctx context.Context) error) error {
+	return fn(ctx)
+}
+
+func newBillingUser() (*User, *memoryBillingRepo) {
+	repo := &memoryRepo{banners: map[int]*entity.Banner{1: {ID: 1, UserID: 7}}}
+	billingRepo := &memoryBillingRepo{
+		invoices: map[string]*entity.Invoice{"key": {ID: 3, UserID: 7, Amount: 10, Key: "key"}},
+	}
+
+	return New(repo, nil, nil, nil, noTransactor{}, billingRepo), billingRepo
+}
+
+func TestUser_InvoicePaid(t *testing.T) {
+	svc, _ := newBillingUser()
+	ctx := context.Background()
+
+	assert.NoError(t, svc.InvoicePaid(ctx, "key"))
+	assert.Error(t, svc.InvoicePaid(ctx, "key"))
+	assert.Error(t, svc.InvoicePaid(ctx, "unknown"))
+
+	balance, err := svc.GetBalance(ctx, 7)
+	assert.NoError(t, err)
+	assert.Equal(t, 10.0, balance)
+}
+
+func TestUser_ChargeClick(t *testing.T) {
+	svc, billingRepo := newBillingUser()
+	ctx := context.Background()
+
+	assert.NoError(t, svc.InvoicePaid(ctx, "key"))
+
+	click := events.Click{ClickID: "a1", BannerID: 1, PlatformID: 2, ViewID: "5f0c", Price: 0.25, CreatedAt: 100}
+
+	assert.NoError(t, svc.ChargeClick(ctx, click))
+	// redelivered click is already charged
+	assert.NoError(t, svc.ChargeClick(ctx, click))
+
+	// another click of the same view is charged on its own
+	click.ClickID = "a2"
+	assert.NoError(t, svc.ChargeClick(ctx, click))
+
+	balance, err := svc.GetBalance(ctx, 7)
+	assert.NoError(t, err)
+	assert.Equal(t, 9.5, balance)
+	assert.Len(t, billingRepo.ledger, 6)
+
+	assert.NoError(t, svc.ChargeClick(ctx, events.Click{ClickID: "a3", BannerID: 1}))
+	assert.Error(t, svc.ChargeClick(ctx, events.Click{BannerID: 1, Price: 0.25}))
+	assert.Error(t, svc.ChargeClick(ctx, events.Click{ClickID: "a4", BannerID: 2, Price: 0.25}))
+}
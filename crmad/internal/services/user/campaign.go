@@ -0,0 +1,212 @@
+package user
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/crxfoz/teaserad/crmad/internal/domain/entity"
+	"github.com/crxfoz/teaserad/crmad/internal/domain/events"
+	"go.opentelemetry.io/otel"
+)
+
+func (u *User) CreateCampaign(ctx context.Context, campaign *entity.Campaign) (int, error) {
+	spanCtx, span := otel.Tracer(tracerName).Start(ctx, "CreateCampaign")
+	defer span.End()
+
+	if err := campaign.Validate(); err != nil {
+		return 0, fmt.Errorf("campaign not valide: %w", err)
+	}
+
+	campaign.IsActive = false
+	campaign.CreatedAt = time.Now().UTC().Unix()
+
+	campaignID, err := u.repo.CreateCampaign(spanCtx, campaign)
+	if err != nil {
+		return 0, fmt.Errorf("repo failed: %w", err)
+	}
+
+	return campaignID, nil
+}
+
+func (u *User) GetCampaigns(ctx context.Context, userID int) ([]*entity.Campaign, error) {
+	spanCtx, span := otel.Tracer(tracerName).Start(ctx, "GetCampaigns")
+	defer span.End()
+
+	campaigns, err := u.repo.GetCampaigns(spanCtx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("repo failed: %w", err)
+	}
+
+	if len(campaigns) == 0 {
+		return []*entity.Campaign{}, nil
+	}
+
+	return campaigns, nil
+}
+
+func (u *User) GetCampaign(ctx context.Context, campaignID int, userID int) (*entity.Campaign, error) {
+	spanCtx, span := otel.Tracer(tracerName).Start(ctx, "GetCampaign")
+	defer span.End()
+
+	campaign, err := u.repo.GetCampaign(spanCtx, campaignID)
+	if err != nil {
+		return nil, fmt.Errorf("repo failed: %w", err)
+	}
+
+	if campaign.UserID != userID {
+		return nil, fmt.Errorf("campaign not found: %d", campaignID)
+	}
+
+	return campaign, nil
+}
+
+func (u *User) UpdateCampaign(ctx context.Context, campaign *entity.Campaign) error {
+	spanCtx, span := otel.Tracer(tracerName).Start(ctx, "UpdateCampaign")
+	defer span.End()
+
+	if err := campaign.Validate(); err != nil {
+		return fmt.Errorf("campaign not valide: %w", err)
+	}
+
+	err := u.transactor.WithTransaction(spanCtx, func(txCtx context.Context) error {
+		if err := u.repo.UpdateCampaign(txCtx, campaign); err != nil {
+			return fmt.Errorf("repo failed: %w", err)
+		}
+
+		// budgets are shared by banners of the campaign, so delivery checks them against spend of all the banners
+		err := u.bannerActor.CampaignLimits(txCtx, events.CampaignLimits{
+			CampaignID:  campaign.ID,
+			TotalBudget: campaign.TotalBudget,
+			DailyBudget: campaign.DailyBudget,
+		})
+		if err != nil {
+			return fmt.Errorf("could not send campaign limits: %w", err)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("could not execute tx: %w", err)
+	}
+
+	return nil
+}
+
+func (u *User) DeleteCampaign(ctx context.Context, campaignID int, userID int) error {
+	spanCtx, span := otel.Tracer(tracerName).Start(ctx, "DeleteCampaign")
+	defer span.End()
+
+	err := u.transactor.WithTransaction(spanCtx, func(txCtx context.Context) error {
+		return u.repo.DeleteCampaign(txCtx, campaignID, userID)
+	})
+	if err != nil {
+		return fmt.Errorf("could not execute tx: %w", err)
+	}
+
+	return nil
+}
+
+// CampaignStart activates the campaign and starts every validated banner attached to it
+func (u *User) CampaignStart(ctx context.Context, campaignID int, userID int) error {
+	spanCtx, span := otel.Tracer(tracerName).Start(ctx, "CampaignStart")
+	defer span.End()
+
+	campaign, err := u.GetCampaign(spanCtx, campaignID, userID)
+	if err != nil {
+		return fmt.Errorf("could not get campaign: %w", err)
+	}
+
+	if !campaign.CanBeStarted(time.Now()) {
+		return fmt.Errorf("campaign cannot be started")
+	}
+
+	banners, err := u.repo.GetCampaignBanners(spanCtx, campaignID)
+	if err != nil {
+		return fmt.Errorf("could not get campaign banners: %w", err)
+	}
+
+	var required float64
+	for _, banner := range banners {
+		if banner.CanBeStarted() {
+			required += banner.LimitBudget
+		}
+	}
+
+	// banners can't spend more than the budget of the campaign together
+	if campaign.TotalBudget > 0 && required > campaign.TotalBudget {
+		required = campaign.TotalBudget
+	}
+
+	if err := u.checkBalance(spanCtx, userID, required); err != nil {
+		return fmt.Errorf("campaign cannot be started: %w", err)
+	}
+
+	err = u.transactor.WithTransaction(spanCtx, func(txCtx context.Context) error {
+		if err := u.repo.CampaignActivate(txCtx, campaignID, userID); err != nil {
+			return fmt.Errorf("could not activate campaign: %w", err)
+		}
+
+		for _, banner := range banners {
+			if !banner.CanBeStarted() {
+				continue
+			}
+
+			if err := u.startBanner(txCtx, banner); err != nil {
+				return fmt.Errorf("could not start banner %d: %w", banner.ID, err)
+			}
+		}
+
+		return nil
+	})
+
+	if err != nil {
+		return fmt.Errorf("could not execute tx: %w", err)
+	}
+
+	return nil
+}
+
+// CampaignStop pauses the campaign and stops every active banner attached to it
+func (u *User) CampaignStop(ctx context.Context, campaignID int, userID int) error {
+	spanCtx, span := otel.Tracer(tracerName).Start(ctx, "CampaignStop")
+	defer span.End()
+
+	campaign, err := u.GetCampaign(spanCtx, campaignID, userID)
+	if err != nil {
+		return fmt.Errorf("could not get campaign: %w", err)
+	}
+
+	if !campaign.CanBeStopped() {
+		return fmt.Errorf("campaign already stopped")
+	}
+
+	banners, err := u.repo.GetCampaignBanners(spanCtx, campaignID)
+	if err != nil {
+		return fmt.Errorf("could not get campaign banners: %w", err)
+	}
+
+	err = u.transactor.WithTransaction(spanCtx, func(txCtx context.Context) error {
+		if err := u.repo.CampaignDeactivate(txCtx, campaignID, userID); err != nil {
+			return fmt.Errorf("could not deactivate campaign: %w", err)
+		}
+
+		for _, banner := range banners {
+			if !banner.CanBeStopped() {
+				continue
+			}
+
+			if err := u.stopBanner(txCtx, banner); err != nil {
+				return fmt.Errorf("could not stop banner %d: %w", banner.ID, err)
+			}
+		}
+
+		return nil
+	})
+
+	if err != nil {
+		return fmt.Errorf("could not execute tx: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,213 @@
+package user
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/crxfoz/teaserad/crmad/internal/domain/entity"
+	"github.com/crxfoz/teaserad/crmad/internal/domain/events"
+	"go.opentelemetry.io/otel"
+)
+
+// UpdateBanner applies new limits at once and sends changed creative to moderation, the banner keeps
+// serving its current creative until the revision is approved. Reports whether moderation is pending.
+// The banner is locked while it's updated, so concurrent resolution or another update is not overwritten
+func (u *User) UpdateBanner(ctx context.Context, isUserValidated bool, bannerID int, userID int, patch *entity.BannerPatch) (bool, error) {
+	spanCtx, span := otel.Tracer(tracerName).Start(ctx, "UpdateBanner")
+	defer span.End()
+
+	if !patch.HasContent() && !patch.HasLimits() {
+		return false, fmt.Errorf("nothing to update")
+	}
+
+	categories, err := u.GetCategories(spanCtx)
+	if err != nil {
+		return false, fmt.Errorf("could not get categories: %w", err)
+	}
+
+	// creative of validated advertiser doesn't need moderation just like a new banner
+	pending := patch.HasContent() && !isUserValidated
+
+	err = u.transactor.WithTransaction(spanCtx, func(txCtx context.Context) error {
+		bannerInfo, err := u.repo.GetBannerForUpdate(txCtx, bannerID)
+		if err != nil {
+			return fmt.Errorf("could not get banner: %w", err)
+		}
+
+		if bannerInfo.UserID != userID {
+			return fmt.Errorf("banner not found")
+		}
+
+		updated := *bannerInfo
+		patch.ApplyLimits(&updated)
+
+		var revision *entity.BannerRevision
+		if patch.HasContent() {
+			revision = patch.Revision(bannerInfo, time.Now().UTC().Unix())
+			revision.Apply(&updated)
+
+			if err := validateImg(revision.ImgData); err != nil {
+				return err
+			}
+		}
+
+		if err := updated.Validate(categories); err != nil {
+			return fmt.Errorf("banner not valide: %w", err)
+		}
+
+		if patch.HasLimits() && updated.IsActive && updated.LimitBudget > bannerInfo.LimitBudget {
+			if err := u.checkBalance(txCtx, userID, updated.LimitBudget); err != nil {
+				return fmt.Errorf("budget cannot be raised: %w", err)
+			}
+		}
+
+		if patch.HasLimits() {
+			if err := u.updateLimits(txCtx, &updated); err != nil {
+				return err
+			}
+		}
+
+		if revision == nil {
+			return nil
+		}
+
+		if !pending {
+			if err := u.applyRevision(txCtx, &updated, revision, bannerInfo.Comment, bannerInfo.ModeratedAt); err != nil {
+				return err
+			}
+
+			updated.IsValidated = true
+
+			return u.startApproved(txCtx, &updated)
+		}
+
+		if err := u.repo.SaveRevision(txCtx, revision); err != nil {
+			return fmt.Errorf("could not save revision: %w", err)
+		}
+
+		err = u.bannerEventer.BannerRevised(txCtx, events.BannerRevised{
+			BannerID:   updated.ID,
+			UserID:     updated.UserID,
+			Device:     updated.Device,
+			CategoryID: updated.CategoryID,
+			CreatedAt:  revision.CreatedAt,
+		})
+		if err != nil {
+			return fmt.Errorf("could not send revision to moderation: %w", err)
+		}
+
+		return nil
+	})
+
+	if err != nil {
+		return false, fmt.Errorf("could not execute tx: %w", err)
+	}
+
+	return pending, nil
+}
+
+func (u *User) GetRevision(ctx context.Context, bannerID int) (*entity.BannerRevision, error) {
+	spanCtx, span := otel.Tracer(tracerName).Start(ctx, "GetRevision")
+	defer span.End()
+
+	revision, err := u.repo.GetRevision(spanCtx, bannerID)
+	if err != nil {
+		return nil, fmt.Errorf("repo failed: %w", err)
+	}
+
+	return revision, nil
+}
+
+// revisionResolved applies moderator's resolution to the pending revision, rejected revision is dropped
+// and the banner goes on with its current creative
+func (u *User) revisionResolved(ctx context.Context, revision *entity.BannerRevision, updated events.BannerUpdated) error {
+	err := u.transactor.WithTransaction(ctx, func(txCtx context.Context) error {
+		bannerInfo, err := u.repo.GetBannerForUpdate(txCtx, revision.BannerID)
+		if err != nil {
+			return fmt.Errorf("could not get banner: %w", err)
+		}
+
+		if updated.IsStale(bannerInfo.ModeratedAt) {
+			return nil
+		}
+
+		if err := u.repo.DeleteRevision(txCtx, revision.ID); err != nil {
+			return fmt.Errorf("could not delete revision: %w", err)
+		}
+
+		if !updated.Approved {
+			return u.repo.BannerComment(txCtx, bannerInfo.ID, updated.Comment, updated.ResolvedAt)
+		}
+
+		revision.Apply(bannerInfo)
+
+		if err := u.applyRevision(txCtx, bannerInfo, revision, updated.Comment, updated.ResolvedAt); err != nil {
+			return err
+		}
+
+		bannerInfo.IsValidated = true
+
+		return u.startApproved(txCtx, bannerInfo)
+	})
+
+	if err != nil {
+		return fmt.Errorf("could not execute tx: %w", err)
+	}
+
+	return nil
+}
+
+// applyRevision replaces the creative of the banner and sends it to delivery if the banner is live,
+// the banner is not restarted, so caps it has reached still hold. Expected to be called within transaction
+func (u *User) applyRevision(txCtx context.Context, bannerInfo *entity.Banner, revision *entity.BannerRevision, comment string, moderatedAt int64) error {
+	if err := u.repo.BannerApplyRevision(txCtx, revision, comment, moderatedAt); err != nil {
+		return fmt.Errorf("could not apply revision: %w", err)
+	}
+
+	if !bannerInfo.IsLive {
+		return nil
+	}
+
+	err := u.bannerActor.BannerCreative(txCtx, events.BannerCreative{
+		BannerID:   bannerInfo.ID,
+		ImgData:    revision.ImgData,
+		BannerText: revision.BannerText,
+		BannerURL:  revision.BannerURL,
+	})
+	if err != nil {
+		return fmt.Errorf("could not send creative: %w", err)
+	}
+
+	return nil
+}
+
+// updateLimits stores new limits and sends them to delivery if the banner is live,
+// expected to be called within transaction
+func (u *User) updateLimits(txCtx context.Context, bannerInfo *entity.Banner) error {
+	if err := u.repo.BannerUpdateLimits(txCtx, bannerInfo); err != nil {
+		return fmt.Errorf("could not update limits: %w", err)
+	}
+
+	if !bannerInfo.IsLive {
+		return nil
+	}
+
+	err := u.bannerActor.BannerLimits(txCtx, events.BannerLimits{
+		BannerID:     bannerInfo.ID,
+		LimitShows:   bannerInfo.LimitShows,
+		LimitClicks:  bannerInfo.LimitClicks,
+		LimitBudget:  bannerInfo.LimitBudget,
+		DailyShows:   bannerInfo.DailyShows,
+		DailyClicks:  bannerInfo.DailyClicks,
+		DailyBudget:  bannerInfo.DailyBudget,
+		HourlyShows:  bannerInfo.HourlyShows,
+		HourlyClicks: bannerInfo.HourlyClicks,
+		HourlyBudget: bannerInfo.HourlyBudget,
+	})
+	if err != nil {
+		return fmt.Errorf("could not send limits: %w", err)
+	}
+
+	return nil
+}
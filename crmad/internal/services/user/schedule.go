@@ -0,0 +1,77 @@
+package user
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/crxfoz/teaserad/crmad/internal/domain/entity"
+	"github.com/crxfoz/teaserad/crmad/internal/domain/events"
+	"go.opentelemetry.io/otel"
+)
+
+// ApplySchedule sends active banners to delivery and removes them from it on boundaries of their flight and schedule,
+// flight of a banner is limited by the flight of its campaign
+func (u *User) ApplySchedule(ctx context.Context) (errRet error) {
+	spanCtx, span := otel.Tracer(tracerName).Start(ctx, "ApplySchedule")
+	defer span.End()
+
+	banners, err := u.repo.GetScheduledBanners(spanCtx)
+	if err != nil {
+		return fmt.Errorf("repo failed: %w", err)
+	}
+
+	now := time.Now()
+
+	for _, banner := range banners {
+		// failure of the banner mustn't block the others
+		if err := u.applySchedule(spanCtx, banner, now); err != nil && errRet == nil {
+			errRet = fmt.Errorf("could not apply schedule to banner %d: %w", banner.ID, err)
+		}
+	}
+
+	return errRet
+}
+
+func (u *User) applySchedule(ctx context.Context, banner *entity.Banner, now time.Time) error {
+	if err := u.campaignWindow(ctx, banner); err != nil {
+		return err
+	}
+
+	inWindow := banner.InWindow(now)
+
+	switch {
+	case banner.IsFinished(now):
+		return u.transactor.WithTransaction(ctx, func(txCtx context.Context) error {
+			return u.stopBanner(txCtx, banner)
+		})
+	case inWindow && !banner.IsLive:
+		return u.transactor.WithTransaction(ctx, func(txCtx context.Context) error {
+			return u.goLive(txCtx, banner)
+		})
+	case !inWindow && banner.IsLive:
+		return u.transactor.WithTransaction(ctx, func(txCtx context.Context) error {
+			return u.goOffline(txCtx, banner)
+		})
+	}
+
+	return nil
+}
+
+// goOffline removes active banner from delivery until its schedule allows it again, expected to be called within transaction
+func (u *User) goOffline(txCtx context.Context, bannerInfo *entity.Banner) error {
+	offline, err := u.repo.BannerSetLive(txCtx, bannerInfo.ID, false)
+	if err != nil {
+		return fmt.Errorf("could not set banner offline: %w", err)
+	}
+
+	if !offline {
+		return nil
+	}
+
+	if err := u.bannerActor.BannerStop(txCtx, events.BannerStop{BannerID: bannerInfo.ID}); err != nil {
+		return fmt.Errorf("could not stop banner: %w", err)
+	}
+
+	return nil
+}
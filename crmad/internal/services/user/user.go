@@ -3,6 +3,7 @@ package user
 import (
 	"bytes"
 	"context"
+	"errors"
 	"fmt"
 	"image"
 	_ "image/jpeg"
@@ -26,20 +27,44 @@ type Repo interface {
 	CreateUser(ctx context.Context, user *entity.User) (int, error)
 	GetBanners(ctx context.Context, userID int) ([]*entity.Banner, error)
 	CreateBanner(ctx context.Context, banner *entity.Banner) (int, error)
-	BannerChangeStatus(ctx context.Context, bannerID int, status bool, comment string) error
+	BannerChangeStatus(ctx context.Context, bannerID int, status bool, comment string, moderatedAt int64) error
+	GetBannerForUpdate(ctx context.Context, bannerID int) (*entity.Banner, error)
+	BannerQueueStart(ctx context.Context, bannerID int, userID int) error
 	GetBanner(ctx context.Context, bannerID int) (*entity.Banner, error)
 	GetCategories(ctx context.Context) ([]*entity.WebsiteCategory, error)
 	BannerActivate(ctx context.Context, bannerID int, userID int) error
 	BannerDeactivate(ctx context.Context, bannerID int, userID int) error
+	GetScheduledBanners(ctx context.Context) ([]*entity.Banner, error)
+	BannerSetLive(ctx context.Context, bannerID int, live bool) (bool, error)
+	BannerPause(ctx context.Context, bannerID int, until int64, reason string) error
+	BannerResume(ctx context.Context, bannerID int) error
+	CreateCampaign(ctx context.Context, campaign *entity.Campaign) (int, error)
+	GetCampaigns(ctx context.Context, userID int) ([]*entity.Campaign, error)
+	GetCampaign(ctx context.Context, campaignID int) (*entity.Campaign, error)
+	UpdateCampaign(ctx context.Context, campaign *entity.Campaign) error
+	DeleteCampaign(ctx context.Context, campaignID int, userID int) error
+	GetCampaignBanners(ctx context.Context, campaignID int) ([]*entity.Banner, error)
+	CampaignActivate(ctx context.Context, campaignID int, userID int) error
+	CampaignDeactivate(ctx context.Context, campaignID int, userID int) error
+	BannerUpdateLimits(ctx context.Context, banner *entity.Banner) error
+	SaveRevision(ctx context.Context, revision *entity.BannerRevision) error
+	GetRevision(ctx context.Context, bannerID int) (*entity.BannerRevision, error)
+	DeleteRevision(ctx context.Context, revisionID int) error
+	BannerApplyRevision(ctx context.Context, revision *entity.BannerRevision, comment string, moderatedAt int64) error
+	BannerComment(ctx context.Context, bannerID int, comment string, moderatedAt int64) error
 }
 
 type BannerEventer interface {
 	BannerCreated(ctx context.Context, msg events.BannerCreated) error
+	BannerRevised(ctx context.Context, msg events.BannerRevised) error
 }
 
 type BannerActor interface {
 	BannerStart(ctx context.Context, msg events.BannerStart) error
 	BannerStop(ctx context.Context, msg events.BannerStop) error
+	BannerLimits(ctx context.Context, msg events.BannerLimits) error
+	BannerCreative(ctx context.Context, msg events.BannerCreative) error
+	CampaignLimits(ctx context.Context, msg events.CampaignLimits) error
 }
 
 type Transactor interface {
@@ -52,10 +77,11 @@ type User struct {
 	bannerEventer BannerEventer
 	bannerActor   BannerActor
 	transactor    Transactor
+	billingRepo   BillingRepo
 }
 
-func New(repo Repo, auth Auth, bannerEventer BannerEventer, bannerActor BannerActor, transactor Transactor) *User {
-	return &User{repo: repo, auth: auth, bannerEventer: bannerEventer, bannerActor: bannerActor, transactor: transactor}
+func New(repo Repo, auth Auth, bannerEventer BannerEventer, bannerActor BannerActor, transactor Transactor, billingRepo BillingRepo) *User {
+	return &User{repo: repo, auth: auth, bannerEventer: bannerEventer, bannerActor: bannerActor, transactor: transactor, billingRepo: billingRepo}
 }
 
 func (u *User) AddCategory(ctx context.Context, category *entity.WebsiteCategory) error {
@@ -104,6 +130,29 @@ func (u *User) BannerReachedLimits(ctx context.Context, item events.BannerReache
 	return nil
 }
 
+// BannerPaused keeps the banner active but shows that it waits for the next period because of its caps
+func (u *User) BannerPaused(ctx context.Context, item events.BannerPaused) error {
+	spanCtx, span := otel.Tracer(tracerName).Start(ctx, "BannerPaused")
+	defer span.End()
+
+	if err := u.repo.BannerPause(spanCtx, item.BannerID, item.Until, item.Reason); err != nil {
+		return fmt.Errorf("repo failed: %w", err)
+	}
+
+	return nil
+}
+
+func (u *User) BannerResumed(ctx context.Context, item events.BannerResumed) error {
+	spanCtx, span := otel.Tracer(tracerName).Start(ctx, "BannerResumed")
+	defer span.End()
+
+	if err := u.repo.BannerResume(spanCtx, item.BannerID); err != nil {
+		return fmt.Errorf("repo failed: %w", err)
+	}
+
+	return nil
+}
+
 func (u *User) BannerStart(ctx context.Context, bannerID int, userID int) error {
 	spanCtx, span := otel.Tracer(tracerName).Start(ctx, "BannerStart")
 	defer span.End()
@@ -113,33 +162,37 @@ func (u *User) BannerStart(ctx context.Context, bannerID int, userID int) error
 		return fmt.Errorf("could not get banner: %w", err)
 	}
 
+	if bannerInfo.UserID != userID {
+		return fmt.Errorf("banner not found")
+	}
+
+	// banner waiting for moderation is started as soon as it's approved
+	if bannerInfo.CanBeQueued() {
+		if err := u.repo.BannerQueueStart(spanCtx, bannerID, userID); err != nil {
+			return fmt.Errorf("could not queue banner: %w", err)
+		}
+
+		return nil
+	}
+
 	if !bannerInfo.CanBeStarted() {
 		return fmt.Errorf("banner cannot be started")
 	}
 
-	err = u.transactor.WithTransaction(spanCtx, func(txCtx context.Context) error {
-		if err := u.repo.BannerActivate(txCtx, bannerID, userID); err != nil {
-			return fmt.Errorf("could not activate banner: %w", err)
-		}
+	if err := u.campaignWindow(spanCtx, bannerInfo); err != nil {
+		return err
+	}
 
-		item := events.BannerStart{
-			BannerID:    bannerID,
-			UserID:      bannerInfo.UserID,
-			ImgData:     bannerInfo.ImgData,
-			BannerText:  bannerInfo.BannerText,
-			BannerURL:   bannerInfo.BannerURL,
-			LimitShows:  bannerInfo.LimitShows,
-			LimitClicks: bannerInfo.LimitClicks,
-			LimitBudget: bannerInfo.LimitBudget,
-			Device:      bannerInfo.Device,
-			CategoryID:  bannerInfo.CategoryID,
-		}
+	if bannerInfo.IsFinished(time.Now()) {
+		return fmt.Errorf("banner flight is over")
+	}
 
-		if err := u.bannerActor.BannerStart(spanCtx, item); err != nil {
-			return fmt.Errorf("could not stop banner: %w", err)
-		}
+	if err := u.checkBalance(spanCtx, userID, bannerInfo.LimitBudget); err != nil {
+		return fmt.Errorf("banner cannot be started: %w", err)
+	}
 
-		return nil
+	err = u.transactor.WithTransaction(spanCtx, func(txCtx context.Context) error {
+		return u.startBanner(txCtx, bannerInfo)
 	})
 
 	if err != nil {
@@ -149,6 +202,116 @@ func (u *User) BannerStart(ctx context.Context, bannerID int, userID int) error
 	return nil
 }
 
+// startBanner activates the banner and sends it to delivery if it's within its schedule,
+// expected to be called within transaction
+func (u *User) startBanner(txCtx context.Context, bannerInfo *entity.Banner) error {
+	if err := u.repo.BannerActivate(txCtx, bannerInfo.ID, bannerInfo.UserID); err != nil {
+		return fmt.Errorf("could not activate banner: %w", err)
+	}
+
+	if err := u.campaignWindow(txCtx, bannerInfo); err != nil {
+		return err
+	}
+
+	// banner out of its schedule is sent to delivery by scheduler later
+	if !bannerInfo.InWindow(time.Now()) {
+		return nil
+	}
+
+	return u.goLive(txCtx, bannerInfo)
+}
+
+// campaignWindow narrows flight of the banner to the flight of its campaign, so the banner is not delivered out of it
+func (u *User) campaignWindow(ctx context.Context, bannerInfo *entity.Banner) error {
+	if bannerInfo.CampaignID == 0 {
+		return nil
+	}
+
+	campaign, err := u.repo.GetCampaign(ctx, bannerInfo.CampaignID)
+	if err != nil {
+		return fmt.Errorf("could not get campaign: %w", err)
+	}
+
+	campaign.Restrict(bannerInfo)
+
+	return nil
+}
+
+// goLive sends active banner to delivery, expected to be called within transaction
+func (u *User) goLive(txCtx context.Context, bannerInfo *entity.Banner) error {
+	live, err := u.repo.BannerSetLive(txCtx, bannerInfo.ID, true)
+	if err != nil {
+		return fmt.Errorf("could not set banner live: %w", err)
+	}
+
+	if !live {
+		return nil
+	}
+
+	item, err := u.startEvent(txCtx, bannerInfo)
+	if err != nil {
+		return err
+	}
+
+	if err := u.bannerActor.BannerStart(txCtx, item); err != nil {
+		return fmt.Errorf("could not start banner: %w", err)
+	}
+
+	return nil
+}
+
+// startEvent builds the event delivery starts the banner with
+func (u *User) startEvent(ctx context.Context, bannerInfo *entity.Banner) (events.BannerStart, error) {
+	item := events.BannerStart{
+		BannerID:    bannerInfo.ID,
+		UserID:      bannerInfo.UserID,
+		CampaignID:  bannerInfo.CampaignID,
+		ImgData:     bannerInfo.ImgData,
+		BannerText:  bannerInfo.BannerText,
+		BannerURL:   bannerInfo.BannerURL,
+		LimitShows:  bannerInfo.LimitShows,
+		LimitClicks: bannerInfo.LimitClicks,
+		LimitBudget: bannerInfo.LimitBudget,
+		Device:      bannerInfo.Device,
+		CategoryID:  bannerInfo.CategoryID,
+
+		DailyShows:   bannerInfo.DailyShows,
+		DailyClicks:  bannerInfo.DailyClicks,
+		DailyBudget:  bannerInfo.DailyBudget,
+		HourlyShows:  bannerInfo.HourlyShows,
+		HourlyClicks: bannerInfo.HourlyClicks,
+		HourlyBudget: bannerInfo.HourlyBudget,
+	}
+
+	// flight window is used by delivery to spread the budget evenly,
+	// banner is delivered only within the window of its campaign
+	item.StartAt = bannerInfo.StartAt
+	item.EndAt = bannerInfo.EndAt
+
+	if bannerInfo.CampaignID != 0 {
+		campaign, err := u.repo.GetCampaign(ctx, bannerInfo.CampaignID)
+		if err != nil {
+			return events.BannerStart{}, fmt.Errorf("could not get campaign: %w", err)
+		}
+
+		item.StartAt, item.EndAt = campaign.Window(bannerInfo)
+
+		item.UTM = campaign.UTM()
+		item.CampaignBudget = campaign.TotalBudget
+		item.CampaignDailyBudget = campaign.DailyBudget
+	}
+
+	item.Schedule = bannerInfo.Schedule
+	item.TimeZone = bannerInfo.TimeZone
+	item.Geo = bannerInfo.Geo
+	item.FrequencyCap = bannerInfo.FrequencyCap
+	item.FrequencyPeriod = bannerInfo.FrequencyPeriod
+	item.BidType = bannerInfo.BidType
+	item.Bid = bannerInfo.Bid
+
+	return item, nil
+}
+
 func (u *User) BannerStop(ctx context.Context, bannerID int, userID int) error {
 	spanCtx, span := otel.Tracer(tracerName).Start(ctx, "BannerStop")
 	defer span.End()
@@ -158,20 +321,16 @@ func (u *User) BannerStop(ctx context.Context, bannerID int, userID int) error {
 		return fmt.Errorf("could not get banner: %w", err)
 	}
 
+	if bannerInfo.UserID != userID {
+		return fmt.Errorf("banner not found")
+	}
+
 	if !bannerInfo.CanBeStopped() {
 		return fmt.Errorf("banner already stopped")
 	}
 
 	err = u.transactor.WithTransaction(spanCtx, func(txCtx context.Context) error {
-		if err := u.repo.BannerDeactivate(txCtx, bannerID, userID); err != nil {
-			return fmt.Errorf("could not deactive banner: %w", err)
-		}
-
-		if err := u.bannerActor.BannerStop(spanCtx, events.BannerStop{BannerID: bannerID}); err != nil {
-			return fmt.Errorf("could not stop banner: %w", err)
-		}
-
-		return nil
+		return u.stopBanner(txCtx, bannerInfo)
 	})
 
 	if err != nil {
@@ -181,6 +340,19 @@ func (u *User) BannerStop(ctx context.Context, bannerID int, userID int) error {
 	return nil
 }
 
+// stopBanner deactivates the banner and removes it from delivery, expected to be called within transaction
+func (u *User) stopBanner(txCtx context.Context, bannerInfo *entity.Banner) error {
+	if err := u.repo.BannerDeactivate(txCtx, bannerInfo.ID, bannerInfo.UserID); err != nil {
+		return fmt.Errorf("could not deactive banner: %w", err)
+	}
+
+	if err := u.bannerActor.BannerStop(txCtx, events.BannerStop{BannerID: bannerInfo.ID}); err != nil {
+		return fmt.Errorf("could not stop banner: %w", err)
+	}
+
+	return nil
+}
+
 func (u *User) CreateUser(ctx context.Context, user *entity.User) (int, error) {
 	spanCtx, span := otel.Tracer(tracerName).Start(ctx, "CreateUser")
 	defer span.End()
@@ -245,6 +417,23 @@ func (u *User) CreateBanner(ctx context.Context, isUserValidated bool, banner *e
 	spanCtx, span := otel.Tracer(tracerName).Start(ctx, "CreateBanner")
 	defer span.End()
 
+	if banner.CampaignID != 0 {
+		campaign, err := u.GetCampaign(spanCtx, banner.CampaignID, banner.UserID)
+		if err != nil {
+			return 0, fmt.Errorf("could not get campaign: %w", err)
+		}
+
+		campaign.ApplyDefaults(banner)
+	}
+
+	if banner.BidType == "" {
+		banner.BidType = entity.BidCPC
+	}
+
+	if banner.Bid == 0 {
+		banner.Bid = entity.DefaultBid
+	}
+
 	categories, err := u.GetCategories(spanCtx)
 	if err != nil {
 		return 0, fmt.Errorf("could not get categories: %w", err)
@@ -254,47 +443,81 @@ func (u *User) CreateBanner(ctx context.Context, isUserValidated bool, banner *e
 		return 0, fmt.Errorf("banned not valide: %w", err)
 	}
 
+	if banner.TimeZone == "" {
+		banner.TimeZone = "UTC"
+	}
+
+	if banner.FrequencyCap > 0 && banner.FrequencyPeriod == 0 {
+		banner.FrequencyPeriod = entity.DefaultFrequencyPeriod
+	}
+
 	banner.CreatedAt = time.Now().UTC().Unix()
 	banner.IsValidated = isUserValidated
 
-	imgFormat := http.DetectContentType(banner.ImgData)
+	if err := validateImg(banner.ImgData); err != nil {
+		return 0, err
+	}
+
+	var bannerID int
+
+	// moderation gets the banner only if it has been stored
+	err = u.transactor.WithTransaction(spanCtx, func(txCtx context.Context) error {
+		id, err := u.repo.CreateBanner(txCtx, banner)
+		if err != nil {
+			return fmt.Errorf("repo failed: %w", err)
+		}
+
+		bannerID = id
+
+		err = u.bannerEventer.BannerCreated(txCtx, events.BannerCreated{
+			BannerID:   id,
+			UserID:     banner.UserID,
+			Validated:  isUserValidated,
+			Device:     banner.Device,
+			CategoryID: banner.CategoryID,
+			CreatedAt:  banner.CreatedAt,
+		})
+		if err != nil {
+			return fmt.Errorf("could not send event that banner is created: %w", err)
+		}
+
+		return nil
+	})
+
+	if err != nil {
+		return 0, fmt.Errorf("could not execute tx: %w", err)
+	}
+
+	// banner of validated advertiser is approved right away
+	if isUserValidated && banner.StartOnApproval {
+		if err := u.BannerStart(spanCtx, bannerID, banner.UserID); err != nil {
+			return bannerID, fmt.Errorf("could not start approved banner: %w", err)
+		}
+	}
+
+	return bannerID, nil
+}
+
+func validateImg(imgData []byte) error {
+	imgFormat := http.DetectContentType(imgData)
 	switch imgFormat {
 	case "image/png":
 	case "image/jpeg":
 	default:
-		return 0, fmt.Errorf("wrong image type: %s", imgFormat)
+		return fmt.Errorf("wrong image type: %s", imgFormat)
 	}
 
-	buff := bytes.NewBuffer(banner.ImgData)
+	buff := bytes.NewBuffer(imgData)
 	imgCfg, _, err := image.DecodeConfig(buff)
 	if err != nil {
-		return 0, fmt.Errorf("could not decome img: %w", err)
+		return fmt.Errorf("could not decome img: %w", err)
 	}
 
 	if _, ok := resolutions[resolution{w: imgCfg.Width, h: imgCfg.Height}]; !ok {
-		return 0, fmt.Errorf("wrong img format: w:%d, h:%d", imgCfg.Width, imgCfg.Height)
-	}
-
-	// TODO: add transaction here? SAGA seems better cuz we dont need to make user create banner once again and
-	//  with SAGA we can gurantee that eventually moderator will get banner for validation
-	bannerID, err := u.repo.CreateBanner(spanCtx, banner)
-	if err != nil {
-		return 0, fmt.Errorf("repo failed: %w", err)
+		return fmt.Errorf("wrong img format: w:%d, h:%d", imgCfg.Width, imgCfg.Height)
 	}
 
-	err = u.bannerEventer.BannerCreated(spanCtx, events.BannerCreated{
-		BannerID:   bannerID,
-		UserID:     banner.UserID,
-		Validated:  isUserValidated,
-		Device:     banner.Device,
-		CategoryID: banner.CategoryID,
-		CreatedAt:  banner.CreatedAt,
-	})
-	if err != nil {
-		return bannerID, fmt.Errorf("could not send event that banner is created: %w", err)
-	}
-
-	return bannerID, nil
+	return nil
 }
 
 func (u *User) GetBanner(ctx context.Context, bannerID int) (*entity.Banner, error) {
@@ -314,11 +537,77 @@ func (u *User) BannerUpdated(ctx context.Context, updated events.BannerUpdated)
 	defer span.End()
 
 	// TODO: shoud this method be in different usecases?
-	// TODO: if banner was active and now got rejected we need to send msg to stop it
 
-	if err := u.repo.BannerChangeStatus(spanCtx, updated.BannerID, updated.Valide, updated.Comment); err != nil {
-		return fmt.Errorf("repo failed: could not update status: %w", err)
+	// resolution of edited banner is about its pending revision, not the creative being served,
+	// unless it has been made before the revision
+	revision, err := u.repo.GetRevision(spanCtx, updated.BannerID)
+	if err == nil && (updated.ResolvedAt == 0 || updated.ResolvedAt >= revision.CreatedAt) {
+		return u.revisionResolved(spanCtx, revision, updated)
+	}
+
+	if err != nil && !errors.Is(err, entity.ErrNoRevision) {
+		return fmt.Errorf("repo failed: could not get revision: %w", err)
+	}
+
+	err = u.transactor.WithTransaction(spanCtx, func(txCtx context.Context) error {
+		bannerInfo, err := u.repo.GetBannerForUpdate(txCtx, updated.BannerID)
+		if err != nil {
+			return fmt.Errorf("could not get banner: %w", err)
+		}
+
+		// resolution is redelivered or reconciled after a later one
+		if updated.IsStale(bannerInfo.ModeratedAt) {
+			return nil
+		}
+
+		if err := u.repo.BannerChangeStatus(txCtx, updated.BannerID, updated.Approved, updated.Comment, updated.ResolvedAt); err != nil {
+			return fmt.Errorf("could not update status: %w", err)
+		}
+
+		bannerInfo.IsValidated = updated.Approved
+
+		// rejected banner must not be served anymore even if it has been started already
+		if !updated.Approved {
+			if !bannerInfo.CanBeStopped() {
+				return nil
+			}
+
+			return u.stopBanner(txCtx, bannerInfo)
+		}
+
+		return u.startApproved(txCtx, bannerInfo)
+	})
+
+	if err != nil {
+		return fmt.Errorf("could not execute tx: %w", err)
 	}
 
 	return nil
 }
+
+// startApproved starts the banner advertiser asked to start once it's approved, the banner stays queued
+// if there is not enough money on balance. Expected to be called within transaction
+func (u *User) startApproved(txCtx context.Context, bannerInfo *entity.Banner) error {
+	if !bannerInfo.StartOnApproval || !bannerInfo.CanBeStarted() {
+		return nil
+	}
+
+	if err := u.campaignWindow(txCtx, bannerInfo); err != nil {
+		return err
+	}
+
+	if bannerInfo.IsFinished(time.Now()) {
+		return nil
+	}
+
+	balance, err := u.billingRepo.GetBalance(txCtx, bannerInfo.UserID)
+	if err != nil {
+		return fmt.Errorf("could not get balance: %w", err)
+	}
+
+	if balance < bannerInfo.LimitBudget {
+		return nil
+	}
+
+	return u.startBanner(txCtx, bannerInfo)
+}
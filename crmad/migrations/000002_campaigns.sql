@@ -0,0 +1,20 @@
+CREATE TABLE `campaigns`
+(
+    `id`           int(11) NOT NULL AUTO_INCREMENT,
+    `user_id`      int(11) NOT NULL,
+    `name`         varchar(255) NOT NULL,
+    `total_budget` decimal(12, 2) NOT NULL,
+    `daily_budget` decimal(12, 2) NOT NULL,
+    `start_at`     int(11) NOT NULL,
+    `end_at`       int(11) NOT NULL,
+    `device`       varchar(16)  NOT NULL,
+    `category_id`  int(11) NOT NULL,
+    `is_active`    tinyint(1) NOT NULL,
+    `created_at`   int(11) NOT NULL,
+    PRIMARY KEY (`id`),
+    KEY `user_id` (`user_id`)
+) ENGINE=InnoDB;
+
+ALTER TABLE `banners`
+    ADD COLUMN `campaign_id` int(11) NOT NULL DEFAULT 0,
+    ADD KEY `campaign_id` (`campaign_id`);
@@ -0,0 +1,26 @@
+CREATE TABLE `invoices`
+(
+    `id`         int(11) NOT NULL AUTO_INCREMENT,
+    `user_id`    int(11) NOT NULL,
+    `amount`     decimal(14, 4) NOT NULL,
+    `key`        varchar(64) NOT NULL,
+    `is_paid`    tinyint(1) NOT NULL,
+    `created_at` int(11) NOT NULL,
+    PRIMARY KEY (`id`),
+    UNIQUE KEY `key` (`key`),
+    KEY `user_id` (`user_id`)
+) ENGINE=InnoDB;
+
+CREATE TABLE `ledger`
+(
+    `id`         int(11) NOT NULL AUTO_INCREMENT,
+    `txn_id`     varchar(64)  NOT NULL,
+    `account`    varchar(32)  NOT NULL,
+    `user_id`    int(11) NOT NULL,
+    `amount`     decimal(14, 4) NOT NULL,
+    `reference`  varchar(255) NOT NULL,
+    `created_at` int(11) NOT NULL,
+    PRIMARY KEY (`id`),
+    KEY `txn_id` (`txn_id`),
+    KEY `account_user` (`account`, `user_id`)
+) ENGINE=InnoDB;
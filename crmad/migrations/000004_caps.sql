@@ -0,0 +1,9 @@
+ALTER TABLE `banners`
+    ADD COLUMN `daily_shows`   int(11) NOT NULL DEFAULT 0,
+    ADD COLUMN `daily_clicks`  int(11) NOT NULL DEFAULT 0,
+    ADD COLUMN `daily_budget`  decimal(12, 2) NOT NULL DEFAULT 0,
+    ADD COLUMN `hourly_shows`  int(11) NOT NULL DEFAULT 0,
+    ADD COLUMN `hourly_clicks` int(11) NOT NULL DEFAULT 0,
+    ADD COLUMN `hourly_budget` decimal(12, 2) NOT NULL DEFAULT 0,
+    ADD COLUMN `paused_until`  int(11) NOT NULL DEFAULT 0,
+    ADD COLUMN `pause_reason`  varchar(32) NOT NULL DEFAULT '';
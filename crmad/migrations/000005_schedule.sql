@@ -0,0 +1,8 @@
+ALTER TABLE `banners`
+    ADD COLUMN `start_at` int(11) NOT NULL DEFAULT 0,
+    ADD COLUMN `end_at`   int(11) NOT NULL DEFAULT 0,
+    ADD COLUMN `schedule` varchar(1024) NOT NULL DEFAULT '',
+    ADD COLUMN `timezone` varchar(64) NOT NULL DEFAULT 'UTC',
+    ADD COLUMN `is_live`  tinyint(1) NOT NULL DEFAULT 0;
+
+UPDATE `banners` SET `is_live` = `is_active`;
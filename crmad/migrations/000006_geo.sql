@@ -0,0 +1,2 @@
+ALTER TABLE `banners`
+    ADD COLUMN `geo` varchar(1024) NOT NULL DEFAULT '';
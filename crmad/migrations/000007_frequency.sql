@@ -0,0 +1,3 @@
+ALTER TABLE `banners`
+    ADD COLUMN `frequency_cap`    int(11) NOT NULL DEFAULT 0,
+    ADD COLUMN `frequency_period` int(11) NOT NULL DEFAULT 0;
@@ -0,0 +1,3 @@
+ALTER TABLE `banners`
+    ADD COLUMN `bid_type` varchar(3) NOT NULL DEFAULT 'cpc',
+    ADD COLUMN `bid`      decimal(12, 2) NOT NULL DEFAULT 1;
@@ -0,0 +1,4 @@
+ALTER TABLE `campaigns`
+    ADD COLUMN `utm_source`   varchar(255) NOT NULL DEFAULT '',
+    ADD COLUMN `utm_medium`   varchar(255) NOT NULL DEFAULT '',
+    ADD COLUMN `utm_campaign` varchar(255) NOT NULL DEFAULT '';
@@ -0,0 +1,11 @@
+CREATE TABLE `banner_revisions`
+(
+    `id`          int(11) NOT NULL AUTO_INCREMENT,
+    `banner_id`   int(11) NOT NULL,
+    `img_data`    blob         NOT NULL,
+    `banner_text` varchar(255) NOT NULL,
+    `banner_url`  varchar(255) NOT NULL,
+    `created_at`  int(11) NOT NULL,
+    PRIMARY KEY (`id`),
+    UNIQUE KEY `banner_id` (`banner_id`)
+) ENGINE=InnoDB;
@@ -0,0 +1,2 @@
+ALTER TABLE `banners`
+    ADD COLUMN `start_on_approval` tinyint(1) NOT NULL DEFAULT 0;
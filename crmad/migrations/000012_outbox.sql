@@ -0,0 +1,14 @@
+CREATE TABLE `outbox`
+(
+    `id`         bigint(20) NOT NULL AUTO_INCREMENT,
+    `topic`      varchar(255) NOT NULL,
+    `msg_key`    varbinary(255) NULL,
+    `payload`    mediumblob   NOT NULL,
+    `headers`    blob         NOT NULL,
+    `created_at` int(11) NOT NULL,
+    `sent_at`    int(11) NOT NULL DEFAULT 0,
+    `attempts`   int(11) NOT NULL DEFAULT 0,
+    `last_error` varchar(1024) NOT NULL DEFAULT '',
+    PRIMARY KEY (`id`),
+    KEY `sent_at` (`sent_at`, `id`)
+) ENGINE=InnoDB;
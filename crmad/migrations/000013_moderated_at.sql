@@ -0,0 +1,2 @@
+ALTER TABLE `banners`
+    ADD COLUMN `moderated_at` int(11) NOT NULL DEFAULT 0;
@@ -0,0 +1,3 @@
+-- a reference is written once per account of an user, so redelivered clicks and callbacks are not charged twice
+ALTER TABLE `ledger`
+    ADD UNIQUE KEY `account_user_reference` (`account`, `user_id`, `reference`);
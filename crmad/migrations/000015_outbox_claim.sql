@@ -0,0 +1,3 @@
+-- relay claims messages for a while instead of locking them while they are sent to kafka
+ALTER TABLE `outbox`
+    ADD COLUMN `claimed_until` int(11) NOT NULL DEFAULT 0;
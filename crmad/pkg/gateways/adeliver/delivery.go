@@ -2,26 +2,27 @@ package adeliver
 
 import (
 	"context"
-	"encoding/json"
 	"fmt"
 
-	"github.com/Shopify/sarama"
+	"github.com/crxfoz/teaserad/adeliver/pkg/kafka"
 	"github.com/crxfoz/teaserad/crmad/internal/domain/events"
-	"go.opentelemetry.io/contrib/instrumentation/github.com/Shopify/sarama/otelsarama"
 	"go.opentelemetry.io/otel"
 )
 
 const (
-	topicBanenrStart = "adeliver.banner.start"
-	topicBannerStop  = "adeliver.banner.stop"
+	topicBanenrStart    = "adeliver.banner.start"
+	topicBannerStop     = "adeliver.banner.stop"
+	topicBannerLimit    = "adeliver.banner.update"
+	topicBannerCreative = "adeliver.banner.creative"
+	topicCampaignLimits = "adeliver.campaign.limits"
 )
 
 type Producer struct {
-	conn sarama.SyncProducer
+	producer *kafka.Producer
 }
 
-func New(conn sarama.SyncProducer) *Producer {
-	return &Producer{conn: conn}
+func New(outbox kafka.Outbox) *Producer {
+	return &Producer{producer: kafka.NewOutboxProducer(outbox, "crmad")}
 }
 
 const (
@@ -32,47 +33,55 @@ func (b *Producer) BannerStart(ctx context.Context, msg events.BannerStart) erro
 	newCtx, span := otel.Tracer(tracerName).Start(ctx, "BannerStart")
 	defer span.End()
 
-	out, err := json.Marshal(msg)
-	if err != nil {
-		return fmt.Errorf("could not marshal msg: %w", err)
+	if err := b.producer.Publish(newCtx, topicBanenrStart, msg); err != nil {
+		return fmt.Errorf("could not publish event: %w", err)
 	}
 
-	pitem := &sarama.ProducerMessage{
-		Topic: topicBanenrStart,
-		Key:   sarama.StringEncoder("1"), // TODO: use different keys
-		Value: sarama.ByteEncoder(out),
-	}
+	return nil
+}
 
-	otel.GetTextMapPropagator().Inject(newCtx, otelsarama.NewProducerMessageCarrier(pitem))
+func (b *Producer) BannerStop(ctx context.Context, msg events.BannerStop) error {
+	newCtx, span := otel.Tracer(tracerName).Start(ctx, "BannerStart")
+	defer span.End()
 
-	_, _, err = b.conn.SendMessage(pitem)
-	if err != nil {
-		return fmt.Errorf("could not send message: %w", err)
+	if err := b.producer.Publish(newCtx, topicBannerStop, msg); err != nil {
+		return fmt.Errorf("could not publish event: %w", err)
 	}
 
 	return nil
 }
 
-func (b *Producer) BannerStop(ctx context.Context, msg events.BannerStop) error {
-	newCtx, span := otel.Tracer(tracerName).Start(ctx, "BannerStart")
+// BannerLimits sends limits of the live banner changed by advertiser
+func (b *Producer) BannerLimits(ctx context.Context, msg events.BannerLimits) error {
+	newCtx, span := otel.Tracer(tracerName).Start(ctx, "BannerLimits")
 	defer span.End()
 
-	out, err := json.Marshal(msg)
-	if err != nil {
-		return fmt.Errorf("could not marshal msg: %w", err)
+	if err := b.producer.Publish(newCtx, topicBannerLimit, msg); err != nil {
+		return fmt.Errorf("could not publish event: %w", err)
 	}
 
-	pitem := &sarama.ProducerMessage{
-		Topic: topicBannerStop,
-		Key:   sarama.StringEncoder("1"), // TODO: use different keys
-		Value: sarama.ByteEncoder(out),
+	return nil
+}
+
+// BannerCreative sends creative of the live banner approved by moderator
+func (b *Producer) BannerCreative(ctx context.Context, msg events.BannerCreative) error {
+	newCtx, span := otel.Tracer(tracerName).Start(ctx, "BannerCreative")
+	defer span.End()
+
+	if err := b.producer.Publish(newCtx, topicBannerCreative, msg); err != nil {
+		return fmt.Errorf("could not publish event: %w", err)
 	}
 
-	otel.GetTextMapPropagator().Inject(newCtx, otelsarama.NewProducerMessageCarrier(pitem))
+	return nil
+}
+
+// CampaignLimits sends budgets of the campaign changed by advertiser, they are shared by its live banners
+func (b *Producer) CampaignLimits(ctx context.Context, msg events.CampaignLimits) error {
+	newCtx, span := otel.Tracer(tracerName).Start(ctx, "CampaignLimits")
+	defer span.End()
 
-	_, _, err = b.conn.SendMessage(pitem)
-	if err != nil {
-		return fmt.Errorf("could not send message: %w", err)
+	if err := b.producer.Publish(newCtx, topicCampaignLimits, msg); err != nil {
+		return fmt.Errorf("could not publish event: %w", err)
 	}
 
 	return nil
@@ -2,25 +2,24 @@ package crmadm
 
 import (
 	"context"
-	"encoding/json"
 	"fmt"
 
-	"github.com/Shopify/sarama"
+	"github.com/crxfoz/teaserad/adeliver/pkg/kafka"
 	"github.com/crxfoz/teaserad/crmad/internal/domain/events"
-	"go.opentelemetry.io/contrib/instrumentation/github.com/Shopify/sarama/otelsarama"
 	"go.opentelemetry.io/otel"
 )
 
 const (
-	topicName = "crmadm.banner.created"
+	topicName        = "crmadm.banner.created"
+	topicNameRevised = "crmadm.banner.revised"
 )
 
 type Banner struct {
-	conn sarama.SyncProducer
+	producer *kafka.Producer
 }
 
-func New(conn sarama.SyncProducer) *Banner {
-	return &Banner{conn: conn}
+func New(outbox kafka.Outbox) *Banner {
+	return &Banner{producer: kafka.NewOutboxProducer(outbox, "crmad")}
 }
 
 const (
@@ -31,22 +30,19 @@ func (b *Banner) BannerCreated(ctx context.Context, msg events.BannerCreated) er
 	newCtx, span := otel.Tracer(tracerName).Start(ctx, "BannerCreated")
 	defer span.End()
 
-	out, err := json.Marshal(msg)
-	if err != nil {
-		return fmt.Errorf("could not marshal msg: %w", err)
+	if err := b.producer.Publish(newCtx, topicName, msg); err != nil {
+		return fmt.Errorf("could not publish event: %w", err)
 	}
 
-	pitem := &sarama.ProducerMessage{
-		Topic: topicName,
-		Key:   sarama.StringEncoder("1"), // TODO: use different keys
-		Value: sarama.ByteEncoder(out),
-	}
+	return nil
+}
 
-	otel.GetTextMapPropagator().Inject(newCtx, otelsarama.NewProducerMessageCarrier(pitem))
+func (b *Banner) BannerRevised(ctx context.Context, msg events.BannerRevised) error {
+	newCtx, span := otel.Tracer(tracerName).Start(ctx, "BannerRevised")
+	defer span.End()
 
-	_, _, err = b.conn.SendMessage(pitem)
-	if err != nil {
-		return fmt.Errorf("could not send message: %w", err)
+	if err := b.producer.Publish(newCtx, topicNameRevised, msg); err != nil {
+		return fmt.Errorf("could not publish event: %w", err)
 	}
 
 	return nil
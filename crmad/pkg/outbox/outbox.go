@@ -0,0 +1,115 @@
+package outbox
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/Shopify/sarama"
+	"go.opentelemetry.io/otel"
+)
+
+// Message is a kafka message stored in the same transaction as the changes it's about
+type Message struct {
+	ID        int    `db:"id"`
+	Topic     string `db:"topic"`
+	Key       []byte `db:"msg_key"`
+	Payload   []byte `db:"payload"`
+	Headers   []byte `db:"headers"`
+	CreatedAt int64  `db:"created_at"`
+	Attempts  int    `db:"attempts"`
+	// ClaimedUntil is when the relay sending the message gives it up
+	ClaimedUntil int64 `db:"claimed_until"`
+}
+
+type Repo interface {
+	WithTransaction(ctx context.Context, fn func(ctx context.Context) error) error
+	AddOutbox(ctx context.Context, msg *Message) error
+	// GetOutbox returns the oldest messages which have not been sent yet and locks them until the end of transaction
+	GetOutbox(ctx context.Context, limit int) ([]*Message, error)
+	// ClaimOutbox and ReleaseOutbox take messages for the relay sending them and give them up
+	ClaimOutbox(ctx context.Context, msgIDs []int, until int64) error
+	ReleaseOutbox(ctx context.Context, msgIDs []int) error
+	OutboxSent(ctx context.Context, msgID int, sentAt int64) error
+	OutboxFailed(ctx context.Context, msgID int, reason string) error
+}
+
+// Outbox keeps messages in DB within the transaction of the context, so they are sent by Relay
+// only if the transaction is committed
+type Outbox struct {
+	repo Repo
+}
+
+func New(repo Repo) *Outbox {
+	return &Outbox{repo: repo}
+}
+
+const (
+	tracerName = "outbox"
+)
+
+func (o *Outbox) Add(ctx context.Context, msg *sarama.ProducerMessage) error {
+	spanCtx, span := otel.Tracer(tracerName).Start(ctx, "Add")
+	defer span.End()
+
+	item := &Message{
+		Topic:     msg.Topic,
+		CreatedAt: time.Now().UTC().Unix(),
+	}
+
+	var err error
+
+	if msg.Key != nil {
+		if item.Key, err = msg.Key.Encode(); err != nil {
+			return fmt.Errorf("could not encode key: %w", err)
+		}
+	}
+
+	if msg.Value != nil {
+		if item.Payload, err = msg.Value.Encode(); err != nil {
+			return fmt.Errorf("could not encode value: %w", err)
+		}
+	}
+
+	// headers carry trace context of the producer
+	headers := make(map[string]string, len(msg.Headers))
+	for _, header := range msg.Headers {
+		headers[string(header.Key)] = string(header.Value)
+	}
+
+	if item.Headers, err = json.Marshal(headers); err != nil {
+		return fmt.Errorf("could not marshal headers: %w", err)
+	}
+
+	if err := o.repo.AddOutbox(spanCtx, item); err != nil {
+		return fmt.Errorf("repo failed: %w", err)
+	}
+
+	return nil
+}
+
+// ProducerMessage restores the message the way it has been added
+func (m *Message) ProducerMessage() (*sarama.ProducerMessage, error) {
+	msg := &sarama.ProducerMessage{
+		Topic: m.Topic,
+		Value: sarama.ByteEncoder(m.Payload),
+	}
+
+	if m.Key != nil {
+		msg.Key = sarama.ByteEncoder(m.Key)
+	}
+
+	var headers map[string]string
+	if len(m.Headers) != 0 {
+		if err := json.Unmarshal(m.Headers, &headers); err != nil {
+			return nil, fmt.Errorf("could not unmarshal headers: %w", err)
+		}
+	}
+
+	for key, value := range headers {
+		msg.Headers = append(msg.Headers, sarama.RecordHeader{Key: []byte(key), Value: []byte(value)})
+	}
+
+	return msg, nil
+}
@@ -0,0 +1,170 @@
+package outbox
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/Shopify/sarama"
+	"github.com/crxfoz/teaserad/crmad/internal/domain"
+	"go.opentelemetry.io/otel"
+)
+
+const (
+	// relayBatch is how many messages are claimed at once
+	relayBatch = 100
+	// relayClaim is how long claimed messages are not taken by another relay, they are sent again if it expires
+	relayClaim = time.Minute
+	// relayMaxBackoff bounds the delay between retries while kafka is unavailable
+	relayMaxBackoff = time.Minute
+)
+
+// Relay sends stored messages to kafka in the order they have been added and marks them as sent,
+// message is sent again if relay fails before marking it, so delivery is at least once
+type Relay struct {
+	repo     Repo
+	producer sarama.SyncProducer
+	interval time.Duration
+	logger   domain.Logger
+	done     chan struct{}
+}
+
+func NewRelay(repo Repo, producer sarama.SyncProducer, interval time.Duration, logger domain.Logger) *Relay {
+	return &Relay{repo: repo, producer: producer, interval: interval, logger: logger, done: make(chan struct{})}
+}
+
+// Run blocks until Stop is called
+func (r *Relay) Run() {
+	delay := r.interval
+
+	for {
+		select {
+		case <-time.After(delay):
+		case <-r.done:
+			return
+		}
+
+		sent, err := r.Flush(context.Background())
+
+		switch {
+		case err != nil:
+			r.logger.Errorw("could not relay outbox", "err", err, "sent", sent)
+			if delay < r.interval {
+				delay = r.interval
+			}
+
+			delay *= 2
+			if delay > relayMaxBackoff {
+				delay = relayMaxBackoff
+			}
+		case sent == relayBatch:
+			// there might be more messages waiting
+			delay = 0
+		default:
+			delay = r.interval
+		}
+	}
+}
+
+func (r *Relay) Stop() {
+	close(r.done)
+}
+
+// Flush sends a batch of pending messages and reports how many of them have been sent.
+// Messages are sent outside of any transaction, so business transactions adding new ones are not blocked by kafka.
+// It stops on the first failure so messages are never sent out of order
+func (r *Relay) Flush(ctx context.Context) (int, error) {
+	spanCtx, span := otel.Tracer(tracerName).Start(ctx, "Flush")
+	defer span.End()
+
+	messages, err := r.claim(spanCtx)
+	if err != nil {
+		return 0, err
+	}
+
+	var sent int
+
+	for i, item := range messages {
+		if sendErr := r.send(item); sendErr != nil {
+			if err := r.repo.OutboxFailed(spanCtx, item.ID, sendErr.Error()); err != nil {
+				return sent, fmt.Errorf("could not mark message %d as failed: %w", item.ID, err)
+			}
+
+			// the failed message and the ones after it are sent by the next flush
+			if err := r.repo.ReleaseOutbox(spanCtx, messageIDs(messages[i:])); err != nil {
+				return sent, fmt.Errorf("could not release messages: %w", err)
+			}
+
+			return sent, fmt.Errorf("could not send message: %w", sendErr)
+		}
+
+		// message not marked is sent again once its claim expires
+		if err := r.repo.OutboxSent(spanCtx, item.ID, time.Now().UTC().Unix()); err != nil {
+			return sent, fmt.Errorf("could not mark message %d as sent: %w", item.ID, err)
+		}
+
+		sent++
+	}
+
+	return sent, nil
+}
+
+// claim takes the oldest pending messages for relayClaim within a short transaction.
+// Nothing is claimed while the oldest messages are claimed by another relay, so they are sent in order
+func (r *Relay) claim(ctx context.Context) ([]*Message, error) {
+	var out []*Message
+
+	err := r.repo.WithTransaction(ctx, func(txCtx context.Context) error {
+		messages, err := r.repo.GetOutbox(txCtx, relayBatch)
+		if err != nil {
+			return fmt.Errorf("could not get outbox: %w", err)
+		}
+
+		now := time.Now().UTC().Unix()
+		for _, item := range messages {
+			if item.ClaimedUntil > now {
+				return nil
+			}
+		}
+
+		if len(messages) == 0 {
+			return nil
+		}
+
+		if err := r.repo.ClaimOutbox(txCtx, messageIDs(messages), now+int64(relayClaim/time.Second)); err != nil {
+			return fmt.Errorf("could not claim outbox: %w", err)
+		}
+
+		out = messages
+
+		return nil
+	})
+
+	if err != nil {
+		return nil, fmt.Errorf("could not execute tx: %w", err)
+	}
+
+	return out, nil
+}
+
+func messageIDs(messages []*Message) []int {
+	out := make([]int, 0, len(messages))
+	for _, item := range messages {
+		out = append(out, item.ID)
+	}
+
+	return out
+}
+
+func (r *Relay) send(item *Message) error {
+	msg, err := item.ProducerMessage()
+	if err != nil {
+		return err
+	}
+
+	if _, _, err := r.producer.SendMessage(msg); err != nil {
+		return err
+	}
+
+	return nil
+}
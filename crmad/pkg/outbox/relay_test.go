@@ -0,0 +1,198 @@
+package outbox
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Shopify/sarama"
+	"github.com/Shopify/sarama/mocks"
+	"github.com/stretchr/testify/assert"
+)
+
+// memRepo serializes transactions the way locks of the outbox table do
+type memRepo struct {
+	tx       sync.Mutex
+	messages []*Message
+	sent     map[int]bool
+	failed   map[int]string
+}
+
+func newMemRepo() *memRepo {
+	return &memRepo{sent: map[int]bool{}, failed: map[int]string{}}
+}
+
+func (m *memRepo) WithTransaction(ctx context.Context, fn func(ctx context.Context) error) error {
+	m.tx.Lock()
+	defer m.tx.Unlock()
+
+	return fn(ctx)
+}
+
+func (m *memRepo) AddOutbox(_ context.Context, msg *Message) error {
+	msg.ID = len(m.messages) + 1
+	m.messages = append(m.messages, msg)
+	return nil
+}
+
+func (m *memRepo) GetOutbox(_ context.Context, limit int) ([]*Message, error) {
+	var out []*Message
+	for _, msg := range m.messages {
+		if !m.sent[msg.ID] && len(out) < limit {
+			out = append(out, msg)
+		}
+	}
+
+	return out, nil
+}
+
+func (m *memRepo) ClaimOutbox(_ context.Context, msgIDs []int, until int64) error {
+	for _, msgID := range msgIDs {
+		m.messages[msgID-1].ClaimedUntil = until
+	}
+
+	return nil
+}
+
+func (m *memRepo) ReleaseOutbox(_ context.Context, msgIDs []int) error {
+	for _, msgID := range msgIDs {
+		m.messages[msgID-1].ClaimedUntil = 0
+	}
+
+	return nil
+}
+
+func (m *memRepo) OutboxSent(_ context.Context, msgID int, _ int64) error {
+	m.sent[msgID] = true
+	return nil
+}
+
+func (m *memRepo) OutboxFailed(_ context.Context, msgID int, reason string) error {
+	m.failed[msgID] = reason
+	return nil
+}
+
+func TestRelay_Flush(t *testing.T) {
+	repo := newMemRepo()
+	box := New(repo)
+
+	for _, value := range []string{"first", "second", "third"} {
+		err := box.Add(context.Background(), &sarama.ProducerMessage{
+			Topic:   "topic",
+			Key:     sarama.StringEncoder("1"),
+			Value:   sarama.StringEncoder(value),
+			Headers: []sarama.RecordHeader{{Key: []byte("traceparent"), Value: []byte("00-1-2-01")}},
+		})
+		assert.NoError(t, err)
+	}
+
+	producer := mocks.NewSyncProducer(t, nil)
+	producer.ExpectSendMessageWithMessageCheckerFunctionAndSucceed(func(msg *sarama.ProducerMessage) error {
+		value, _ := msg.Value.Encode()
+		if string(value) != "first" {
+			return errors.New("wrong order")
+		}
+
+		if len(msg.Headers) != 1 || string(msg.Headers[0].Value) != "00-1-2-01" {
+			return errors.New("headers are lost")
+		}
+
+		return nil
+	})
+	producer.ExpectSendMessageAndFail(sarama.ErrOutOfBrokers)
+
+	relay := NewRelay(repo, producer, 0, nil)
+
+	sent, err := relay.Flush(context.Background())
+	assert.Error(t, err)
+	assert.Equal(t, 1, sent)
+	assert.True(t, repo.sent[1])
+	assert.False(t, repo.sent[2])
+	assert.Contains(t, repo.failed[2], sarama.ErrOutOfBrokers.Error())
+
+	// the third message waits for the second one to keep the order
+	assert.False(t, repo.sent[3])
+	assert.NoError(t, producer.Close())
+
+	producer = mocks.NewSyncProducer(t, nil)
+	producer.ExpectSendMessageAndSucceed()
+	producer.ExpectSendMessageAndSucceed()
+
+	relay = NewRelay(repo, producer, 0, nil)
+
+	sent, err = relay.Flush(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, 2, sent)
+	assert.True(t, repo.sent[3])
+	assert.NoError(t, producer.Close())
+}
+
+func TestRelay_FlushDoesNotBlockInserts(t *testing.T) {
+	repo := newMemRepo()
+	box := New(repo)
+
+	add := func(value string) error {
+		return repo.WithTransaction(context.Background(), func(txCtx context.Context) error {
+			return box.Add(txCtx, &sarama.ProducerMessage{Topic: "topic", Value: sarama.StringEncoder(value)})
+		})
+	}
+
+	assert.NoError(t, add("first"))
+
+	producer := mocks.NewSyncProducer(t, nil)
+	producer.ExpectSendMessageWithMessageCheckerFunctionAndSucceed(func(*sarama.ProducerMessage) error {
+		// business transaction commits while kafka is sending the message
+		added := make(chan error, 1)
+		go func() { added <- add("second") }()
+
+		select {
+		case err := <-added:
+			return err
+		case <-time.After(time.Second):
+			return errors.New("insert is blocked by relay")
+		}
+	})
+
+	relay := NewRelay(repo, producer, 0, nil)
+
+	sent, err := relay.Flush(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, 1, sent)
+	assert.Len(t, repo.messages, 2)
+	assert.NoError(t, producer.Close())
+}
+
+func TestRelay_FlushSkipsClaimed(t *testing.T) {
+	repo := newMemRepo()
+	box := New(repo)
+
+	for _, value := range []string{"first", "second"} {
+		assert.NoError(t, box.Add(context.Background(), &sarama.ProducerMessage{Topic: "topic", Value: sarama.StringEncoder(value)}))
+	}
+
+	// another relay is sending the oldest message
+	assert.NoError(t, repo.ClaimOutbox(context.Background(), []int{1}, time.Now().Add(time.Minute).Unix()))
+
+	producer := mocks.NewSyncProducer(t, nil)
+	relay := NewRelay(repo, producer, 0, nil)
+
+	sent, err := relay.Flush(context.Background())
+	assert.NoError(t, err)
+	assert.Zero(t, sent)
+	assert.NoError(t, producer.Close())
+
+	// claim of the relay which has died expires
+	assert.NoError(t, repo.ClaimOutbox(context.Background(), []int{1}, time.Now().Add(-time.Second).Unix()))
+
+	producer = mocks.NewSyncProducer(t, nil)
+	producer.ExpectSendMessageAndSucceed()
+	producer.ExpectSendMessageAndSucceed()
+	relay = NewRelay(repo, producer, 0, nil)
+
+	sent, err = relay.Flush(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, 2, sent)
+	assert.NoError(t, producer.Close())
+}
@@ -11,6 +11,7 @@ import (
 	"github.com/Shopify/sarama"
 	"github.com/crxfoz/teaserad/adeliver/pkg/kafka"
 	"github.com/crxfoz/teaserad/crmad/pkg/auth/middleware"
+	"github.com/crxfoz/teaserad/crmad/pkg/outbox"
 	"github.com/crxfoz/teaserad/crmad/pkg/tracer"
 	httpController "github.com/crxfoz/teaserad/crmadm/internal/delivery/http"
 	kafkaController "github.com/crxfoz/teaserad/crmadm/internal/delivery/kafka"
@@ -57,8 +58,6 @@ func main() {
 			"err", err)
 	}
 
-	crmadGateway := crmad.New(kafkaProducer)
-
 	sqlConn, err := sqlx.Connect("mysql",
 		fmt.Sprintf("%s:%s@(%s:%s)/%s",
 			"root",
@@ -73,6 +72,13 @@ func main() {
 
 	authManager := jwt.NewJWTManager("123", time.Hour*24*7) // TODO: add token to envs
 	userRepo := mysql.NewRepo(sqlConn)
+
+	// resolutions are sent to crmad through the outbox stored along with them
+	crmadGateway := crmad.New(outbox.New(userRepo))
+
+	outboxRelay := outbox.NewRelay(userRepo, kafkaProducer, time.Second, logger.Named("crmadm-outbox-relay"))
+	go outboxRelay.Run()
+
 	userSvc := user.New(authManager, userRepo, userRepo, crmadGateway)
 	authMiddleware := middleware.New[entity.User, entity.UserContext](authManager)
 
@@ -86,10 +92,11 @@ func main() {
 	}
 
 	kfController := kafkaController.New(userSvc)
-	kafBuilder := kafka.New(logger)
+	kafBuilder := kafka.New(logger, kafkaProducer)
 
 	kafkaSess, err := kafBuilder.NewConsumer("crmadm-delivery-kafka", kafkaConsumer, func(session *kafka.Session) error {
 		session.AddRoute("crmadm.banner.created", kfController.OnNewBanner)
+		session.AddRoute("crmadm.banner.revised", kfController.OnBannerRevised)
 		return nil
 	})
 
@@ -118,6 +125,17 @@ func main() {
 		cmdLogger.Errorw("could not stop http-server", "err", err)
 	}
 
+	// consumer dead-letters messages through the producer, so it's stopped first
+	if err := kafkaSess.Stop(); err != nil {
+		cmdLogger.Errorw("could not stop consumer gracefuly", "err", err)
+	}
+
+	outboxRelay.Stop()
+
+	if err := kafkaProducer.Close(); err != nil {
+		cmdLogger.Errorw("could not stop producer gracefuly", "err", err)
+	}
+
 	if err := tp.Shutdown(context.Background()); err != nil {
 		cmdLogger.Errorw("could not stop tracing gracefuly", "err", err)
 	}
@@ -0,0 +1,68 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"os"
+
+	"github.com/crxfoz/teaserad/crmad/pkg/outbox"
+	"github.com/crxfoz/teaserad/crmadm/internal/repo/mysql"
+	"github.com/crxfoz/teaserad/crmadm/internal/services/user"
+	"github.com/crxfoz/teaserad/crmadm/pkg/gateways/crmad"
+	_ "github.com/go-sql-driver/mysql"
+	"github.com/jmoiron/sqlx"
+	"go.uber.org/zap"
+)
+
+// reconcile finds resolutions which have not been applied by crmad and puts them to the outbox once again,
+// they are sent by the relay of running crmadm.
+//
+// It's a tool for operators only: it reads banners of crmad straight from its database, so it's run with
+// a read-only user of crmad and never by services, e.g.
+//
+//	reconcile -crmadm-dsn 'root:pass@(db-master:3306)/crmadm' -crmad-dsn 'reader:pass@(db-master:3306)/crmad'
+//
+// DSNs are also taken from CRMADM_DSN and CRMAD_DSN envs
+func main() {
+	admDSN := flag.String("crmadm-dsn", os.Getenv("CRMADM_DSN"), "DSN of crmadm database, resolutions are read and the outbox is written")
+	crmadDSN := flag.String("crmad-dsn", os.Getenv("CRMAD_DSN"), "DSN of crmad database, banners are only read")
+	flag.Parse()
+
+	z, err := zap.NewDevelopment()
+	if err != nil {
+		panic(err)
+	}
+
+	defer z.Sync()
+
+	cmdLogger := z.Sugar().Named("reconcile")
+
+	if *admDSN == "" || *crmadDSN == "" {
+		cmdLogger.Errorw("both DSNs are required")
+		return
+	}
+
+	admConn, err := sqlx.Connect("mysql", *admDSN)
+	if err != nil {
+		cmdLogger.Errorw("could not connect to DB", "err", err, "db", "crmadm")
+		return
+	}
+
+	crmadConn, err := sqlx.Connect("mysql", *crmadDSN)
+	if err != nil {
+		cmdLogger.Errorw("could not connect to DB", "err", err, "db", "crmad")
+		return
+	}
+
+	userRepo := mysql.NewRepo(admConn)
+	// auth is not used to reconcile
+	userSvc := user.New(nil, userRepo, userRepo, crmad.New(outbox.New(userRepo)))
+
+	sent, err := userSvc.Reconcile(context.Background(), crmad.NewState(crmadConn))
+	if err != nil {
+		cmdLogger.Errorw("could not reconcile resolutions", "err", err, "sent", sent)
+		return
+	}
+
+	cmdLogger.Infow("resolutions reconciled", "sent", sent)
+}
@@ -2,16 +2,17 @@ package kafka
 
 import (
 	"context"
-	"encoding/json"
 	"fmt"
 
 	"github.com/Shopify/sarama"
+	kafkapkg "github.com/crxfoz/teaserad/adeliver/pkg/kafka"
 	"github.com/crxfoz/teaserad/crmadm/internal/domain/events"
 	"go.opentelemetry.io/otel"
 )
 
 type BannerService interface {
 	NewBanner(ctx context.Context, banner events.BannerCreated) error
+	BannerRevised(ctx context.Context, banner events.BannerRevised) error
 }
 
 type BannerConsumer struct {
@@ -27,9 +28,21 @@ func (bc *BannerConsumer) OnNewBanner(ctx context.Context, msg *sarama.ConsumerM
 	defer span.End()
 
 	var created events.BannerCreated
-	if err := json.Unmarshal(msg.Value, &created); err != nil {
+	if err := kafkapkg.Decode(msg, &created); err != nil {
 		return fmt.Errorf("could not parse message: %w", err)
 	}
 
 	return bc.bannerSvc.NewBanner(newCtx, created)
 }
+
+func (bc *BannerConsumer) OnBannerRevised(ctx context.Context, msg *sarama.ConsumerMessage) error {
+	newCtx, span := otel.Tracer("kafka-consumer").Start(ctx, "OnBannerRevised")
+	defer span.End()
+
+	var revised events.BannerRevised
+	if err := kafkapkg.Decode(msg, &revised); err != nil {
+		return fmt.Errorf("could not parse message: %w", err)
+	}
+
+	return bc.bannerSvc.BannerRevised(newCtx, revised)
+}
@@ -1,17 +1,10 @@
 package events
 
-type BannerCreated struct {
-	BannerID  int  `json:"banner_id"`
-	UserID    int  `json:"user_id"`
-	Validated bool `json:"validated"`
-}
+import contracts "github.com/crxfoz/teaserad/contracts/events"
 
-func (c *BannerCreated) ShouldBeAdded() bool {
-	return !c.Validated
-}
-
-type BannerUpdated struct {
-	BannerID int    `json:"banner_id"`
-	Valide   bool   `json:"valide"`
-	Comment  string `json:"comment"`
-}
+// events are declared by the shared contracts, so every service agrees on their schemas
+type (
+	BannerCreated = contracts.BannerCreated
+	BannerRevised = contracts.BannerRevised
+	BannerUpdated = contracts.BannerUpdated
+)
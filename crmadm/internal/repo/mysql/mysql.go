@@ -119,7 +119,7 @@ func (r *UserRepo) GetNewBanners(ctx context.Context, limit int, offset int) ([]
 	var banners []*entity.Banner
 	err := conn.SelectContext(ctx, &banners, `SELECT b.banner_id,b.user_id,b.created_at 
 		FROM banner b WHERE NOT EXISTS(
-			SELECT r.banner_id FROM resolution r WHERE b.banner_id=r.banner_id)
+			SELECT r.banner_id FROM resolution r WHERE b.banner_id=r.banner_id AND (r.created_at>=b.created_at OR r.created_at=0))
 		ORDER BY b.created_at ASC LIMIT ? OFFSET ?`, limit, offset)
 	if err != nil {
 		return nil, fmt.Errorf("could not select: %w", err)
@@ -152,3 +152,46 @@ func (r *UserRepo) NewBanner(ctx context.Context, banner *entity.Banner) error {
 
 	return nil
 }
+
+// ResubmitBanner adds the banner once again, resolutions made before are kept as history
+func (r *UserRepo) ResubmitBanner(ctx context.Context, banner *entity.Banner) error {
+	newCtx, span := otel.Tracer("db").Start(ctx, "ResubmitBanner")
+	defer span.End()
+
+	conn := r.executor(newCtx)
+
+	// resolutions stored without time are older than the banner and must not count anymore
+	_, err := conn.ExecContext(newCtx, `UPDATE resolution r JOIN banner b ON b.banner_id=r.banner_id
+		SET r.created_at=b.created_at WHERE r.banner_id=? AND r.created_at=0`, banner.BannerID)
+	if err != nil {
+		return fmt.Errorf("could not update resolutions: %w", err)
+	}
+
+	_, err = conn.ExecContext(newCtx, `INSERT INTO banner (banner_id, user_id, created_at) VALUES (?,?,?)
+		ON DUPLICATE KEY UPDATE created_at=VALUES(created_at)`, banner.BannerID, banner.UserID, banner.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("could not resubmit banner: %w", err)
+	}
+
+	return nil
+}
+
+// GetLatestResolutions returns the last resolution of each banner ordered by banner, starting after the given one.
+// Resolutions stored without time are skipped since it's unknown whether they are the last ones
+func (r *UserRepo) GetLatestResolutions(ctx context.Context, afterBannerID int, limit int) ([]*entity.Resolution, error) {
+	newCtx, span := otel.Tracer("db").Start(ctx, "GetLatestResolutions")
+	defer span.End()
+
+	conn := r.executor(newCtx)
+
+	var resolutions []*entity.Resolution
+	err := conn.SelectContext(newCtx, &resolutions, `SELECT r.banner_id, r.valide, r.comment, r.created_at
+		FROM resolution r WHERE r.banner_id>? AND r.created_at>0
+			AND r.created_at=(SELECT MAX(l.created_at) FROM resolution l WHERE l.banner_id=r.banner_id)
+		ORDER BY r.banner_id ASC LIMIT ?`, afterBannerID, limit)
+	if err != nil {
+		return nil, fmt.Errorf("could not select: %w", err)
+	}
+
+	return resolutions, nil
+}
@@ -0,0 +1,108 @@
+package mysql
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/crxfoz/teaserad/crmad/pkg/outbox"
+	"github.com/jmoiron/sqlx"
+	"go.opentelemetry.io/otel"
+)
+
+// maxOutboxError is the size of last_error column
+const maxOutboxError = 1024
+
+func (r *UserRepo) AddOutbox(ctx context.Context, msg *outbox.Message) error {
+	newCtx, span := otel.Tracer("db").Start(ctx, "AddOutbox")
+	defer span.End()
+
+	_, err := r.executor(newCtx).ExecContext(newCtx,
+		"INSERT INTO outbox (topic, msg_key, payload, headers, created_at) VALUES (?,?,?,?,?)",
+		msg.Topic,
+		msg.Key,
+		msg.Payload,
+		msg.Headers,
+		msg.CreatedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("could not insert outbox message: %w", err)
+	}
+
+	return nil
+}
+
+// GetOutbox locks pending messages so another replica waits until they are claimed
+func (r *UserRepo) GetOutbox(ctx context.Context, limit int) ([]*outbox.Message, error) {
+	newCtx, span := otel.Tracer("db").Start(ctx, "GetOutbox")
+	defer span.End()
+
+	var messages []*outbox.Message
+
+	err := r.executor(newCtx).SelectContext(newCtx, &messages,
+		`SELECT id, topic, msg_key, payload, headers, created_at, attempts, claimed_until
+		FROM outbox WHERE sent_at=0 ORDER BY id LIMIT ? FOR UPDATE`, limit)
+	if err != nil {
+		return nil, fmt.Errorf("could not get outbox: %w", err)
+	}
+
+	return messages, nil
+}
+
+func (r *UserRepo) ClaimOutbox(ctx context.Context, msgIDs []int, until int64) error {
+	newCtx, span := otel.Tracer("db").Start(ctx, "ClaimOutbox")
+	defer span.End()
+
+	return r.setOutboxClaim(newCtx, msgIDs, until)
+}
+
+func (r *UserRepo) ReleaseOutbox(ctx context.Context, msgIDs []int) error {
+	newCtx, span := otel.Tracer("db").Start(ctx, "ReleaseOutbox")
+	defer span.End()
+
+	return r.setOutboxClaim(newCtx, msgIDs, 0)
+}
+
+func (r *UserRepo) setOutboxClaim(ctx context.Context, msgIDs []int, until int64) error {
+	if len(msgIDs) == 0 {
+		return nil
+	}
+
+	query, args, err := sqlx.In("UPDATE outbox SET claimed_until=? WHERE id IN (?)", until, msgIDs)
+	if err != nil {
+		return fmt.Errorf("could not build query: %w", err)
+	}
+
+	if _, err := r.executor(ctx).ExecContext(ctx, query, args...); err != nil {
+		return fmt.Errorf("could not update outbox: %w", err)
+	}
+
+	return nil
+}
+
+func (r *UserRepo) OutboxSent(ctx context.Context, msgID int, sentAt int64) error {
+	newCtx, span := otel.Tracer("db").Start(ctx, "OutboxSent")
+	defer span.End()
+
+	if _, err := r.executor(newCtx).ExecContext(newCtx, "UPDATE outbox SET sent_at=? WHERE id=?", sentAt, msgID); err != nil {
+		return fmt.Errorf("could not update outbox: %w", err)
+	}
+
+	return nil
+}
+
+func (r *UserRepo) OutboxFailed(ctx context.Context, msgID int, reason string) error {
+	newCtx, span := otel.Tracer("db").Start(ctx, "OutboxFailed")
+	defer span.End()
+
+	if len(reason) > maxOutboxError {
+		reason = reason[:maxOutboxError]
+	}
+
+	_, err := r.executor(newCtx).ExecContext(newCtx,
+		"UPDATE outbox SET attempts=attempts+1, last_error=? WHERE id=?", reason, msgID)
+	if err != nil {
+		return fmt.Errorf("could not update outbox: %w", err)
+	}
+
+	return nil
+}
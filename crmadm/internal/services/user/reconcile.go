@@ -0,0 +1,79 @@
+package user
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/crxfoz/teaserad/crmadm/internal/domain/entity"
+	"github.com/crxfoz/teaserad/crmadm/internal/domain/events"
+	"go.opentelemetry.io/otel"
+)
+
+// reconcileBatch is how many banners are compared at once
+const reconcileBatch = 500
+
+// ModerationState is what crmad has applied from moderation
+type ModerationState interface {
+	// ModeratedAt returns the time of the last resolution applied to each of the banners found
+	ModeratedAt(ctx context.Context, bannerIDs []int) (map[int]int64, error)
+}
+
+func resolutionEvent(resolution *entity.Resolution) events.BannerUpdated {
+	return events.BannerUpdated{
+		BannerID:   resolution.BannerID,
+		Approved:   resolution.Valide,
+		Comment:    resolution.Comment,
+		ResolvedAt: resolution.CreatedAt,
+	}
+}
+
+// Reconcile sends again the last resolution of banners which crmad has not applied yet and reports how many are sent.
+// Time of the resolution is compared instead of the status since rejected revision keeps the banner validated
+func (u *User) Reconcile(ctx context.Context, state ModerationState) (int, error) {
+	newCtx, span := otel.Tracer("usecase").Start(ctx, "Reconcile")
+	defer span.End()
+
+	var (
+		sent   int
+		lastID int
+	)
+
+	for {
+		resolutions, err := u.repo.GetLatestResolutions(newCtx, lastID, reconcileBatch)
+		if err != nil {
+			return sent, fmt.Errorf("repo failed: %w", err)
+		}
+
+		if len(resolutions) == 0 {
+			return sent, nil
+		}
+
+		bannerIDs := make([]int, 0, len(resolutions))
+		for _, resolution := range resolutions {
+			bannerIDs = append(bannerIDs, resolution.BannerID)
+		}
+
+		moderatedAt, err := state.ModeratedAt(newCtx, bannerIDs)
+		if err != nil {
+			return sent, fmt.Errorf("could not get moderation state: %w", err)
+		}
+
+		for _, resolution := range resolutions {
+			lastID = resolution.BannerID
+
+			appliedAt, ok := moderatedAt[resolution.BannerID]
+			if !ok || appliedAt >= resolution.CreatedAt {
+				continue
+			}
+
+			// only one of resolutions made within the same second is sent
+			moderatedAt[resolution.BannerID] = resolution.CreatedAt
+
+			if err := u.bannerEventer.BannerUpdated(newCtx, resolutionEvent(resolution)); err != nil {
+				return sent, fmt.Errorf("could not send event: %w", err)
+			}
+
+			sent++
+		}
+	}
+}
@@ -16,9 +16,11 @@ type Transactor interface {
 
 type UserRepo interface {
 	NewBanner(ctx context.Context, banner *entity.Banner) error
+	ResubmitBanner(ctx context.Context, banner *entity.Banner) error
 	GetBanners(ctx context.Context, limit int, offset int) ([]*entity.BannerResulution, error)
 	GetNewBanners(ctx context.Context, limit int, offset int) ([]*entity.Banner, error)
 	AddResolution(ctx context.Context, resolution *entity.Resolution) error
+	GetLatestResolutions(ctx context.Context, afterBannerID int, limit int) ([]*entity.Resolution, error)
 	AddUser(ctx context.Context, user *entity.User) error
 	FindUser(ctx context.Context, username string) (*entity.User, error)
 }
@@ -29,7 +31,7 @@ type Auth interface {
 }
 
 type BannerEventer interface {
-	BannerUpdated(msg events.BannerUpdated) error
+	BannerUpdated(ctx context.Context, msg events.BannerUpdated) error
 }
 
 type User struct {
@@ -102,17 +104,16 @@ func (u *User) GetNewBanners(ctx context.Context, limit int, offset int) ([]*ent
 }
 
 func (u *User) AddBannerResolution(ctx context.Context, resolution *entity.Resolution) error {
+	// resolution made before the banner has been resubmitted doesn't count, so its time is required
+	resolution.CreatedAt = time.Now().UTC().Unix()
+
 	err := u.transactor.WithTransaction(ctx, func(txCtx context.Context) error {
 		if err := u.repo.AddResolution(txCtx, resolution); err != nil {
 			return fmt.Errorf("repo failed: %w", err)
 		}
 
-		err := u.bannerEventer.BannerUpdated(events.BannerUpdated{
-			BannerID: resolution.BannerID,
-			Valide:   resolution.Valide,
-			Comment:  resolution.Comment,
-		})
-		if err != nil {
+		// event is stored along with the resolution and relayed after commit
+		if err := u.bannerEventer.BannerUpdated(txCtx, resolutionEvent(resolution)); err != nil {
 			return fmt.Errorf("could not send event: %w", err)
 		}
 
@@ -160,3 +161,24 @@ func (u *User) NewBanner(ctx context.Context, banner events.BannerCreated) error
 
 	return nil
 }
+
+// BannerRevised puts the banner back to the queue of new ones, its next resolution is about the revision
+func (u *User) BannerRevised(ctx context.Context, banner events.BannerRevised) error {
+	newCtx, span := otel.Tracer("usecase").Start(ctx, "BannerRevised")
+	defer span.End()
+
+	item := &entity.Banner{
+		BannerID:  banner.BannerID,
+		UserID:    banner.UserID,
+		CreatedAt: time.Now().UTC().Unix(),
+	}
+
+	err := u.transactor.WithTransaction(newCtx, func(txCtx context.Context) error {
+		return u.repo.ResubmitBanner(txCtx, item)
+	})
+	if err != nil {
+		return fmt.Errorf("repo failed: %w", err)
+	}
+
+	return nil
+}
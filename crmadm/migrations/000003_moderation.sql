@@ -0,0 +1,34 @@
+-- tables of moderation crmadm has been using since before migrations were added,
+-- they are created only if the database doesn't have them yet
+CREATE TABLE IF NOT EXISTS `user`
+(
+    `id`         int(11) NOT NULL AUTO_INCREMENT,
+    `username`   varchar(255) NOT NULL,
+    `password`   varchar(255) NOT NULL,
+    `role`       varchar(32)  NOT NULL,
+    `created_at` int(11) NOT NULL,
+    PRIMARY KEY (`id`),
+    UNIQUE KEY `username` (`username`)
+) ENGINE=InnoDB;
+
+-- banner is a submission waiting for moderation, resubmitted banner gets the new created_at
+CREATE TABLE IF NOT EXISTS `banner`
+(
+    `banner_id`  int(11) NOT NULL,
+    `user_id`    int(11) NOT NULL,
+    `created_at` int(11) NOT NULL,
+    PRIMARY KEY (`banner_id`),
+    KEY `created_at` (`created_at`)
+) ENGINE=InnoDB;
+
+-- resolutions are kept as history, the latest one of the banner is in force
+CREATE TABLE IF NOT EXISTS `resolution`
+(
+    `id`         int(11) NOT NULL AUTO_INCREMENT,
+    `banner_id`  int(11) NOT NULL,
+    `valide`     tinyint(1) NOT NULL,
+    `comment`    varchar(1024) NOT NULL DEFAULT '',
+    `created_at` int(11) NOT NULL,
+    PRIMARY KEY (`id`),
+    KEY `banner_id` (`banner_id`, `created_at`)
+) ENGINE=InnoDB;
@@ -1,11 +1,12 @@
 package crmad
 
 import (
-	"encoding/json"
+	"context"
 	"fmt"
 
-	"github.com/Shopify/sarama"
+	"github.com/crxfoz/teaserad/adeliver/pkg/kafka"
 	"github.com/crxfoz/teaserad/crmadm/internal/domain/events"
+	"go.opentelemetry.io/otel"
 )
 
 const (
@@ -13,26 +14,23 @@ const (
 )
 
 type Banner struct {
-	conn sarama.SyncProducer
+	producer *kafka.Producer
 }
 
-func New(conn sarama.SyncProducer) *Banner {
-	return &Banner{conn: conn}
+func New(outbox kafka.Outbox) *Banner {
+	return &Banner{producer: kafka.NewOutboxProducer(outbox, "crmadm")}
 }
 
-func (b *Banner) BannerUpdated(msg events.BannerUpdated) error {
-	out, err := json.Marshal(msg)
-	if err != nil {
-		return fmt.Errorf("could not marshal msg: %w", err)
-	}
+const (
+	tracerName = "kafka-producer-crmad"
+)
+
+func (b *Banner) BannerUpdated(ctx context.Context, msg events.BannerUpdated) error {
+	newCtx, span := otel.Tracer(tracerName).Start(ctx, "BannerUpdated")
+	defer span.End()
 
-	_, _, err = b.conn.SendMessage(&sarama.ProducerMessage{
-		Topic: topicName,
-		Key:   sarama.StringEncoder("1"), // TODO: use different keys
-		Value: sarama.ByteEncoder(out),
-	})
-	if err != nil {
-		return fmt.Errorf("could not send message: %w", err)
+	if err := b.producer.Publish(newCtx, topicName, msg); err != nil {
+		return fmt.Errorf("could not publish event: %w", err)
 	}
 
 	return nil
@@ -0,0 +1,52 @@
+package crmad
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/jmoiron/sqlx"
+	"go.opentelemetry.io/otel"
+)
+
+// State reads what crmad has applied from moderation, it's used to find resolutions lost on the way.
+// It queries banners of crmad database directly, so it's only meant for the reconcile tool run by operators,
+// services get the state of crmad through its events
+type State struct {
+	db *sqlx.DB
+}
+
+func NewState(db *sqlx.DB) *State {
+	return &State{db: db}
+}
+
+// ModeratedAt returns the time of the last resolution applied to each of the banners,
+// banners which don't exist in crmad are missing in the result
+func (s *State) ModeratedAt(ctx context.Context, bannerIDs []int) (map[int]int64, error) {
+	spanCtx, span := otel.Tracer(tracerName).Start(ctx, "ModeratedAt")
+	defer span.End()
+
+	out := make(map[int]int64, len(bannerIDs))
+	if len(bannerIDs) == 0 {
+		return out, nil
+	}
+
+	query, args, err := sqlx.In("SELECT id, moderated_at FROM banners WHERE id IN (?)", bannerIDs)
+	if err != nil {
+		return nil, fmt.Errorf("could not build query: %w", err)
+	}
+
+	var rows []struct {
+		ID          int   `db:"id"`
+		ModeratedAt int64 `db:"moderated_at"`
+	}
+
+	if err := s.db.SelectContext(spanCtx, &rows, s.db.Rebind(query), args...); err != nil {
+		return nil, fmt.Errorf("could not select banners: %w", err)
+	}
+
+	for _, row := range rows {
+		out[row.ID] = row.ModeratedAt
+	}
+
+	return out, nil
+}